@@ -0,0 +1,171 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/juleur/becrpe/config"
+	"github.com/juleur/becrpe/graph/model"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Admin runs operator commands against the same database as the server
+type Admin struct {
+	DB     *sqlx.DB
+	Config *config.Config
+	Logger *logrus.Logger
+	Out    io.Writer
+}
+
+type command struct {
+	name  string
+	args  string
+	help  string
+	nArgs int
+	run   func(a *Admin, ctx context.Context, args []string) error
+	// sections are the ones validated besides the database, the server
+	// settings are not needed by the admin commands
+	sections []config.Section
+}
+
+var (
+	needsRedis      = []config.Section{config.SectionRedis}
+	needsRevocation = []config.Section{config.SectionRedis, config.SectionRevocation}
+	needsStorage    = []config.Section{config.SectionStorage}
+)
+
+var commands = []command{
+	{"user show", "<user>", "print a user", 1, (*Admin).userShow, nil},
+	{"user promote", "<user>", "make a user a teacher", 1, (*Admin).userPromote, nil},
+	{"user demote", "<user>", "remove the teacher role of a user", 1, (*Admin).userDemote, nil},
+	{"user grant", "<user> <role>", "give a role (student, teacher, admin, support) to a user", 2, (*Admin).userGrant, nil},
+	{"user revoke", "<user> <role>", "take a role back from a user", 2, (*Admin).userRevoke, nil},
+	{"user unlock", "<user>", "lift the login lockout after wrong passwords or two-factor codes", 1, (*Admin).userUnlock, needsRedis},
+	{"user export", "<user>", "write the ZIP of the personal data of a user to stdout, as exportMyData", 1, (*Admin).userExport, nil},
+	{"user delete", "<user>", "erase a user and anonymise their payments, as deleteAccount", 1, (*Admin).userDelete, needsRevocation},
+	{"enrollment list", "<user>", "list the refresher courses of a user", 1, (*Admin).enrollmentList, nil},
+	{"enrollment grant", "<user> <refresher-course-id>", "give a refresher course without payment, e.g. bank transfer", 2, (*Admin).enrollmentGrant, nil},
+	{"enrollment revoke", "<user> <refresher-course-id>", "take a refresher course back", 2, (*Admin).enrollmentRevoke, nil},
+	{"token revoke", "<user>", "revoke every refresh token of a user", 1, (*Admin).tokenRevoke, needsRevocation},
+	{"session pending", "", "list sessions not ready yet with their upload jobs", 0, (*Admin).sessionPending, nil},
+	{"session requeue", "<session-id>", "run again the saved upload jobs of a session and wait for them", 1, (*Admin).sessionRequeue, needsStorage},
+	{"migrate up", "", "apply pending database migrations", 0, (*Admin).migrateUp, nil},
+	{"migrate down", "<steps>", "revert the last applied migrations", 1, (*Admin).migrateDown, nil},
+	{"migrate status", "", "list migrations and when they were applied", 0, (*Admin).migrateStatus, nil},
+}
+
+// Run executes `becrpe admin [flags] <command> [args]` and returns the exit code,
+// flags are the server ones so both share the config file and environment
+func Run(args []string, stdout, stderr io.Writer) int {
+	cfg, rest, err := config.ParseArgs("becrpe admin", args)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	if len(rest) < 2 {
+		usage(stderr)
+		return 2
+	}
+	name := rest[0] + " " + rest[1]
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil || len(rest[2:]) != cmd.nArgs {
+		usage(stderr)
+		return 2
+	}
+	if err := cfg.ValidateSections(append([]config.Section{config.SectionDatabase}, cmd.sections...)...); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(stderr)
+	db, err := sqlx.Connect("mysql", cfg.Database.DSN)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	defer db.Close()
+
+	// a second signal kills the process, the first one lets upload jobs be saved
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+	go func() {
+		select {
+		case <-quit:
+			signal.Stop(quit)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	a := &Admin{DB: db, Config: cfg, Logger: logger, Out: stdout}
+	if err := cmd.run(a, ctx, rest[2:]); err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: becrpe admin [-config file] [server flags] <command> [args]")
+	fmt.Fprintln(w, "<user> is a user id, username or email")
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "  %s %s\t%s\n", cmd.name, cmd.args, cmd.help)
+	}
+	tw.Flush()
+}
+
+func (a *Admin) table() *tabwriter.Writer {
+	return tabwriter.NewWriter(a.Out, 0, 0, 2, ' ', 0)
+}
+
+// findUser looks the user up by id, email or username
+func (a *Admin) findUser(ctx context.Context, ref string) (*model.User, error) {
+	query := "SELECT id, username, fullname, email, created_at, updated_at FROM users "
+	switch {
+	case isID(ref):
+		query += "WHERE id = ?"
+	case strings.Contains(ref, "@"):
+		query += "WHERE email = ?"
+	default:
+		query += "WHERE username = ?"
+	}
+	user := model.User{}
+	if err := a.DB.GetContext(ctx, &user, query, ref); err != nil {
+		return nil, errors.Wrapf(err, "user %s", ref)
+	}
+	return &user, nil
+}
+
+func isID(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+func parseID(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id <= 0 {
+		return 0, errors.Errorf("%q is not a valid id", s)
+	}
+	return id, nil
+}
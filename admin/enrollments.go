@@ -0,0 +1,106 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+type enrollment struct {
+	RefresherCourseID int           `db:"refresher_course_id"`
+	Subject           string        `db:"subject"`
+	Year              string        `db:"year"`
+	PaymentID         sql.NullInt64 `db:"payment_id"`
+}
+
+func (a *Admin) enrollmentList(ctx context.Context, args []string) error {
+	user, err := a.findUser(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	enrollments := []enrollment{}
+	if err := a.DB.SelectContext(ctx, &enrollments, `
+		SELECT urc.refresher_course_id, rc.subject, rc.year, urc.payment_id FROM users_refresher_courses AS urc
+		JOIN refresher_courses AS rc ON rc.id = urc.refresher_course_id
+		WHERE urc.user_id = ? ORDER BY rc.year, rc.subject
+	`, user.ID); err != nil {
+		return errors.WithStack(err)
+	}
+	tw := a.table()
+	fmt.Fprintln(tw, "COURSE\tSUBJECT\tYEAR\tPAYMENT")
+	for _, e := range enrollments {
+		payment := "granted"
+		if e.PaymentID.Valid {
+			payment = fmt.Sprint(e.PaymentID.Int64)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", e.RefresherCourseID, e.Subject, e.Year, payment)
+	}
+	return tw.Flush()
+}
+
+func (a *Admin) enrollmentGrant(ctx context.Context, args []string) error {
+	user, err := a.findUser(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	refresherCourseID, err := parseID(args[1])
+	if err != nil {
+		return err
+	}
+	var subject string
+	if err := a.DB.GetContext(ctx, &subject, "SELECT subject FROM refresher_courses WHERE id = ?", refresherCourseID); err != nil {
+		return errors.Wrapf(err, "refresher course %d", refresherCourseID)
+	}
+	enrolled, err := a.isEnrolled(ctx, user.ID, refresherCourseID)
+	if err != nil {
+		return err
+	}
+	if enrolled {
+		fmt.Fprintf(a.Out, "%s is already enrolled in refresher course %d\n", user.Username, refresherCourseID)
+		return nil
+	}
+	// payment_id stays NULL, granted courses are not tied to a PayPal order
+	if _, err := a.DB.ExecContext(ctx,
+		"INSERT INTO users_refresher_courses (payment_id, user_id, refresher_course_id) VALUES (?,?,?)",
+		nil, user.ID, refresherCourseID,
+	); err != nil {
+		return errors.WithStack(err)
+	}
+	fmt.Fprintf(a.Out, "%s enrolled in refresher course %d (%s)\n", user.Username, refresherCourseID, subject)
+	return nil
+}
+
+func (a *Admin) enrollmentRevoke(ctx context.Context, args []string) error {
+	user, err := a.findUser(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	refresherCourseID, err := parseID(args[1])
+	if err != nil {
+		return err
+	}
+	res, err := a.DB.ExecContext(ctx,
+		"DELETE FROM users_refresher_courses WHERE user_id = ? AND refresher_course_id = ?",
+		user.ID, refresherCourseID,
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return errors.Errorf("%s is not enrolled in refresher course %d", user.Username, refresherCourseID)
+	}
+	fmt.Fprintf(a.Out, "%s unenrolled from refresher course %d\n", user.Username, refresherCourseID)
+	return nil
+}
+
+func (a *Admin) isEnrolled(ctx context.Context, userID, refresherCourseID int) (bool, error) {
+	var count int
+	if err := a.DB.GetContext(ctx, &count, `
+		SELECT COUNT(*) FROM users_refresher_courses WHERE user_id = ? AND refresher_course_id = ?
+	`, userID, refresherCourseID); err != nil {
+		return false, errors.WithStack(err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,66 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/juleur/becrpe/migrations"
+)
+
+func (a *Admin) migrateUp(ctx context.Context, args []string) error {
+	migrator, err := migrations.New(a.Config.Database.DSN, a.Logger)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.Out, "%d migration(s) applied\n", applied)
+	return nil
+}
+
+func (a *Admin) migrateDown(ctx context.Context, args []string) error {
+	steps, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+	migrator, err := migrations.New(a.Config.Database.DSN, a.Logger)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+	reverted, err := migrator.Down(ctx, steps)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.Out, "%d migration(s) reverted\n", reverted)
+	return nil
+}
+
+func (a *Admin) migrateStatus(ctx context.Context, args []string) error {
+	migrator, err := migrations.New(a.Config.Database.DSN, a.Logger)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+	tw := a.table()
+	fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED")
+	for _, s := range statuses {
+		applied := "pending"
+		switch {
+		case s.Dirty:
+			applied = "dirty since " + s.AppliedAt.Format(time.RFC3339)
+		case !s.AppliedAt.IsZero():
+			applied = s.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%04d\t%s\t%s\n", s.Version, s.Name, applied)
+	}
+	return tw.Flush()
+}
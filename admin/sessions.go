@@ -0,0 +1,94 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/juleur/becrpe/graph/model"
+	"github.com/pkg/errors"
+)
+
+type pendingSession struct {
+	ID                int            `db:"id"`
+	Title             string         `db:"title"`
+	RefresherCourseID int            `db:"refresher_course_id"`
+	Teacher           string         `db:"username"`
+	CreatedAt         time.Time      `db:"created_at"`
+	JobKind           sql.NullString `db:"kind"`
+	JobFailedAt       sql.NullTime   `db:"failed_at"`
+	JobError          sql.NullString `db:"error"`
+}
+
+func (a *Admin) sessionPending(ctx context.Context, args []string) error {
+	sessions := []pendingSession{}
+	if err := a.DB.SelectContext(ctx, &sessions, `
+		SELECT s.id, s.title, s.refresher_course_id, u.username, s.created_at, j.kind, j.failed_at, j.error
+		FROM sessions AS s
+		JOIN users AS u ON u.id = s.user_id
+		LEFT JOIN upload_jobs AS j ON j.session_id = s.id
+		WHERE s.is_ready = 0 ORDER BY s.id, j.id
+	`); err != nil {
+		return errors.WithStack(err)
+	}
+	tw := a.table()
+	fmt.Fprintln(tw, "SESSION\tCOURSE\tTEACHER\tCREATED\tTITLE\tJOB")
+	for _, s := range sessions {
+		fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%s\t%s\n",
+			s.ID, s.RefresherCourseID, s.Teacher, s.CreatedAt.Format("2006-01-02 15:04"), s.Title, jobState(s))
+	}
+	return tw.Flush()
+}
+
+func jobState(s pendingSession) string {
+	switch {
+	case !s.JobKind.Valid:
+		// nothing was queued, its files must be uploaded again
+		return "none"
+	case s.JobFailedAt.Valid:
+		return fmt.Sprintf("%s failed at %s: %s", s.JobKind.String, s.JobFailedAt.Time.Format("2006-01-02 15:04"), s.JobError.String)
+	default:
+		// running on the server, or interrupted and resumed when it starts
+		return s.JobKind.String + " queued"
+	}
+}
+
+// sessionRequeue runs the failed jobs in this process, the server only
+// resumes the queued ones when it starts
+func (a *Admin) sessionRequeue(ctx context.Context, args []string) error {
+	sessionID, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+	ufm, err := model.NewUploadFileManager(a.DB, a.Logger, a.Config.Storage)
+	if err != nil {
+		return err
+	}
+	started, err := ufm.RequeueSession(sessionID)
+	if err != nil {
+		ufm.Shutdown(ctx)
+		return err
+	}
+	if started == 0 {
+		ufm.Shutdown(ctx)
+		return errors.Errorf("session n°%d has no failed upload job, see `session pending`", sessionID)
+	}
+	fmt.Fprintf(a.Out, "%d job(s) of session n°%d started, waiting for them\n", started, sessionID)
+	// ctx is cancelled by SIGINT, unfinished jobs are then resumed when the server next starts
+	if err := ufm.Shutdown(ctx); err != nil {
+		return err
+	}
+
+	var failed int
+	if err := a.DB.GetContext(context.Background(), &failed,
+		"SELECT COUNT(*) FROM upload_jobs WHERE session_id = ?", sessionID,
+	); err != nil {
+		return errors.WithStack(err)
+	}
+	if failed > 0 {
+		return errors.Errorf("%d job(s) of session n°%d did not finish, see `session pending`", failed, sessionID)
+	}
+	fmt.Fprintf(a.Out, "session n°%d processed\n", sessionID)
+	return nil
+}
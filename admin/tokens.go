@@ -0,0 +1,38 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/juleur/becrpe/cache"
+	"github.com/juleur/becrpe/repository"
+)
+
+func (a *Admin) tokenRevoke(ctx context.Context, args []string) error {
+	user, err := a.findUser(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	families, err := repository.NewMySQL(a.DB).AuthTokens.RevokeAll(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	redisCache, err := cache.NewCache(a.Config.Redis.Address, a.Config.Redis.Password, a.Config.Redis.TTL)
+	if err != nil {
+		return err
+	}
+	defer redisCache.Close()
+	// the JWTs already issued to those devices are rejected too
+	for _, familyID := range families {
+		if err := redisCache.RevokeSession(ctx, strconv.Itoa(familyID), a.Config.JWT.Expiration); err != nil {
+			return err
+		}
+	}
+	// frees the player lock on the last IP, the next check pins the new one
+	redisCache.DeleteIP(ctx, strconv.Itoa(user.ID))
+
+	fmt.Fprintf(a.Out, "%d device session(s) of %s revoked\n", len(families), user.Username)
+	return nil
+}
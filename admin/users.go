@@ -0,0 +1,171 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juleur/becrpe/cache"
+	"github.com/juleur/becrpe/graph/model"
+	"github.com/juleur/becrpe/repository"
+	"github.com/pkg/errors"
+)
+
+func (a *Admin) userShow(ctx context.Context, args []string) error {
+	user, err := a.findUser(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	names, _, err := repository.NewMySQL(a.DB).Roles.ByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	tw := a.table()
+	fmt.Fprintf(tw, "id\t%d\n", user.ID)
+	fmt.Fprintf(tw, "username\t%s\n", user.Username)
+	fmt.Fprintf(tw, "fullname\t%s\n", user.Fullname.String)
+	fmt.Fprintf(tw, "email\t%s\n", user.Email)
+	fmt.Fprintf(tw, "roles\t%s\n", strings.Join(names, ", "))
+	fmt.Fprintf(tw, "created at\t%s\n", user.CreatedAt.Format(time.RFC3339))
+	if user.UpdatedAt.Valid {
+		fmt.Fprintf(tw, "updated at\t%s\n", user.UpdatedAt.Time.Format(time.RFC3339))
+	}
+	return tw.Flush()
+}
+
+func (a *Admin) userPromote(ctx context.Context, args []string) error {
+	return a.setRole(ctx, args[0], model.RoleTeacher, true)
+}
+
+func (a *Admin) userDemote(ctx context.Context, args []string) error {
+	return a.setRole(ctx, args[0], model.RoleTeacher, false)
+}
+
+func (a *Admin) userGrant(ctx context.Context, args []string) error {
+	role, err := parseRole(args[1])
+	if err != nil {
+		return err
+	}
+	return a.setRole(ctx, args[0], role, true)
+}
+
+func (a *Admin) userRevoke(ctx context.Context, args []string) error {
+	role, err := parseRole(args[1])
+	if err != nil {
+		return err
+	}
+	return a.setRole(ctx, args[0], role, false)
+}
+
+func (a *Admin) userUnlock(ctx context.Context, args []string) error {
+	user, err := a.findUser(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	redisCache, err := cache.NewCache(a.Config.Redis.Address, a.Config.Redis.Password, a.Config.Redis.TTL)
+	if err != nil {
+		return err
+	}
+	defer redisCache.Close()
+	for _, key := range []string{cache.LoginKey(user.Email), cache.TwoFactorKey(user.ID)} {
+		if err := redisCache.ClearFailures(ctx, key); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(a.Out, "%s can log in again\n", user.Username)
+	return nil
+}
+
+func (a *Admin) userExport(ctx context.Context, args []string) error {
+	user, err := a.findUser(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	data, err := repository.NewMySQL(a.DB).PersonalData.Export(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	archive, err := data.Archive()
+	if err != nil {
+		return err
+	}
+	_, err = a.Out.Write(archive)
+	return errors.WithStack(err)
+}
+
+func (a *Admin) userDelete(ctx context.Context, args []string) error {
+	user, err := a.findUser(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	repos := repository.NewMySQL(a.DB)
+	auths, err := repos.AuthTokens.Active(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if err := repos.PersonalData.Erase(ctx, user.ID); err == repository.ErrAuthoredSessions {
+		return errors.Errorf("%s recorded sessions, reassign them (sessions.user_id) first", user.Username)
+	} else if err != nil {
+		return err
+	}
+
+	redisCache, err := cache.NewCache(a.Config.Redis.Address, a.Config.Redis.Password, a.Config.Redis.TTL)
+	if err != nil {
+		return err
+	}
+	defer redisCache.Close()
+	// the JWTs already issued to the deleted user are rejected too
+	for _, auth := range auths {
+		if err := redisCache.RevokeSession(ctx, strconv.Itoa(auth.FamilyID), a.Config.JWT.Expiration); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(a.Out, "%s deleted, their payments are anonymised\n", user.Username)
+	return nil
+}
+
+func (a *Admin) setRole(ctx context.Context, ref string, role model.Role, granted bool) error {
+	user, err := a.findUser(ctx, ref)
+	if err != nil {
+		return err
+	}
+	roles := repository.NewMySQL(a.DB).Roles
+	names, _, err := roles.ByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if hasRole(names, role.Name()) == granted {
+		fmt.Fprintf(a.Out, "%s already has %s=%t\n", user.Username, role.Name(), granted)
+		return nil
+	}
+	if granted {
+		err = roles.Grant(ctx, user.ID, role.Name())
+	} else {
+		err = roles.Revoke(ctx, user.ID, role.Name())
+	}
+	if err != nil {
+		return err
+	}
+	// the roles are read from the JWT, they apply on the next token refresh
+	fmt.Fprintf(a.Out, "%s now has %s=%t, effective at the next token refresh\n", user.Username, role.Name(), granted)
+	return nil
+}
+
+func parseRole(name string) (model.Role, error) {
+	role := model.RoleFromName(name)
+	if !role.IsValid() {
+		return "", errors.Errorf("%q is not a role, expected student, teacher, admin or support", name)
+	}
+	return role, nil
+}
+
+func hasRole(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
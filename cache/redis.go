@@ -1,6 +1,10 @@
 package cache
 
 import (
+	"context"
+	"math/rand"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v7"
@@ -9,11 +13,19 @@ import (
 
 // Cache struct
 type Cache struct {
-	client redis.UniversalClient
+	client *redis.Client
 	ttl    time.Duration
 }
 
-const userPrefix = "user:"
+const (
+	userPrefix           = "user:"
+	revokedSessionPrefix = "revoked_session:"
+	deniedJWTPrefix      = "denied_jwt:"
+	rateLimitPrefix      = "rate_limit:"
+	failuresPrefix       = "failures:"
+	lockPrefix           = "lock:"
+	oncePrefix           = "once:"
+)
 
 // NewCache func
 func NewCache(redisAddress string, password string, ttl time.Duration) (*Cache, error) {
@@ -28,15 +40,30 @@ func NewCache(redisAddress string, password string, ttl time.Duration) (*Cache,
 	return &Cache{client: client, ttl: ttl}, nil
 }
 
+// AddHook instruments every command sent by the client
+func (c *Cache) AddHook(hook redis.Hook) {
+	c.client.AddHook(hook)
+}
+
+// Ping checks redis is reachable
+func (c *Cache) Ping(ctx context.Context) error {
+	return errors.WithStack(c.client.WithContext(ctx).Ping().Err())
+}
+
+// Close closes the redis client
+func (c *Cache) Close() error {
+	return c.client.Close()
+}
+
 //** USER IP **//
 // AddIP func
-func (c *Cache) AddIP(userID string, userIP string, duration time.Duration) {
-	_ = c.client.Set(userPrefix+userID, userIP, duration)
+func (c *Cache) AddIP(ctx context.Context, userID string, userIP string, duration time.Duration) {
+	_ = c.client.WithContext(ctx).Set(userPrefix+userID, userIP, duration)
 }
 
 // GetIP returns addresses IP
-func (c *Cache) GetIP(userID string) (string, bool) {
-	s, err := c.client.Get(userPrefix + userID).Result()
+func (c *Cache) GetIP(ctx context.Context, userID string) (string, bool) {
+	s, err := c.client.WithContext(ctx).Get(userPrefix + userID).Result()
 	if err == redis.Nil || s != "" {
 		return s, true
 	}
@@ -44,6 +71,137 @@ func (c *Cache) GetIP(userID string) (string, bool) {
 }
 
 // DeleteIP func
-func (c *Cache) DeleteIP(userID string) {
-	_ = c.client.Del(userPrefix + userID)
+func (c *Cache) DeleteIP(ctx context.Context, userID string) {
+	_ = c.client.WithContext(ctx).Del(userPrefix + userID)
+}
+
+//** REVOKED SESSIONS **//
+// RevokeSession rejects the JWTs of a refresh token family, duration is
+// the JWT lifetime, the family cannot issue new ones once revoked in user_auths
+func (c *Cache) RevokeSession(ctx context.Context, sessionID string, duration time.Duration) error {
+	return errors.WithStack(c.client.WithContext(ctx).Set(revokedSessionPrefix+sessionID, 1, duration).Err())
+}
+
+// IsSessionRevoked answers false when redis fails, the JWT expires shortly anyway
+func (c *Cache) IsSessionRevoked(ctx context.Context, sessionID string) bool {
+	n, err := c.client.WithContext(ctx).Exists(revokedSessionPrefix + sessionID).Result()
+	return err == nil && n > 0
+}
+
+//** JWT DENYLIST **//
+// DenyJWT rejects the JWT named jti, duration is the time left before it expires
+func (c *Cache) DenyJWT(ctx context.Context, jti string, duration time.Duration) error {
+	// redis would keep the key forever, the JWT is expired anyway
+	if duration <= 0 {
+		return nil
+	}
+	return errors.WithStack(c.client.WithContext(ctx).Set(deniedJWTPrefix+jti, 1, duration).Err())
+}
+
+// IsJWTDenied answers false when redis fails, like IsSessionRevoked
+func (c *Cache) IsJWTDenied(ctx context.Context, jti string) bool {
+	n, err := c.client.WithContext(ctx).Exists(deniedJWTPrefix + jti).Result()
+	return err == nil && n > 0
+}
+
+//** RATE LIMITS **//
+// Hit records a call under key and answers whether at most limit calls were
+// made during the last window, otherwise retryAfter tells when the next call
+// fits. Refused calls count too, hammering keeps the key limited.
+func (c *Cache) Hit(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	client := c.client.WithContext(ctx)
+	key = rateLimitPrefix + key
+	now := time.Now()
+	// scores are in milliseconds, members only need to be unique
+	member := strconv.FormatInt(now.UnixNano(), 36) + "-" + strconv.FormatInt(rand.Int63(), 36)
+	var card *redis.IntCmd
+	if _, err := client.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.ZRemRangeByScore(key, "-inf", "("+strconv.FormatInt(millis(now.Add(-window)), 10))
+		pipe.ZAdd(key, &redis.Z{Score: float64(millis(now)), Member: member})
+		card = pipe.ZCard(key)
+		pipe.PExpire(key, window)
+		return nil
+	}); err != nil {
+		return false, 0, errors.WithStack(err)
+	}
+	n := card.Val()
+	if n <= int64(limit) {
+		return true, 0, nil
+	}
+	// the next call fits once the calls older than the last limit ones left the window
+	oldest, err := client.ZRangeWithScores(key, n-int64(limit), n-int64(limit)).Result()
+	if err != nil {
+		return false, 0, errors.WithStack(err)
+	}
+	if len(oldest) == 0 {
+		return false, window, nil
+	}
+	return false, time.Duration(int64(oldest[0].Score)-millis(now))*time.Millisecond + window, nil
+}
+
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+//** LOCKOUT **//
+// LoginKey is the lockout key of the logins with email
+func LoginKey(email string) string {
+	return "login:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// LockedFor answers how long key stays locked by AddFailure, 0 if it is not
+func (c *Cache) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	d, err := c.client.WithContext(ctx).PTTL(lockPrefix + key).Result()
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	// -2 when missing, -1 without expiry, which AddFailure never sets
+	if d < 0 {
+		return 0, nil
+	}
+	return d, nil
+}
+
+// AddFailure counts a failure of key, from threshold failures in a row key is
+// locked for base, doubled on each further failure up to max. The count is
+// forgotten max after the last failure. It returns the lock set, 0 if none.
+func (c *Cache) AddFailure(ctx context.Context, key string, threshold int, base, max time.Duration) (time.Duration, error) {
+	client := c.client.WithContext(ctx)
+	var incr *redis.IntCmd
+	if _, err := client.TxPipelined(func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(failuresPrefix + key)
+		pipe.PExpire(failuresPrefix+key, max)
+		return nil
+	}); err != nil {
+		return 0, errors.WithStack(err)
+	}
+	n := int(incr.Val())
+	if n < threshold {
+		return 0, nil
+	}
+	lock := base
+	for i := threshold; i < n && lock < max; i++ {
+		lock *= 2
+	}
+	if lock > max {
+		lock = max
+	}
+	return lock, errors.WithStack(client.Set(lockPrefix+key, 1, lock).Err())
+}
+
+// TwoFactorKey is the lockout key of the two-factor codes of a user
+func TwoFactorKey(userID int) string {
+	return "two_factor:" + strconv.Itoa(userID)
+}
+
+// ClearFailures forgets the failures and the lock of key
+func (c *Cache) ClearFailures(ctx context.Context, key string) error {
+	return errors.WithStack(c.client.WithContext(ctx).Del(failuresPrefix+key, lockPrefix+key).Err())
+}
+
+//** ONE-TIME KEYS **//
+// Once answers true the first time key is seen during duration
+func (c *Cache) Once(ctx context.Context, key string, duration time.Duration) (bool, error) {
+	first, err := c.client.WithContext(ctx).SetNX(oncePrefix+key, 1, duration).Result()
+	return first, errors.WithStack(err)
 }
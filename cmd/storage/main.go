@@ -0,0 +1,32 @@
+// Command storage serves the storage_video and storage_doc endpoints on the
+// local filesystem so the whole upload flow runs without the hosted storage,
+// see package storage for the contract.
+package main
+
+import (
+	"flag"
+	"net/http"
+	"os"
+
+	"github.com/juleur/becrpe/storage"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen address, config storage urls point to http://localhost:8080 by default")
+	root := flag.String("root", "./storage-data", "directory receiving the files")
+	publicPrefix := flag.String("public-prefix", storage.DefaultPublicPrefix, "prefix of the returned paths, stripped by the API")
+	flag.Parse()
+
+	logger := logrus.New()
+	logger.SetOutput(os.Stdout)
+
+	server, err := storage.NewServer(*root, *publicPrefix, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+	logger.Infof("storing under %s, listening on %s", *root, *addr)
+	if err := http.ListenAndServe(*addr, server.Handler()); err != nil {
+		logger.Fatalln(err)
+	}
+}
@@ -0,0 +1,744 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// envPrefix is prepended to every environment variable read by Load
+const envPrefix = "BECRPE_"
+
+// Config holds every setting needed by the server, it is built by Load
+// from defaults, then the config file, then environment variables, then flags
+type Config struct {
+	Server    Server    `yaml:"server"`
+	Database  Database  `yaml:"database"`
+	Redis     Redis     `yaml:"redis"`
+	JWT       JWT       `yaml:"jwt"`
+	Storage   Storage   `yaml:"storage"`
+	Log       Log       `yaml:"log"`
+	Tracing   Tracing   `yaml:"tracing"`
+	Mail      Mail      `yaml:"mail"`
+	Account   Account   `yaml:"account"`
+	RateLimit RateLimit `yaml:"rate_limit"`
+	TwoFactor TwoFactor `yaml:"two_factor"`
+	Password  Password  `yaml:"password"`
+	Session   Session   `yaml:"session"`
+}
+
+// Server struct
+type Server struct {
+	Port            string        `yaml:"port"`
+	MaxUploadSize   int64         `yaml:"max_upload_size"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// AllowedOrigins are the CORS origins, "*" is refused in cookie mode
+	AllowedOrigins []string `yaml:"allowed_origins"`
+	// MetricsAddress is the internal listener of /metrics, empty disables it
+	MetricsAddress string `yaml:"metrics_address"`
+}
+
+// Database struct
+type Database struct {
+	DSN             string        `yaml:"dsn"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	// MigrateOnStart applies pending migrations before serving
+	MigrateOnStart bool `yaml:"migrate_on_start"`
+}
+
+// Redis struct
+type Redis struct {
+	Address  string        `yaml:"address"`
+	Password string        `yaml:"password"`
+	TTL      time.Duration `yaml:"ttl"`
+}
+
+// JWT struct
+type JWT struct {
+	// SecretKey signs HS512 tokens without kid, it is only needed
+	// when SigningKeyID is empty or to accept tokens signed before a rotation
+	SecretKey  string        `yaml:"secret_key"`
+	Issuer     string        `yaml:"issuer"`
+	Expiration time.Duration `yaml:"expiration"`
+	// RefreshTokenKey keys the hash of the refresh tokens stored in user_auths,
+	// changing it invalidates every refresh token
+	RefreshTokenKey string `yaml:"refresh_token_key"`
+	// SigningKeyID picks in Keys the key signing new tokens
+	SigningKeyID string   `yaml:"signing_key_id"`
+	Keys         []JWTKey `yaml:"keys"`
+}
+
+// JWTKey is an asymmetric key verifying tokens with its kid,
+// keys without private key only verify, the others can also sign
+type JWTKey struct {
+	ID string `yaml:"id"`
+	// Algorithm is RS256 or EdDSA
+	Algorithm      string `yaml:"algorithm"`
+	PrivateKeyFile string `yaml:"private_key_file"`
+	PublicKeyFile  string `yaml:"public_key_file"`
+}
+
+// Storage struct
+type Storage struct {
+	VideoURL string `yaml:"video_url"`
+	DocURL   string `yaml:"doc_url"`
+	// SpoolDir keeps uploaded files until they are sent to storage
+	SpoolDir string `yaml:"spool_dir"`
+	// MaxRunningJobs caps the jobs packaged or sent at once, the others wait,
+	// /readyz fails once MaxQueuedJobs are waiting
+	MaxRunningJobs int `yaml:"max_running_jobs"`
+	MaxQueuedJobs  int `yaml:"max_queued_jobs"`
+}
+
+// Log struct
+type Log struct {
+	Filename string `yaml:"filename"`
+	MaxAge   int    `yaml:"max_age"`
+	MaxSize  int    `yaml:"max_size"`
+}
+
+// Tracing struct
+type Tracing struct {
+	// Exporter is none, stdout or otlp
+	Exporter    string  `yaml:"exporter"`
+	Endpoint    string  `yaml:"endpoint"`
+	Insecure    bool    `yaml:"insecure"`
+	ServiceName string  `yaml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// Mail struct
+type Mail struct {
+	// Driver is smtp or log
+	Driver   string `yaml:"driver"`
+	Address  string `yaml:"address"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
+// Account struct
+type Account struct {
+	// URL is the frontend the emailed links point to
+	URL              string        `yaml:"url"`
+	PasswordResetTTL time.Duration `yaml:"password_reset_ttl"`
+	// VerificationKey signs the email verification links
+	VerificationKey      string        `yaml:"verification_key"`
+	EmailVerificationTTL time.Duration `yaml:"email_verification_ttl"`
+	// RequireVerifiedEmail blocks purchases until the email is verified
+	RequireVerifiedEmail bool `yaml:"require_verified_email"`
+}
+
+// RateLimit struct
+type RateLimit struct {
+	Login             Limit   `yaml:"login"`
+	CreateUser        Limit   `yaml:"create_user"`
+	PasswordReset     Limit   `yaml:"password_reset"`
+	VerificationEmail Limit   `yaml:"verification_email"`
+	Lockout           Lockout `yaml:"lockout"`
+}
+
+// Limit caps the calls of an operation per client IP and per email
+// in a sliding window, a cap of 0 is disabled
+type Limit struct {
+	PerIP    int           `yaml:"per_ip"`
+	PerEmail int           `yaml:"per_email"`
+	Window   time.Duration `yaml:"window"`
+}
+
+// Lockout locks the login of an email after Threshold wrong passwords in a
+// row for BaseDuration, doubled on each further failure up to MaxDuration,
+// a Threshold of 0 disables it
+type Lockout struct {
+	Threshold    int           `yaml:"threshold"`
+	BaseDuration time.Duration `yaml:"base_duration"`
+	MaxDuration  time.Duration `yaml:"max_duration"`
+}
+
+// TwoFactor struct
+type TwoFactor struct {
+	// Issuer names the accounts in the authenticator apps
+	Issuer string `yaml:"issuer"`
+	// EncryptionKey encrypts the TOTP secrets stored in users
+	EncryptionKey string `yaml:"encryption_key"`
+	// ChallengeKey signs the login challenges, apart from the verification
+	// links so that neither can stand for the other
+	ChallengeKey string `yaml:"challenge_key"`
+	// ChallengeTTL is how long a login waits for its code
+	ChallengeTTL time.Duration `yaml:"challenge_ttl"`
+	// RequiredFor lists the roles that must enable it before using the
+	// fields restricted by @hasRole or @hasPermission
+	RequiredFor []string `yaml:"required_for"`
+}
+
+// Requires answers whether role must enable two-factor authentication
+func (t TwoFactor) Requires(role string) bool {
+	for _, r := range t.RequiredFor {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Password algorithms
+const (
+	PasswordArgon2id = "argon2id"
+	PasswordBcrypt   = "bcrypt"
+)
+
+// Password picks how new password hashes are made, a login with a hash of
+// another algorithm or weaker parameters replaces it
+type Password struct {
+	// Algorithm is argon2id or bcrypt
+	Algorithm  string `yaml:"algorithm"`
+	Argon2     Argon2 `yaml:"argon2"`
+	BcryptCost int    `yaml:"bcrypt_cost"`
+}
+
+// Argon2 struct
+type Argon2 struct {
+	// Memory is in KiB
+	Memory      uint32 `yaml:"memory"`
+	Iterations  uint32 `yaml:"iterations"`
+	Parallelism uint8  `yaml:"parallelism"`
+	SaltLength  uint32 `yaml:"salt_length"`
+	KeyLength   uint32 `yaml:"key_length"`
+}
+
+// Session modes
+const (
+	SessionBearer = "bearer"
+	SessionCookie = "cookie"
+)
+
+// Session picks where the clients keep their tokens. In bearer mode the
+// responses carry them and the JWT comes in the Authorization header, in
+// cookie mode they stay in HttpOnly cookies and every mutation of a request
+// with those cookies needs the CSRF token, the header is still accepted
+type Session struct {
+	Mode string `yaml:"mode"`
+	// CookieDomain is empty for the host of the API only
+	CookieDomain string `yaml:"cookie_domain"`
+	// CookieInsecure drops the Secure attribute, for development over http
+	CookieInsecure bool `yaml:"cookie_insecure"`
+	// SameSite is strict, lax or none
+	SameSite     string        `yaml:"same_site"`
+	CookieMaxAge time.Duration `yaml:"cookie_max_age"`
+}
+
+// Default returns the settings used when nothing overrides them,
+// secrets (jwt secret key, refresh token key, verification key, two-factor key, database dsn) are intentionally left empty
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Port:            "6677",
+			MaxUploadSize:   300000000,
+			ShutdownTimeout: 2 * time.Minute,
+			AllowedOrigins:  []string{"https://rf.ecrpe.fr"},
+			MetricsAddress:  "127.0.0.1:9090",
+		},
+		Database: Database{
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+		},
+		Redis: Redis{
+			Address: "localhost:8989",
+			TTL:     24 * time.Hour,
+		},
+		JWT: JWT{
+			Issuer:     "https://rf.ecrpe.fr",
+			Expiration: 1 * time.Minute,
+		},
+		Storage: Storage{
+			VideoURL:       "http://localhost:8080/storage_video",
+			DocURL:         "http://localhost:8080/storage_doc",
+			SpoolDir:       "./spool",
+			MaxRunningJobs: 2,
+			MaxQueuedJobs:  50,
+		},
+		Log: Log{
+			Filename: "./log/becrpe.log",
+			MaxAge:   2,
+			MaxSize:  30,
+		},
+		Tracing: Tracing{
+			Exporter:    "none",
+			Endpoint:    "localhost:4318",
+			Insecure:    true,
+			ServiceName: "becrpe",
+			SampleRatio: 1,
+		},
+		Mail: Mail{
+			Driver:  "smtp",
+			Address: "localhost:1025",
+			From:    "ECRPE <no-reply@ecrpe.fr>",
+		},
+		Account: Account{
+			URL:                  "https://rf.ecrpe.fr",
+			PasswordResetTTL:     1 * time.Hour,
+			EmailVerificationTTL: 72 * time.Hour,
+		},
+		RateLimit: RateLimit{
+			Login:             Limit{PerIP: 30, PerEmail: 10, Window: 10 * time.Minute},
+			CreateUser:        Limit{PerIP: 5, PerEmail: 3, Window: 1 * time.Hour},
+			PasswordReset:     Limit{PerIP: 10, PerEmail: 3, Window: 1 * time.Hour},
+			VerificationEmail: Limit{PerIP: 10, PerEmail: 3, Window: 1 * time.Hour},
+			Lockout:           Lockout{Threshold: 5, BaseDuration: 1 * time.Minute, MaxDuration: 1 * time.Hour},
+		},
+		TwoFactor: TwoFactor{
+			Issuer:       "ECRPE",
+			ChallengeTTL: 5 * time.Minute,
+		},
+		Password: Password{
+			Algorithm:  PasswordArgon2id,
+			Argon2:     Argon2{Memory: 64 * 1024, Iterations: 3, Parallelism: 2, SaltLength: 16, KeyLength: 32},
+			BcryptCost: 10,
+		},
+		Session: Session{
+			Mode:         SessionBearer,
+			SameSite:     "lax",
+			CookieMaxAge: 30 * 24 * time.Hour,
+		},
+	}
+}
+
+// Load builds the config from args (usually os.Args[1:]),
+// the config file path comes from -config flag or BECRPE_CONFIG
+func Load(name string, args []string) (*Config, error) {
+	cfg, _, err := LoadArgs(name, args)
+	return cfg, err
+}
+
+// LoadArgs is Load also returning the arguments left after the flags,
+// it lets subcommands share the server flags
+func LoadArgs(name string, args []string) (*Config, []string, error) {
+	cfg, rest, err := ParseArgs(name, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return cfg, rest, nil
+}
+
+// ParseArgs is LoadArgs without Validate, for the commands which check
+// only the sections they use with ValidateSections
+func ParseArgs(name string, args []string) (*Config, []string, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	configPath := fs.String("config", os.Getenv(envPrefix+"CONFIG"), "path to the YAML config file")
+	port := fs.String("port", "", "HTTP listen port")
+	dsn := fs.String("db-dsn", "", "MySQL data source name")
+	redisAddr := fs.String("redis-addr", "", "Redis address")
+	jwtSecret := fs.String("jwt-secret", "", "JWT signing secret key")
+	jwtIssuer := fs.String("jwt-issuer", "", "JWT issuer")
+	storageVideoURL := fs.String("storage-video-url", "", "storage server endpoint receiving videos")
+	storageDocURL := fs.String("storage-doc-url", "", "storage server endpoint receiving class papers")
+	logFile := fs.String("log-file", "", "log file path")
+	migrate := fs.Bool("migrate", false, "apply pending database migrations at startup")
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if *configPath != "" {
+		if err := cfg.loadFile(*configPath); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := cfg.loadEnv(); err != nil {
+		return nil, nil, err
+	}
+	// only flags explicitly set override file and env
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			cfg.Server.Port = *port
+		case "db-dsn":
+			cfg.Database.DSN = *dsn
+		case "redis-addr":
+			cfg.Redis.Address = *redisAddr
+		case "jwt-secret":
+			cfg.JWT.SecretKey = *jwtSecret
+		case "jwt-issuer":
+			cfg.JWT.Issuer = *jwtIssuer
+		case "storage-video-url":
+			cfg.Storage.VideoURL = *storageVideoURL
+		case "storage-doc-url":
+			cfg.Storage.DocURL = *storageDocURL
+		case "log-file":
+			cfg.Log.Filename = *logFile
+		case "migrate":
+			cfg.Database.MigrateOnStart = *migrate
+		}
+	})
+	return cfg, fs.Args(), nil
+}
+
+func (cfg *Config) loadFile(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "config: unable to read %s", path)
+	}
+	if err := yaml.UnmarshalStrict(b, cfg); err != nil {
+		return errors.Wrapf(err, "config: unable to parse %s", path)
+	}
+	return nil
+}
+
+func (cfg *Config) loadEnv() error {
+	strVars := map[string]*string{
+		"PORT":                     &cfg.Server.Port,
+		"DB_DSN":                   &cfg.Database.DSN,
+		"REDIS_ADDR":               &cfg.Redis.Address,
+		"REDIS_PASSWORD":           &cfg.Redis.Password,
+		"JWT_SECRET":               &cfg.JWT.SecretKey,
+		"JWT_ISSUER":               &cfg.JWT.Issuer,
+		"JWT_SIGNING_KEY":          &cfg.JWT.SigningKeyID,
+		"REFRESH_TOKEN_KEY":        &cfg.JWT.RefreshTokenKey,
+		"STORAGE_VIDEO_URL":        &cfg.Storage.VideoURL,
+		"STORAGE_DOC_URL":          &cfg.Storage.DocURL,
+		"STORAGE_SPOOL_DIR":        &cfg.Storage.SpoolDir,
+		"LOG_FILE":                 &cfg.Log.Filename,
+		"TRACING_EXPORTER":         &cfg.Tracing.Exporter,
+		"TRACING_ENDPOINT":         &cfg.Tracing.Endpoint,
+		"MAIL_DRIVER":              &cfg.Mail.Driver,
+		"MAIL_ADDRESS":             &cfg.Mail.Address,
+		"MAIL_USERNAME":            &cfg.Mail.Username,
+		"MAIL_PASSWORD":            &cfg.Mail.Password,
+		"MAIL_FROM":                &cfg.Mail.From,
+		"ACCOUNT_URL":              &cfg.Account.URL,
+		"EMAIL_VERIFICATION_KEY":   &cfg.Account.VerificationKey,
+		"TWO_FACTOR_KEY":           &cfg.TwoFactor.EncryptionKey,
+		"TWO_FACTOR_CHALLENGE_KEY": &cfg.TwoFactor.ChallengeKey,
+		"SESSION_MODE":             &cfg.Session.Mode,
+		"COOKIE_DOMAIN":            &cfg.Session.CookieDomain,
+	}
+	for name, dst := range strVars {
+		if v, ok := os.LookupEnv(envPrefix + name); ok {
+			*dst = v
+		}
+	}
+	durVars := map[string]*time.Duration{
+		"SHUTDOWN_TIMEOUT":       &cfg.Server.ShutdownTimeout,
+		"DB_CONN_MAX_LIFETIME":   &cfg.Database.ConnMaxLifetime,
+		"REDIS_TTL":              &cfg.Redis.TTL,
+		"JWT_EXPIRATION":         &cfg.JWT.Expiration,
+		"PASSWORD_RESET_TTL":     &cfg.Account.PasswordResetTTL,
+		"EMAIL_VERIFICATION_TTL": &cfg.Account.EmailVerificationTTL,
+	}
+	for name, dst := range durVars {
+		if v, ok := os.LookupEnv(envPrefix + name); ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return errors.Wrapf(err, "config: %s%s", envPrefix, name)
+			}
+			*dst = d
+		}
+	}
+	if v, ok := os.LookupEnv(envPrefix + "DB_MIGRATE_ON_START"); ok {
+		migrate, err := strconv.ParseBool(v)
+		if err != nil {
+			return errors.Wrapf(err, "config: %sDB_MIGRATE_ON_START", envPrefix)
+		}
+		cfg.Database.MigrateOnStart = migrate
+	}
+	if v, ok := os.LookupEnv(envPrefix + "REQUIRE_VERIFIED_EMAIL"); ok {
+		require, err := strconv.ParseBool(v)
+		if err != nil {
+			return errors.Wrapf(err, "config: %sREQUIRE_VERIFIED_EMAIL", envPrefix)
+		}
+		cfg.Account.RequireVerifiedEmail = require
+	}
+	if v, ok := os.LookupEnv(envPrefix + "ALLOWED_ORIGINS"); ok {
+		cfg.Server.AllowedOrigins = strings.Split(v, ",")
+	}
+	if v, ok := os.LookupEnv(envPrefix + "METRICS_ADDRESS"); ok {
+		cfg.Server.MetricsAddress = v
+	}
+	if v, ok := os.LookupEnv(envPrefix + "MAX_UPLOAD_SIZE"); ok {
+		size, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return errors.Wrapf(err, "config: %sMAX_UPLOAD_SIZE", envPrefix)
+		}
+		cfg.Server.MaxUploadSize = size
+	}
+	return nil
+}
+
+// Validate reports every missing or invalid setting at once
+func (cfg *Config) Validate() error {
+	var problems []string
+	if cfg.JWT.SecretKey == "" && cfg.JWT.SigningKeyID == "" {
+		problems = append(problems, "jwt.secret_key is missing (set "+envPrefix+"JWT_SECRET) and no jwt.signing_key_id is set")
+	}
+	problems = append(problems, cfg.JWT.validateKeys()...)
+	if len(cfg.JWT.RefreshTokenKey) < 32 {
+		problems = append(problems, "jwt.refresh_token_key must be at least 32 characters (set "+envPrefix+"REFRESH_TOKEN_KEY)")
+	}
+	problems = append(problems, cfg.validateDatabase()...)
+	problems = append(problems, cfg.validateRedis()...)
+	if _, err := strconv.Atoi(cfg.Server.Port); err != nil {
+		problems = append(problems, fmt.Sprintf("server.port %q is not a number", cfg.Server.Port))
+	}
+	if cfg.Server.MaxUploadSize <= 0 {
+		problems = append(problems, "server.max_upload_size must be positive")
+	}
+	if cfg.Server.ShutdownTimeout <= 0 {
+		problems = append(problems, "server.shutdown_timeout must be positive")
+	}
+	if cfg.JWT.Issuer == "" {
+		problems = append(problems, "jwt.issuer is missing")
+	}
+	problems = append(problems, cfg.validateRevocation()...)
+	problems = append(problems, cfg.validateStorage()...)
+	switch cfg.Tracing.Exporter {
+	case "none", "stdout", "otlp":
+	default:
+		problems = append(problems, fmt.Sprintf("tracing.exporter %q must be none, stdout or otlp", cfg.Tracing.Exporter))
+	}
+	if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+		problems = append(problems, "tracing.sample_ratio must be between 0 and 1")
+	}
+	switch cfg.Mail.Driver {
+	case "smtp":
+		if _, _, err := net.SplitHostPort(cfg.Mail.Address); err != nil {
+			problems = append(problems, fmt.Sprintf("mail.address %q must be host:port", cfg.Mail.Address))
+		}
+		if cfg.Mail.From == "" {
+			problems = append(problems, "mail.from is missing")
+		}
+	case "log":
+	default:
+		problems = append(problems, fmt.Sprintf("mail.driver %q must be smtp or log", cfg.Mail.Driver))
+	}
+	if u, err := url.Parse(cfg.Account.URL); err != nil || u.Scheme == "" || u.Host == "" {
+		problems = append(problems, fmt.Sprintf("account.url %q is not a valid URL", cfg.Account.URL))
+	}
+	if cfg.Account.PasswordResetTTL <= 0 {
+		problems = append(problems, "account.password_reset_ttl must be positive")
+	}
+	if len(cfg.Account.VerificationKey) < 32 {
+		problems = append(problems, "account.verification_key must be at least 32 characters (set "+envPrefix+"EMAIL_VERIFICATION_KEY)")
+	}
+	if cfg.Account.EmailVerificationTTL <= 0 {
+		problems = append(problems, "account.email_verification_ttl must be positive")
+	}
+	for _, limit := range []struct {
+		key string
+		Limit
+	}{
+		{"rate_limit.login", cfg.RateLimit.Login},
+		{"rate_limit.create_user", cfg.RateLimit.CreateUser},
+		{"rate_limit.password_reset", cfg.RateLimit.PasswordReset},
+		{"rate_limit.verification_email", cfg.RateLimit.VerificationEmail},
+	} {
+		if limit.PerIP < 0 || limit.PerEmail < 0 {
+			problems = append(problems, limit.key+" caps cannot be negative")
+		}
+		if (limit.PerIP > 0 || limit.PerEmail > 0) && limit.Window <= 0 {
+			problems = append(problems, limit.key+".window must be positive")
+		}
+	}
+	if lockout := cfg.RateLimit.Lockout; lockout.Threshold < 0 {
+		problems = append(problems, "rate_limit.lockout.threshold cannot be negative")
+	} else if lockout.Threshold > 0 && (lockout.BaseDuration <= 0 || lockout.MaxDuration < lockout.BaseDuration) {
+		problems = append(problems, "rate_limit.lockout.base_duration must be positive and not above max_duration")
+	}
+	if cfg.TwoFactor.Issuer == "" {
+		problems = append(problems, "two_factor.issuer is missing")
+	}
+	if len(cfg.TwoFactor.EncryptionKey) < 32 {
+		problems = append(problems, "two_factor.encryption_key must be at least 32 characters (set "+envPrefix+"TWO_FACTOR_KEY)")
+	}
+	if len(cfg.TwoFactor.ChallengeKey) < 32 {
+		problems = append(problems, "two_factor.challenge_key must be at least 32 characters (set "+envPrefix+"TWO_FACTOR_CHALLENGE_KEY)")
+	} else if cfg.TwoFactor.ChallengeKey == cfg.Account.VerificationKey {
+		problems = append(problems, "two_factor.challenge_key must differ from account.verification_key")
+	}
+	if cfg.TwoFactor.ChallengeTTL <= 0 {
+		problems = append(problems, "two_factor.challenge_ttl must be positive")
+	}
+	for _, role := range cfg.TwoFactor.RequiredFor {
+		switch role {
+		case "student", "teacher", "admin", "support":
+		default:
+			problems = append(problems, fmt.Sprintf("two_factor.required_for: unknown role %q, must be student, teacher, admin or support", role))
+		}
+	}
+	switch cfg.Password.Algorithm {
+	case PasswordArgon2id:
+		// users.encrypted_pwd is a VARCHAR(255)
+		if a := cfg.Password.Argon2; a.Iterations < 1 || a.Parallelism < 1 || a.Memory < 8*uint32(a.Parallelism) ||
+			a.SaltLength < 8 || a.KeyLength < 16 || a.SaltLength+a.KeyLength > 128 {
+			problems = append(problems, "password.argon2 needs iterations and parallelism of at least 1, memory of at least 8 KiB per thread, "+
+				"a salt_length of at least 8, a key_length of at least 16 and at most 128 bytes for both")
+		}
+	case PasswordBcrypt:
+		if cfg.Password.BcryptCost < 10 || cfg.Password.BcryptCost > 31 {
+			problems = append(problems, "password.bcrypt_cost must be between 10 and 31")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("password.algorithm %q must be argon2id or bcrypt", cfg.Password.Algorithm))
+	}
+	switch cfg.Session.Mode {
+	case SessionBearer, SessionCookie:
+	default:
+		problems = append(problems, fmt.Sprintf("session.mode %q must be bearer or cookie", cfg.Session.Mode))
+	}
+	switch cfg.Session.SameSite {
+	case "strict", "lax":
+	case "none":
+		if cfg.Session.CookieInsecure {
+			problems = append(problems, "session.same_site none needs secure cookies, unset session.cookie_insecure")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("session.same_site %q must be strict, lax or none", cfg.Session.SameSite))
+	}
+	if cfg.Session.CookieMaxAge <= 0 {
+		problems = append(problems, "session.cookie_max_age must be positive")
+	}
+	if len(cfg.Server.AllowedOrigins) == 0 {
+		problems = append(problems, "server.allowed_origins is empty")
+	}
+	for _, origin := range cfg.Server.AllowedOrigins {
+		if origin == "*" {
+			// the browsers would send the session cookies from any site
+			if cfg.Session.Mode == SessionCookie {
+				problems = append(problems, "server.allowed_origins cannot contain \"*\" in cookie session mode")
+			}
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			problems = append(problems, fmt.Sprintf("server.allowed_origins: %q is not an origin (scheme://host[:port])", origin))
+		}
+	}
+	return joinProblems(problems)
+}
+
+// Section is a part of the config which ValidateSections checks alone
+type Section string
+
+const (
+	SectionDatabase Section = "database"
+	SectionRedis    Section = "redis"
+	// SectionRevocation is the jwt.expiration the revoked sessions stay in redis for
+	SectionRevocation Section = "revocation"
+	SectionStorage    Section = "storage"
+)
+
+var sectionChecks = map[Section]func(cfg *Config) []string{
+	SectionDatabase:   (*Config).validateDatabase,
+	SectionRedis:      (*Config).validateRedis,
+	SectionRevocation: (*Config).validateRevocation,
+	SectionStorage:    (*Config).validateStorage,
+}
+
+// ValidateSections is Validate restricted to sections, so that a host with
+// only the database credentials can run what needs nothing else
+func (cfg *Config) ValidateSections(sections ...Section) error {
+	var problems []string
+	for _, section := range sections {
+		check, ok := sectionChecks[section]
+		if !ok {
+			return errors.Errorf("config: unknown section %q", section)
+		}
+		problems = append(problems, check(cfg)...)
+	}
+	return joinProblems(problems)
+}
+
+func joinProblems(problems []string) error {
+	if len(problems) > 0 {
+		return fmt.Errorf("config: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (cfg *Config) validateDatabase() []string {
+	if cfg.Database.DSN == "" {
+		return []string{"database.dsn is missing (set " + envPrefix + "DB_DSN)"}
+	}
+	return nil
+}
+
+func (cfg *Config) validateRedis() []string {
+	if cfg.Redis.Address == "" {
+		return []string{"redis.address is missing"}
+	}
+	return nil
+}
+
+func (cfg *Config) validateRevocation() []string {
+	if cfg.JWT.Expiration <= 0 {
+		return []string{"jwt.expiration must be positive"}
+	}
+	return nil
+}
+
+func (cfg *Config) validateStorage() []string {
+	var problems []string
+	for _, storageURL := range []struct{ key, raw string }{
+		{"storage.video_url", cfg.Storage.VideoURL},
+		{"storage.doc_url", cfg.Storage.DocURL},
+	} {
+		if u, err := url.Parse(storageURL.raw); err != nil || u.Scheme == "" || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("%s %q is not a valid URL", storageURL.key, storageURL.raw))
+		}
+	}
+	if cfg.Storage.SpoolDir == "" {
+		problems = append(problems, "storage.spool_dir is missing")
+	}
+	if cfg.Storage.MaxRunningJobs < 1 {
+		problems = append(problems, "storage.max_running_jobs must be at least 1")
+	}
+	if cfg.Storage.MaxQueuedJobs < 0 {
+		problems = append(problems, "storage.max_queued_jobs cannot be negative")
+	}
+	return problems
+}
+
+func (j JWT) validateKeys() []string {
+	var problems []string
+	ids := make(map[string]bool)
+	signing := false
+	for i, k := range j.Keys {
+		switch {
+		case k.ID == "":
+			problems = append(problems, fmt.Sprintf("jwt.keys[%d].id is missing", i))
+		case ids[k.ID]:
+			problems = append(problems, fmt.Sprintf("jwt.keys[%d].id %q is duplicated", i, k.ID))
+		}
+		ids[k.ID] = true
+		switch k.Algorithm {
+		case "RS256", "EdDSA":
+		default:
+			problems = append(problems, fmt.Sprintf("jwt.keys[%d].algorithm %q must be RS256 or EdDSA", i, k.Algorithm))
+		}
+		if k.PrivateKeyFile == "" && k.PublicKeyFile == "" {
+			problems = append(problems, fmt.Sprintf("jwt.keys[%d] needs a private_key_file or a public_key_file", i))
+		}
+		if k.ID == j.SigningKeyID {
+			signing = true
+			if k.PrivateKeyFile == "" {
+				problems = append(problems, fmt.Sprintf("jwt.keys[%d] signs tokens, its private_key_file is missing", i))
+			}
+		}
+	}
+	if j.SigningKeyID != "" && !signing {
+		problems = append(problems, fmt.Sprintf("jwt.signing_key_id %q is not in jwt.keys", j.SigningKeyID))
+	}
+	return problems
+}
@@ -0,0 +1,154 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/juleur/becrpe/graph/generated"
+	"github.com/juleur/becrpe/graph/model"
+	"github.com/juleur/becrpe/interceptors"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Directives implements the schema directives, their checks run before
+// the resolvers of the fields they annotate
+func (r *Resolver) Directives() generated.DirectiveRoot {
+	return generated.DirectiveRoot{
+		Auth:          r.auth,
+		HasRole:       r.hasRole,
+		HasPermission: r.hasPermission,
+	}
+}
+
+type authUserContextKey struct{}
+
+// authUser returns the user checked by @auth, @hasRole or @hasPermission,
+// it panics in a resolver whose field has none of them
+func authUser(ctx context.Context) interceptors.User {
+	userAuth, ok := ctx.Value(authUserContextKey{}).(interceptors.User)
+	if !ok {
+		panic("authUser outside of an @auth, @hasRole or @hasPermission field")
+	}
+	return userAuth
+}
+
+// authenticate returns ctx carrying the user of a valid JWT for authUser
+func (r *Resolver) authenticate(ctx context.Context) (context.Context, interceptors.User, error) {
+	userAuth := interceptors.ForUserContext(ctx)
+	if !userAuth.IsAuth {
+		r.log(ctx).Errorln(fmt.Sprintf("User n°%d authentication didn't succeed", userAuth.UserID), "HttpErrorStatus", userAuth.HttpErrorResponse.StatusText)
+		return ctx, userAuth, &gqlerror.Error{
+			Message: userAuth.HttpErrorResponse.Message,
+			Extensions: map[string]interface{}{
+				"statusCode": userAuth.HttpErrorResponse.StatusCode,
+				"statusText": userAuth.HttpErrorResponse.StatusText,
+			},
+		}
+	}
+	return context.WithValue(ctx, authUserContextKey{}, userAuth), userAuth, nil
+}
+
+// auth lets authenticated users resolve the field, on an input field it
+// refuses the argument to the others
+func (r *Resolver) auth(ctx context.Context, obj interface{}, next graphql.Resolver) (interface{}, error) {
+	ctx, _, err := r.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return next(ctx)
+}
+
+// hasRole lets authenticated users holding one of roles resolve the field
+func (r *Resolver) hasRole(ctx context.Context, obj interface{}, next graphql.Resolver, roles []model.Role) (interface{}, error) {
+	ctx, userAuth, err := r.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		if userAuth.HasRole(role.Name()) {
+			return r.withTwoFactor(ctx, userAuth, next)
+		}
+	}
+	r.log(ctx).Errorln(fmt.Sprintf("User n°%d lacks one of the roles %v", userAuth.UserID, roles))
+	return nil, forbidden()
+}
+
+// hasPermission lets authenticated users whose roles grant permission resolve the field
+func (r *Resolver) hasPermission(ctx context.Context, obj interface{}, next graphql.Resolver, permission model.Permission) (interface{}, error) {
+	ctx, userAuth, err := r.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !userAuth.HasPermission(permission.Name()) {
+		r.log(ctx).Errorln(fmt.Sprintf("User n°%d lacks the permission %s", userAuth.UserID, permission))
+		return nil, forbidden()
+	}
+	return r.withTwoFactor(ctx, userAuth, next)
+}
+
+// withTwoFactor resolves the field unless a role of the user requires
+// two-factor authentication (two_factor.required_for) the user has not enabled
+func (r *Resolver) withTwoFactor(ctx context.Context, userAuth interceptors.User, next graphql.Resolver) (interface{}, error) {
+	required := false
+	for _, role := range userAuth.Roles {
+		required = required || r.TwoFactorConfig.Requires(role)
+	}
+	if !required {
+		return next(ctx)
+	}
+	user, err := r.Users.ByID(ctx, userAuth.UserID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return nil, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	if !user.TOTPEnabledAt.Valid {
+		r.log(ctx).Errorln(fmt.Sprintf("User n°%d has no two-factor authentication", userAuth.UserID))
+		return nil, &gqlerror.Error{
+			Message: "Veuillez activer la double authentification pour accéder à cette fonctionnalité",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusForbidden,
+				"statusText": http.StatusText(http.StatusForbidden),
+			},
+		}
+	}
+	return next(ctx)
+}
+
+// targetUser returns the user a request is about, the caller unless userID
+// names another user and the caller may read other users
+func (r *Resolver) targetUser(ctx context.Context, userAuth interceptors.User, userID *int) (int, error) {
+	if userID == nil || *userID == userAuth.UserID {
+		return userAuth.UserID, nil
+	}
+	if !userAuth.HasPermission(model.PermissionUserRead.Name()) {
+		r.log(ctx).Errorln(fmt.Sprintf("User n°%d asked about user n°%d", userAuth.UserID, *userID))
+		return 0, forbidden()
+	}
+	r.log(ctx).WithField("security_event", "user_read").Infoln(fmt.Sprintf("User n°%d looked at user n°%d", userAuth.UserID, *userID))
+	return *userID, nil
+}
+
+// readsUser answers whether the caller is the user userID or may read users,
+// for the fields of User resolved outside of the directives
+func readsUser(ctx context.Context, userID int) bool {
+	userAuth := interceptors.ForUserContext(ctx)
+	return userAuth.IsAuth && (userAuth.UserID == userID || userAuth.HasPermission(model.PermissionUserRead.Name()))
+}
+
+func forbidden() error {
+	return &gqlerror.Error{
+		Message: "Vous n'avez pas les droits nécessaires pour effectuer cette action",
+		Extensions: map[string]interface{}{
+			"statusCode": http.StatusForbidden,
+			"statusText": http.StatusText(http.StatusForbidden),
+		},
+	}
+}
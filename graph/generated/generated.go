@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -36,12 +37,17 @@ type Config struct {
 }
 
 type ResolverRoot interface {
+	Me() MeResolver
 	Mutation() MutationResolver
 	Query() QueryResolver
 	RefresherCourse() RefresherCourseResolver
+	User() UserResolver
 }
 
 type DirectiveRoot struct {
+	Auth          func(ctx context.Context, obj interface{}, next graphql.Resolver) (res interface{}, err error)
+	HasPermission func(ctx context.Context, obj interface{}, next graphql.Resolver, permission model.Permission) (res interface{}, err error)
+	HasRole       func(ctx context.Context, obj interface{}, next graphql.Resolver, roles []model.Role) (res interface{}, err error)
 }
 
 type ComplexityRoot struct {
@@ -53,19 +59,65 @@ type ComplexityRoot struct {
 		UpdatedAt func(childComplexity int) int
 	}
 
+	DataExport struct {
+		Content     func(childComplexity int) int
+		ContentType func(childComplexity int) int
+		Filename    func(childComplexity int) int
+	}
+
+	DeviceSession struct {
+		Current     func(childComplexity int) int
+		DeliveredAt func(childComplexity int) int
+		ID          func(childComplexity int) int
+		IPAddress   func(childComplexity int) int
+		Origin      func(childComplexity int) int
+		UserAgent   func(childComplexity int) int
+	}
+
+	Me struct {
+		DeviceSessions   func(childComplexity int) int
+		PurchasedCourses func(childComplexity int) int
+		User             func(childComplexity int) int
+	}
+
 	Mutation struct {
+		ConfirmTwoFactor        func(childComplexity int, input model.ConfirmTwoFactorInput) int
 		CreateRefresherCourse   func(childComplexity int, input model.NewSessionInput) int
 		CreateUser              func(childComplexity int, input model.NewUserInput) int
+		DeleteAccount           func(childComplexity int, input model.DeleteAccountInput) int
+		DisableTwoFactor        func(childComplexity int, code string) int
+		EnableTwoFactor         func(childComplexity int, input model.EnableTwoFactorInput) int
+		ExportMyData            func(childComplexity int) int
+		GrantRole               func(childComplexity int, userID int, role model.Role) int
+		Login                   func(childComplexity int, input model.LoginInput) int
+		Logout                  func(childComplexity int) int
+		LogoutEverywhere        func(childComplexity int) int
 		PurchaseRefresherCourse func(childComplexity int, input model.PurchaseRefresherCourseInput) int
-		RefreshToken            func(childComplexity int, refreshToken string) int
+		RefreshToken            func(childComplexity int, refreshToken *string) int
+		RequestPasswordReset    func(childComplexity int, email string) int
+		ResendVerificationEmail func(childComplexity int) int
+		ResetPassword           func(childComplexity int, input model.ResetPasswordInput) int
+		RevokeDeviceSession     func(childComplexity int, deviceSessionID int) int
+		RevokeRole              func(childComplexity int, userID int, role model.Role) int
 		UpdateUser              func(childComplexity int, input model.UpdateUserInput) int
+		VerifyEmail             func(childComplexity int, token string) int
+		VerifyTwoFactor         func(childComplexity int, input model.VerifyTwoFactorInput) int
+	}
+
+	PurchasedCourse struct {
+		PurchasedAt     func(childComplexity int) int
+		RefresherCourse func(childComplexity int) int
+		TotalSessions   func(childComplexity int) int
+		ViewedSessions  func(childComplexity int) int
 	}
 
 	Query struct {
 		AuthTeacher       func(childComplexity int, userID int) int
+		DeviceSessions    func(childComplexity int) int
 		Login             func(childComplexity int, input model.LoginInput) int
+		Me                func(childComplexity int) int
 		PlayerCheckUser   func(childComplexity int) int
-		Profile           func(childComplexity int, userID int) int
+		Profile           func(childComplexity int, userID *int) int
 		RefresherCourse   func(childComplexity int, refresherCourseID int) int
 		RefresherCourses  func(childComplexity int, input model.RefresherCourseInput) int
 		SessionCourse     func(childComplexity int, input model.SessionInput) int
@@ -111,18 +163,27 @@ type ComplexityRoot struct {
 	}
 
 	Token struct {
-		Jwt          func(childComplexity int) int
-		RefreshToken func(childComplexity int) int
+		Jwt                func(childComplexity int) int
+		RefreshToken       func(childComplexity int) int
+		TwoFactorChallenge func(childComplexity int) int
+	}
+
+	TwoFactorEnrollment struct {
+		OtpauthURI func(childComplexity int) int
+		Secret     func(childComplexity int) int
 	}
 
 	User struct {
-		CreatedAt func(childComplexity int) int
-		Email     func(childComplexity int) int
-		Fullname  func(childComplexity int) int
-		ID        func(childComplexity int) int
-		IsTeacher func(childComplexity int) int
-		UpdatedAt func(childComplexity int) int
-		Username  func(childComplexity int) int
+		CreatedAt        func(childComplexity int) int
+		Email            func(childComplexity int) int
+		EmailVerified    func(childComplexity int) int
+		Fullname         func(childComplexity int) int
+		ID               func(childComplexity int) int
+		IsTeacher        func(childComplexity int) int
+		Roles            func(childComplexity int) int
+		TwoFactorEnabled func(childComplexity int) int
+		UpdatedAt        func(childComplexity int) int
+		Username         func(childComplexity int) int
 	}
 
 	Video struct {
@@ -134,29 +195,61 @@ type ComplexityRoot struct {
 	}
 }
 
+type MeResolver interface {
+	PurchasedCourses(ctx context.Context, obj *model.Me) ([]*model.PurchasedCourse, error)
+	DeviceSessions(ctx context.Context, obj *model.Me) ([]*model.DeviceSession, error)
+}
 type MutationResolver interface {
+	Login(ctx context.Context, input model.LoginInput) (*model.Token, error)
 	CreateUser(ctx context.Context, input model.NewUserInput) (bool, error)
-	RefreshToken(ctx context.Context, refreshToken string) (*model.Token, error)
+	RefreshToken(ctx context.Context, refreshToken *string) (*model.Token, error)
 	UpdateUser(ctx context.Context, input model.UpdateUserInput) (*model.User, error)
 	PurchaseRefresherCourse(ctx context.Context, input model.PurchaseRefresherCourseInput) (bool, error)
 	CreateRefresherCourse(ctx context.Context, input model.NewSessionInput) (bool, error)
+	RevokeDeviceSession(ctx context.Context, deviceSessionID int) (bool, error)
+	LogoutEverywhere(ctx context.Context) (bool, error)
+	Logout(ctx context.Context) (bool, error)
+	RequestPasswordReset(ctx context.Context, email string) (bool, error)
+	ResetPassword(ctx context.Context, input model.ResetPasswordInput) (bool, error)
+	VerifyEmail(ctx context.Context, token string) (bool, error)
+	ResendVerificationEmail(ctx context.Context) (bool, error)
+	EnableTwoFactor(ctx context.Context, input model.EnableTwoFactorInput) (*model.TwoFactorEnrollment, error)
+	ConfirmTwoFactor(ctx context.Context, input model.ConfirmTwoFactorInput) ([]string, error)
+	DisableTwoFactor(ctx context.Context, code string) (bool, error)
+	VerifyTwoFactor(ctx context.Context, input model.VerifyTwoFactorInput) (*model.Token, error)
+	GrantRole(ctx context.Context, userID int, role model.Role) (bool, error)
+	RevokeRole(ctx context.Context, userID int, role model.Role) (bool, error)
+	ExportMyData(ctx context.Context) (*model.DataExport, error)
+	DeleteAccount(ctx context.Context, input model.DeleteAccountInput) (bool, error)
 }
 type QueryResolver interface {
 	Login(ctx context.Context, input model.LoginInput) (*model.Token, error)
+	Me(ctx context.Context) (*model.Me, error)
 	RefresherCourses(ctx context.Context, input model.RefresherCourseInput) ([]*model.RefresherCourse, error)
 	RefresherCourse(ctx context.Context, refresherCourseID int) (*model.RefresherCourseResponse, error)
 	PlayerCheckUser(ctx context.Context) (bool, error)
-	Profile(ctx context.Context, userID int) (*model.User, error)
+	Profile(ctx context.Context, userID *int) (*model.User, error)
 	SessionCourse(ctx context.Context, input model.SessionInput) (*model.SessionResponse, error)
 	AuthTeacher(ctx context.Context, userID int) (bool, error)
 	SubjectsEnum(ctx context.Context) ([]string, error)
 	TotalHoursCourses(ctx context.Context) (string, error)
+	DeviceSessions(ctx context.Context) ([]*model.DeviceSession, error)
 }
 type RefresherCourseResolver interface {
 	TotalDuration(ctx context.Context, obj *model.RefresherCourse) (*string, error)
 	IsPurchased(ctx context.Context, obj *model.RefresherCourse) (*bool, error)
 	Teachers(ctx context.Context, obj *model.RefresherCourse) ([]*model.User, error)
 }
+type UserResolver interface {
+	Fullname(ctx context.Context, obj *model.User) (*string, error)
+
+	EmailVerified(ctx context.Context, obj *model.User) (bool, error)
+	TwoFactorEnabled(ctx context.Context, obj *model.User) (*bool, error)
+	IsTeacher(ctx context.Context, obj *model.User) (*bool, error)
+	Roles(ctx context.Context, obj *model.User) ([]model.Role, error)
+
+	UpdatedAt(ctx context.Context, obj *model.User) (*time.Time, error)
+}
 
 type executableSchema struct {
 	resolvers  ResolverRoot
@@ -208,6 +301,102 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.ClassPaper.UpdatedAt(childComplexity), true
 
+	case "DataExport.content":
+		if e.complexity.DataExport.Content == nil {
+			break
+		}
+
+		return e.complexity.DataExport.Content(childComplexity), true
+
+	case "DataExport.contentType":
+		if e.complexity.DataExport.ContentType == nil {
+			break
+		}
+
+		return e.complexity.DataExport.ContentType(childComplexity), true
+
+	case "DataExport.filename":
+		if e.complexity.DataExport.Filename == nil {
+			break
+		}
+
+		return e.complexity.DataExport.Filename(childComplexity), true
+
+	case "DeviceSession.current":
+		if e.complexity.DeviceSession.Current == nil {
+			break
+		}
+
+		return e.complexity.DeviceSession.Current(childComplexity), true
+
+	case "DeviceSession.deliveredAt":
+		if e.complexity.DeviceSession.DeliveredAt == nil {
+			break
+		}
+
+		return e.complexity.DeviceSession.DeliveredAt(childComplexity), true
+
+	case "DeviceSession.id":
+		if e.complexity.DeviceSession.ID == nil {
+			break
+		}
+
+		return e.complexity.DeviceSession.ID(childComplexity), true
+
+	case "DeviceSession.ipAddress":
+		if e.complexity.DeviceSession.IPAddress == nil {
+			break
+		}
+
+		return e.complexity.DeviceSession.IPAddress(childComplexity), true
+
+	case "DeviceSession.origin":
+		if e.complexity.DeviceSession.Origin == nil {
+			break
+		}
+
+		return e.complexity.DeviceSession.Origin(childComplexity), true
+
+	case "DeviceSession.userAgent":
+		if e.complexity.DeviceSession.UserAgent == nil {
+			break
+		}
+
+		return e.complexity.DeviceSession.UserAgent(childComplexity), true
+
+	case "Me.deviceSessions":
+		if e.complexity.Me.DeviceSessions == nil {
+			break
+		}
+
+		return e.complexity.Me.DeviceSessions(childComplexity), true
+
+	case "Me.purchasedCourses":
+		if e.complexity.Me.PurchasedCourses == nil {
+			break
+		}
+
+		return e.complexity.Me.PurchasedCourses(childComplexity), true
+
+	case "Me.user":
+		if e.complexity.Me.User == nil {
+			break
+		}
+
+		return e.complexity.Me.User(childComplexity), true
+
+	case "Mutation.confirmTwoFactor":
+		if e.complexity.Mutation.ConfirmTwoFactor == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_confirmTwoFactor_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.ConfirmTwoFactor(childComplexity, args["input"].(model.ConfirmTwoFactorInput)), true
+
 	case "Mutation.createRefresherCourse":
 		if e.complexity.Mutation.CreateRefresherCourse == nil {
 			break
@@ -232,6 +421,87 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Mutation.CreateUser(childComplexity, args["input"].(model.NewUserInput)), true
 
+	case "Mutation.deleteAccount":
+		if e.complexity.Mutation.DeleteAccount == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_deleteAccount_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.DeleteAccount(childComplexity, args["input"].(model.DeleteAccountInput)), true
+
+	case "Mutation.disableTwoFactor":
+		if e.complexity.Mutation.DisableTwoFactor == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_disableTwoFactor_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.DisableTwoFactor(childComplexity, args["code"].(string)), true
+
+	case "Mutation.enableTwoFactor":
+		if e.complexity.Mutation.EnableTwoFactor == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_enableTwoFactor_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.EnableTwoFactor(childComplexity, args["input"].(model.EnableTwoFactorInput)), true
+
+	case "Mutation.exportMyData":
+		if e.complexity.Mutation.ExportMyData == nil {
+			break
+		}
+
+		return e.complexity.Mutation.ExportMyData(childComplexity), true
+
+	case "Mutation.grantRole":
+		if e.complexity.Mutation.GrantRole == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_grantRole_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.GrantRole(childComplexity, args["userId"].(int), args["role"].(model.Role)), true
+
+	case "Mutation.login":
+		if e.complexity.Mutation.Login == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_login_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.Login(childComplexity, args["input"].(model.LoginInput)), true
+
+	case "Mutation.logout":
+		if e.complexity.Mutation.Logout == nil {
+			break
+		}
+
+		return e.complexity.Mutation.Logout(childComplexity), true
+
+	case "Mutation.logoutEverywhere":
+		if e.complexity.Mutation.LogoutEverywhere == nil {
+			break
+		}
+
+		return e.complexity.Mutation.LogoutEverywhere(childComplexity), true
+
 	case "Mutation.purchaseRefresherCourse":
 		if e.complexity.Mutation.PurchaseRefresherCourse == nil {
 			break
@@ -254,7 +524,62 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 			return 0, false
 		}
 
-		return e.complexity.Mutation.RefreshToken(childComplexity, args["refreshToken"].(string)), true
+		return e.complexity.Mutation.RefreshToken(childComplexity, args["refreshToken"].(*string)), true
+
+	case "Mutation.requestPasswordReset":
+		if e.complexity.Mutation.RequestPasswordReset == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_requestPasswordReset_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.RequestPasswordReset(childComplexity, args["email"].(string)), true
+
+	case "Mutation.resendVerificationEmail":
+		if e.complexity.Mutation.ResendVerificationEmail == nil {
+			break
+		}
+
+		return e.complexity.Mutation.ResendVerificationEmail(childComplexity), true
+
+	case "Mutation.resetPassword":
+		if e.complexity.Mutation.ResetPassword == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_resetPassword_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.ResetPassword(childComplexity, args["input"].(model.ResetPasswordInput)), true
+
+	case "Mutation.revokeDeviceSession":
+		if e.complexity.Mutation.RevokeDeviceSession == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_revokeDeviceSession_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.RevokeDeviceSession(childComplexity, args["deviceSessionId"].(int)), true
+
+	case "Mutation.revokeRole":
+		if e.complexity.Mutation.RevokeRole == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_revokeRole_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.RevokeRole(childComplexity, args["userId"].(int), args["role"].(model.Role)), true
 
 	case "Mutation.updateUser":
 		if e.complexity.Mutation.UpdateUser == nil {
@@ -268,6 +593,58 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Mutation.UpdateUser(childComplexity, args["input"].(model.UpdateUserInput)), true
 
+	case "Mutation.verifyEmail":
+		if e.complexity.Mutation.VerifyEmail == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_verifyEmail_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.VerifyEmail(childComplexity, args["token"].(string)), true
+
+	case "Mutation.verifyTwoFactor":
+		if e.complexity.Mutation.VerifyTwoFactor == nil {
+			break
+		}
+
+		args, err := ec.field_Mutation_verifyTwoFactor_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Mutation.VerifyTwoFactor(childComplexity, args["input"].(model.VerifyTwoFactorInput)), true
+
+	case "PurchasedCourse.purchasedAt":
+		if e.complexity.PurchasedCourse.PurchasedAt == nil {
+			break
+		}
+
+		return e.complexity.PurchasedCourse.PurchasedAt(childComplexity), true
+
+	case "PurchasedCourse.refresherCourse":
+		if e.complexity.PurchasedCourse.RefresherCourse == nil {
+			break
+		}
+
+		return e.complexity.PurchasedCourse.RefresherCourse(childComplexity), true
+
+	case "PurchasedCourse.totalSessions":
+		if e.complexity.PurchasedCourse.TotalSessions == nil {
+			break
+		}
+
+		return e.complexity.PurchasedCourse.TotalSessions(childComplexity), true
+
+	case "PurchasedCourse.viewedSessions":
+		if e.complexity.PurchasedCourse.ViewedSessions == nil {
+			break
+		}
+
+		return e.complexity.PurchasedCourse.ViewedSessions(childComplexity), true
+
 	case "Query.authTeacher":
 		if e.complexity.Query.AuthTeacher == nil {
 			break
@@ -280,6 +657,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Query.AuthTeacher(childComplexity, args["userId"].(int)), true
 
+	case "Query.deviceSessions":
+		if e.complexity.Query.DeviceSessions == nil {
+			break
+		}
+
+		return e.complexity.Query.DeviceSessions(childComplexity), true
+
 	case "Query.login":
 		if e.complexity.Query.Login == nil {
 			break
@@ -292,6 +676,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Query.Login(childComplexity, args["input"].(model.LoginInput)), true
 
+	case "Query.me":
+		if e.complexity.Query.Me == nil {
+			break
+		}
+
+		return e.complexity.Query.Me(childComplexity), true
+
 	case "Query.playerCheckUser":
 		if e.complexity.Query.PlayerCheckUser == nil {
 			break
@@ -309,7 +700,7 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 			return 0, false
 		}
 
-		return e.complexity.Query.Profile(childComplexity, args["userId"].(int)), true
+		return e.complexity.Query.Profile(childComplexity, args["userId"].(*int)), true
 
 	case "Query.refresherCourse":
 		if e.complexity.Query.RefresherCourse == nil {
@@ -550,6 +941,27 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Token.RefreshToken(childComplexity), true
 
+	case "Token.twoFactorChallenge":
+		if e.complexity.Token.TwoFactorChallenge == nil {
+			break
+		}
+
+		return e.complexity.Token.TwoFactorChallenge(childComplexity), true
+
+	case "TwoFactorEnrollment.otpauthUri":
+		if e.complexity.TwoFactorEnrollment.OtpauthURI == nil {
+			break
+		}
+
+		return e.complexity.TwoFactorEnrollment.OtpauthURI(childComplexity), true
+
+	case "TwoFactorEnrollment.secret":
+		if e.complexity.TwoFactorEnrollment.Secret == nil {
+			break
+		}
+
+		return e.complexity.TwoFactorEnrollment.Secret(childComplexity), true
+
 	case "User.createdAt":
 		if e.complexity.User.CreatedAt == nil {
 			break
@@ -564,6 +976,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.User.Email(childComplexity), true
 
+	case "User.emailVerified":
+		if e.complexity.User.EmailVerified == nil {
+			break
+		}
+
+		return e.complexity.User.EmailVerified(childComplexity), true
+
 	case "User.fullname":
 		if e.complexity.User.Fullname == nil {
 			break
@@ -585,6 +1004,20 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.User.IsTeacher(childComplexity), true
 
+	case "User.roles":
+		if e.complexity.User.Roles == nil {
+			break
+		}
+
+		return e.complexity.User.Roles(childComplexity), true
+
+	case "User.twoFactorEnabled":
+		if e.complexity.User.TwoFactorEnabled == nil {
+			break
+		}
+
+		return e.complexity.User.TwoFactorEnabled(childComplexity), true
+
 	case "User.updatedAt":
 		if e.complexity.User.UpdatedAt == nil {
 			break
@@ -702,6 +1135,14 @@ var sources = []*ast.Source{
 #
 # https://gqlgen.com/getting-started/
 
+# Fields and arguments restricted to authenticated users, an expired JWT gets
+# a 498 error to refresh it, any other failure a 401 (see graph/directives.go)
+directive @auth on FIELD_DEFINITION | INPUT_FIELD_DEFINITION
+# Fields restricted to authenticated users holding one of the roles or the
+# permission, read from the JWT claims
+directive @hasRole(roles: [Role!]!) on FIELD_DEFINITION
+directive @hasPermission(permission: Permission!) on FIELD_DEFINITION
+
 type ClassPaper {
   id: ID!
   title: String
@@ -735,9 +1176,34 @@ type Session {
   updatedAt: Time
 }
 
+type DeviceSession {
+  id: ID!
+  userAgent: String
+  ipAddress: String
+  deliveredAt: Time
+  origin: SessionOriginEnum
+  current: Boolean!
+}
+
+# With two-factor authentication login only returns twoFactorChallenge,
+# jwt and refreshToken are empty until verifyTwoFactor answers the challenge.
+# In cookie session mode they are always empty, the cookies carry them
 type Token {
   jwt: String!
   refreshToken: String!
+  twoFactorChallenge: String
+}
+
+# content is the base64 of the archive
+type DataExport {
+  filename: String!
+  contentType: String!
+  content: String!
+}
+
+type TwoFactorEnrollment {
+  otpauthUri: String!
+  secret: String!
 }
 
 type User {
@@ -745,11 +1211,31 @@ type User {
   username: String
   fullname: String
   email: String
+  emailVerified: Boolean!
+  # null unless the user is the caller or the caller may read users
+  twoFactorEnabled: Boolean
   isTeacher: Boolean
+  # null unless the user is the caller or the caller may read users
+  roles: [Role!]
   createdAt: Time
   updatedAt: Time
 }
 
+# The authenticated user with what only they can see
+type Me {
+  user: User!
+  purchasedCourses: [PurchasedCourse!]!
+  deviceSessions: [DeviceSession!]!
+}
+
+# viewedSessions counts the ready sessions opened through sessionCourse
+type PurchasedCourse {
+  refresherCourse: RefresherCourse!
+  purchasedAt: Time
+  viewedSessions: Int!
+  totalSessions: Int!
+}
+
 type Video {
   id: ID!
   path: String
@@ -758,24 +1244,51 @@ type Video {
   updatedAt: Time
 }
 
+# userId and byUserId default to the authenticated user, naming another
+# user requires the USER_READ permission
 type Query {
+  # bearer mode only, cookie sessions log in with the login mutation so GET
+  # requests, which skip the CSRF check, cannot set session cookies
   login(input: LoginInput!): Token!
+  me: Me! @auth
   refresherCourses(input: RefresherCourseInput!): [RefresherCourse]!
   refresherCourse(refresherCourseId: Int!): RefresherCourseResponse!
-  playerCheckUser: Boolean!
-  profile(userId: Int!): User!
-  sessionCourse(input: SessionInput!): SessionResponse!
-  authTeacher(userId: Int!): Boolean!
+  playerCheckUser: Boolean! @auth
+  profile(userId: Int): User! @auth
+  sessionCourse(input: SessionInput!): SessionResponse! @auth
+  authTeacher(userId: Int!): Boolean! @hasPermission(permission: COURSE_PUBLISH)
   subjectsEnum: [String!]!
   totalHoursCourses: String!
+  deviceSessions: [DeviceSession!]! @auth
 }
 
 type Mutation {
+  # the same as the login query, which cookie sessions cannot use
+  login(input: LoginInput!): Token!
   createUser(input: NewUserInput!): Boolean!
-  refreshToken(refreshToken: String!): Token!
-  updateUser(input: UpdateUserInput!): User!
-  purchaseRefresherCourse(input: PurchaseRefresherCourseInput!): Boolean!
-  createRefresherCourse(input: NewSessionInput!): Boolean!
+  # refreshToken defaults to the cookie in cookie session mode
+  refreshToken(refreshToken: String): Token!
+  updateUser(input: UpdateUserInput!): User! @auth
+  purchaseRefresherCourse(input: PurchaseRefresherCourseInput!): Boolean! @auth
+  createRefresherCourse(input: NewSessionInput!): Boolean! @hasPermission(permission: COURSE_PUBLISH)
+  revokeDeviceSession(deviceSessionId: Int!): Boolean! @auth
+  logoutEverywhere: Boolean! @auth
+  logout: Boolean! @auth
+  requestPasswordReset(email: String!): Boolean!
+  resetPassword(input: ResetPasswordInput!): Boolean!
+  verifyEmail(token: String!): Boolean!
+  resendVerificationEmail: Boolean! @auth
+  # both ask the password again, a stolen JWT cannot enrol its own authenticator
+  enableTwoFactor(input: EnableTwoFactorInput!): TwoFactorEnrollment! @auth
+  confirmTwoFactor(input: ConfirmTwoFactorInput!): [String!]! @auth
+  disableTwoFactor(code: String!): Boolean! @auth
+  verifyTwoFactor(input: VerifyTwoFactorInput!): Token!
+  grantRole(userId: Int!, role: Role!): Boolean! @hasPermission(permission: ROLE_MANAGE)
+  revokeRole(userId: Int!, role: Role!): Boolean! @hasPermission(permission: ROLE_MANAGE)
+  # ZIP of JSON files holding everything stored about the caller
+  exportMyData: DataExport! @auth
+  # erases the account, the payments stay anonymised for accounting
+  deleteAccount(input: DeleteAccountInput!): Boolean! @auth
 }
 
 input LoginInput {
@@ -784,7 +1297,7 @@ input LoginInput {
 }
 
 input RefresherCourseInput {
-  byUserId: Int
+  byUserId: Int @auth
   bySubject: SubjectEnum
 }
 
@@ -794,7 +1307,7 @@ type RefresherCourseResponse {
 }
 
 input SessionInput {
-  userId: Int!
+  userId: Int
   refresherCourseId: Int!
   sessionId: Int!
 }
@@ -820,6 +1333,31 @@ input UpdateUserInput {
   password: String!
 }
 
+input ResetPasswordInput {
+  token: String!
+  password: String!
+}
+
+# twoFactorCode is required once two-factor authentication is enabled
+input DeleteAccountInput {
+  password: String!
+  twoFactorCode: String
+}
+
+input EnableTwoFactorInput {
+  password: String!
+}
+
+input ConfirmTwoFactorInput {
+  password: String!
+  code: String!
+}
+
+input VerifyTwoFactorInput {
+  challenge: String!
+  code: String!
+}
+
 input PurchaseRefresherCourseInput {
   refresherCourseId: Int!
   paypalOrderId: String!
@@ -856,7 +1394,27 @@ enum SectionEnum {
   SCIENTIFIC
 }
 
-enum SubjectEnum {
+# stored in lower case in roles.name and the JWT claims
+enum Role {
+  STUDENT
+  TEACHER
+  ADMIN
+  SUPPORT
+}
+
+# stored in lower case in permissions.name and the JWT claims
+enum Permission {
+  COURSE_PUBLISH
+  USER_READ
+  ROLE_MANAGE
+}
+
+enum SessionOriginEnum {
+  LOGIN
+  REFRESH
+}
+
+enum SubjectEnum {
   ECONOMICS
   FRENCH
   MATHETIMATICS
@@ -869,12 +1427,54 @@ var parsedSchema = gqlparser.MustLoadSchema(sources...)
 
 // region    ***************************** args.gotpl *****************************
 
+func (ec *executionContext) dir_hasPermission_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 model.Permission
+	if tmp, ok := rawArgs["permission"]; ok {
+		arg0, err = ec.unmarshalNPermission2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐPermission(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["permission"] = arg0
+	return args, nil
+}
+
+func (ec *executionContext) dir_hasRole_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 []model.Role
+	if tmp, ok := rawArgs["roles"]; ok {
+		arg0, err = ec.unmarshalNRole2ᚕgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRoleᚄ(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["roles"] = arg0
+	return args, nil
+}
+
+func (ec *executionContext) field_Mutation_confirmTwoFactor_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 model.ConfirmTwoFactorInput
+	if tmp, ok := rawArgs["input"]; ok {
+		arg0, err = ec.unmarshalNConfirmTwoFactorInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐConfirmTwoFactorInput(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["input"] = arg0
+	return args, nil
+}
+
 func (ec *executionContext) field_Mutation_createRefresherCourse_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
 	var err error
 	args := map[string]interface{}{}
 	var arg0 model.NewSessionInput
 	if tmp, ok := rawArgs["input"]; ok {
-		arg0, err = ec.unmarshalNNewSessionInput2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐNewSessionInput(ctx, tmp)
+		arg0, err = ec.unmarshalNNewSessionInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐNewSessionInput(ctx, tmp)
 		if err != nil {
 			return nil, err
 		}
@@ -888,7 +1488,85 @@ func (ec *executionContext) field_Mutation_createUser_args(ctx context.Context,
 	args := map[string]interface{}{}
 	var arg0 model.NewUserInput
 	if tmp, ok := rawArgs["input"]; ok {
-		arg0, err = ec.unmarshalNNewUserInput2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐNewUserInput(ctx, tmp)
+		arg0, err = ec.unmarshalNNewUserInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐNewUserInput(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["input"] = arg0
+	return args, nil
+}
+
+func (ec *executionContext) field_Mutation_deleteAccount_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 model.DeleteAccountInput
+	if tmp, ok := rawArgs["input"]; ok {
+		arg0, err = ec.unmarshalNDeleteAccountInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐDeleteAccountInput(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["input"] = arg0
+	return args, nil
+}
+
+func (ec *executionContext) field_Mutation_disableTwoFactor_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["code"]; ok {
+		arg0, err = ec.unmarshalNString2string(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["code"] = arg0
+	return args, nil
+}
+
+func (ec *executionContext) field_Mutation_enableTwoFactor_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 model.EnableTwoFactorInput
+	if tmp, ok := rawArgs["input"]; ok {
+		arg0, err = ec.unmarshalNEnableTwoFactorInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐEnableTwoFactorInput(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["input"] = arg0
+	return args, nil
+}
+
+func (ec *executionContext) field_Mutation_grantRole_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 int
+	if tmp, ok := rawArgs["userId"]; ok {
+		arg0, err = ec.unmarshalNInt2int(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["userId"] = arg0
+	var arg1 model.Role
+	if tmp, ok := rawArgs["role"]; ok {
+		arg1, err = ec.unmarshalNRole2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRole(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["role"] = arg1
+	return args, nil
+}
+
+func (ec *executionContext) field_Mutation_login_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 model.LoginInput
+	if tmp, ok := rawArgs["input"]; ok {
+		arg0, err = ec.unmarshalNLoginInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐLoginInput(ctx, tmp)
 		if err != nil {
 			return nil, err
 		}
@@ -902,7 +1580,7 @@ func (ec *executionContext) field_Mutation_purchaseRefresherCourse_args(ctx cont
 	args := map[string]interface{}{}
 	var arg0 model.PurchaseRefresherCourseInput
 	if tmp, ok := rawArgs["input"]; ok {
-		arg0, err = ec.unmarshalNPurchaseRefresherCourseInput2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐPurchaseRefresherCourseInput(ctx, tmp)
+		arg0, err = ec.unmarshalNPurchaseRefresherCourseInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐPurchaseRefresherCourseInput(ctx, tmp)
 		if err != nil {
 			return nil, err
 		}
@@ -914,9 +1592,9 @@ func (ec *executionContext) field_Mutation_purchaseRefresherCourse_args(ctx cont
 func (ec *executionContext) field_Mutation_refreshToken_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
 	var err error
 	args := map[string]interface{}{}
-	var arg0 string
+	var arg0 *string
 	if tmp, ok := rawArgs["refreshToken"]; ok {
-		arg0, err = ec.unmarshalNString2string(ctx, tmp)
+		arg0, err = ec.unmarshalOString2ᚖstring(ctx, tmp)
 		if err != nil {
 			return nil, err
 		}
@@ -925,12 +1603,104 @@ func (ec *executionContext) field_Mutation_refreshToken_args(ctx context.Context
 	return args, nil
 }
 
+func (ec *executionContext) field_Mutation_requestPasswordReset_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["email"]; ok {
+		arg0, err = ec.unmarshalNString2string(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["email"] = arg0
+	return args, nil
+}
+
+func (ec *executionContext) field_Mutation_resetPassword_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 model.ResetPasswordInput
+	if tmp, ok := rawArgs["input"]; ok {
+		arg0, err = ec.unmarshalNResetPasswordInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐResetPasswordInput(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["input"] = arg0
+	return args, nil
+}
+
+func (ec *executionContext) field_Mutation_revokeDeviceSession_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 int
+	if tmp, ok := rawArgs["deviceSessionId"]; ok {
+		arg0, err = ec.unmarshalNInt2int(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["deviceSessionId"] = arg0
+	return args, nil
+}
+
+func (ec *executionContext) field_Mutation_revokeRole_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 int
+	if tmp, ok := rawArgs["userId"]; ok {
+		arg0, err = ec.unmarshalNInt2int(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["userId"] = arg0
+	var arg1 model.Role
+	if tmp, ok := rawArgs["role"]; ok {
+		arg1, err = ec.unmarshalNRole2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRole(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["role"] = arg1
+	return args, nil
+}
+
 func (ec *executionContext) field_Mutation_updateUser_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
 	var err error
 	args := map[string]interface{}{}
 	var arg0 model.UpdateUserInput
 	if tmp, ok := rawArgs["input"]; ok {
-		arg0, err = ec.unmarshalNUpdateUserInput2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐUpdateUserInput(ctx, tmp)
+		arg0, err = ec.unmarshalNUpdateUserInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐUpdateUserInput(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["input"] = arg0
+	return args, nil
+}
+
+func (ec *executionContext) field_Mutation_verifyEmail_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["token"]; ok {
+		arg0, err = ec.unmarshalNString2string(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["token"] = arg0
+	return args, nil
+}
+
+func (ec *executionContext) field_Mutation_verifyTwoFactor_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 model.VerifyTwoFactorInput
+	if tmp, ok := rawArgs["input"]; ok {
+		arg0, err = ec.unmarshalNVerifyTwoFactorInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐVerifyTwoFactorInput(ctx, tmp)
 		if err != nil {
 			return nil, err
 		}
@@ -972,7 +1742,7 @@ func (ec *executionContext) field_Query_login_args(ctx context.Context, rawArgs
 	args := map[string]interface{}{}
 	var arg0 model.LoginInput
 	if tmp, ok := rawArgs["input"]; ok {
-		arg0, err = ec.unmarshalNLoginInput2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐLoginInput(ctx, tmp)
+		arg0, err = ec.unmarshalNLoginInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐLoginInput(ctx, tmp)
 		if err != nil {
 			return nil, err
 		}
@@ -984,9 +1754,9 @@ func (ec *executionContext) field_Query_login_args(ctx context.Context, rawArgs
 func (ec *executionContext) field_Query_profile_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
 	var err error
 	args := map[string]interface{}{}
-	var arg0 int
+	var arg0 *int
 	if tmp, ok := rawArgs["userId"]; ok {
-		arg0, err = ec.unmarshalNInt2int(ctx, tmp)
+		arg0, err = ec.unmarshalOInt2ᚖint(ctx, tmp)
 		if err != nil {
 			return nil, err
 		}
@@ -1014,7 +1784,7 @@ func (ec *executionContext) field_Query_refresherCourses_args(ctx context.Contex
 	args := map[string]interface{}{}
 	var arg0 model.RefresherCourseInput
 	if tmp, ok := rawArgs["input"]; ok {
-		arg0, err = ec.unmarshalNRefresherCourseInput2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐRefresherCourseInput(ctx, tmp)
+		arg0, err = ec.unmarshalNRefresherCourseInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRefresherCourseInput(ctx, tmp)
 		if err != nil {
 			return nil, err
 		}
@@ -1028,7 +1798,7 @@ func (ec *executionContext) field_Query_sessionCourse_args(ctx context.Context,
 	args := map[string]interface{}{}
 	var arg0 model.SessionInput
 	if tmp, ok := rawArgs["input"]; ok {
-		arg0, err = ec.unmarshalNSessionInput2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSessionInput(ctx, tmp)
+		arg0, err = ec.unmarshalNSessionInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSessionInput(ctx, tmp)
 		if err != nil {
 			return nil, err
 		}
@@ -1231,7 +2001,7 @@ func (ec *executionContext) _ClassPaper_updatedAt(ctx context.Context, field gra
 	return ec.marshalOTime2timeᚐTime(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _Mutation_createUser(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+func (ec *executionContext) _DataExport_filename(ctx context.Context, field graphql.CollectedField, obj *model.DataExport) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -1239,23 +2009,16 @@ func (ec *executionContext) _Mutation_createUser(ctx context.Context, field grap
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "Mutation",
+		Object:   "DataExport",
 		Field:    field,
 		Args:     nil,
-		IsMethod: true,
+		IsMethod: false,
 	}
 
 	ctx = graphql.WithFieldContext(ctx, fc)
-	rawArgs := field.ArgumentMap(ec.Variables)
-	args, err := ec.field_Mutation_createUser_args(ctx, rawArgs)
-	if err != nil {
-		ec.Error(ctx, err)
-		return graphql.Null
-	}
-	fc.Args = args
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Mutation().CreateUser(rctx, args["input"].(model.NewUserInput))
+		return obj.Filename, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -1267,12 +2030,12 @@ func (ec *executionContext) _Mutation_createUser(ctx context.Context, field grap
 		}
 		return graphql.Null
 	}
-	res := resTmp.(bool)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _Mutation_refreshToken(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+func (ec *executionContext) _DataExport_contentType(ctx context.Context, field graphql.CollectedField, obj *model.DataExport) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -1280,23 +2043,16 @@ func (ec *executionContext) _Mutation_refreshToken(ctx context.Context, field gr
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "Mutation",
+		Object:   "DataExport",
 		Field:    field,
 		Args:     nil,
-		IsMethod: true,
+		IsMethod: false,
 	}
 
 	ctx = graphql.WithFieldContext(ctx, fc)
-	rawArgs := field.ArgumentMap(ec.Variables)
-	args, err := ec.field_Mutation_refreshToken_args(ctx, rawArgs)
-	if err != nil {
-		ec.Error(ctx, err)
-		return graphql.Null
-	}
-	fc.Args = args
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Mutation().RefreshToken(rctx, args["refreshToken"].(string))
+		return obj.ContentType, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -1308,12 +2064,12 @@ func (ec *executionContext) _Mutation_refreshToken(ctx context.Context, field gr
 		}
 		return graphql.Null
 	}
-	res := resTmp.(*model.Token)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalNToken2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐToken(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _Mutation_updateUser(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+func (ec *executionContext) _DataExport_content(ctx context.Context, field graphql.CollectedField, obj *model.DataExport) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -1321,23 +2077,16 @@ func (ec *executionContext) _Mutation_updateUser(ctx context.Context, field grap
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "Mutation",
+		Object:   "DataExport",
 		Field:    field,
 		Args:     nil,
-		IsMethod: true,
+		IsMethod: false,
 	}
 
 	ctx = graphql.WithFieldContext(ctx, fc)
-	rawArgs := field.ArgumentMap(ec.Variables)
-	args, err := ec.field_Mutation_updateUser_args(ctx, rawArgs)
-	if err != nil {
-		ec.Error(ctx, err)
-		return graphql.Null
-	}
-	fc.Args = args
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Mutation().UpdateUser(rctx, args["input"].(model.UpdateUserInput))
+		return obj.Content, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -1349,12 +2098,12 @@ func (ec *executionContext) _Mutation_updateUser(ctx context.Context, field grap
 		}
 		return graphql.Null
 	}
-	res := resTmp.(*model.User)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalNUser2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐUser(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _Mutation_purchaseRefresherCourse(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+func (ec *executionContext) _DeviceSession_id(ctx context.Context, field graphql.CollectedField, obj *model.DeviceSession) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -1362,23 +2111,16 @@ func (ec *executionContext) _Mutation_purchaseRefresherCourse(ctx context.Contex
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "Mutation",
+		Object:   "DeviceSession",
 		Field:    field,
 		Args:     nil,
-		IsMethod: true,
+		IsMethod: false,
 	}
 
 	ctx = graphql.WithFieldContext(ctx, fc)
-	rawArgs := field.ArgumentMap(ec.Variables)
-	args, err := ec.field_Mutation_purchaseRefresherCourse_args(ctx, rawArgs)
-	if err != nil {
-		ec.Error(ctx, err)
-		return graphql.Null
-	}
-	fc.Args = args
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Mutation().PurchaseRefresherCourse(rctx, args["input"].(model.PurchaseRefresherCourseInput))
+		return obj.ID, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -1390,12 +2132,12 @@ func (ec *executionContext) _Mutation_purchaseRefresherCourse(ctx context.Contex
 		}
 		return graphql.Null
 	}
-	res := resTmp.(bool)
+	res := resTmp.(int)
 	fc.Result = res
-	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+	return ec.marshalNID2int(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _Mutation_createRefresherCourse(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+func (ec *executionContext) _DeviceSession_userAgent(ctx context.Context, field graphql.CollectedField, obj *model.DeviceSession) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -1403,40 +2145,30 @@ func (ec *executionContext) _Mutation_createRefresherCourse(ctx context.Context,
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "Mutation",
+		Object:   "DeviceSession",
 		Field:    field,
 		Args:     nil,
-		IsMethod: true,
+		IsMethod: false,
 	}
 
 	ctx = graphql.WithFieldContext(ctx, fc)
-	rawArgs := field.ArgumentMap(ec.Variables)
-	args, err := ec.field_Mutation_createRefresherCourse_args(ctx, rawArgs)
-	if err != nil {
-		ec.Error(ctx, err)
-		return graphql.Null
-	}
-	fc.Args = args
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Mutation().CreateRefresherCourse(rctx, args["input"].(model.NewSessionInput))
+		return obj.UserAgent, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
-			ec.Errorf(ctx, "must not be null")
-		}
 		return graphql.Null
 	}
-	res := resTmp.(bool)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+	return ec.marshalOString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _Query_login(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+func (ec *executionContext) _DeviceSession_ipAddress(ctx context.Context, field graphql.CollectedField, obj *model.DeviceSession) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -1444,10 +2176,1497 @@ func (ec *executionContext) _Query_login(ctx context.Context, field graphql.Coll
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "Query",
+		Object:   "DeviceSession",
 		Field:    field,
 		Args:     nil,
-		IsMethod: true,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.IPAddress, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalOString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _DeviceSession_deliveredAt(ctx context.Context, field graphql.CollectedField, obj *model.DeviceSession) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "DeviceSession",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.DeliveredAt, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(time.Time)
+	fc.Result = res
+	return ec.marshalOTime2timeᚐTime(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _DeviceSession_origin(ctx context.Context, field graphql.CollectedField, obj *model.DeviceSession) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "DeviceSession",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Origin, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(model.SessionOriginEnum)
+	fc.Result = res
+	return ec.marshalOSessionOriginEnum2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSessionOriginEnum(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _DeviceSession_current(ctx context.Context, field graphql.CollectedField, obj *model.DeviceSession) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "DeviceSession",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Current, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(bool)
+	fc.Result = res
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Me_user(ctx context.Context, field graphql.CollectedField, obj *model.Me) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Me",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.User, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*model.User)
+	fc.Result = res
+	return ec.marshalNUser2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐUser(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Me_purchasedCourses(ctx context.Context, field graphql.CollectedField, obj *model.Me) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Me",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Me().PurchasedCourses(rctx, obj)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.([]*model.PurchasedCourse)
+	fc.Result = res
+	return ec.marshalNPurchasedCourse2ᚕᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐPurchasedCourseᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Me_deviceSessions(ctx context.Context, field graphql.CollectedField, obj *model.Me) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Me",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Me().DeviceSessions(rctx, obj)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.([]*model.DeviceSession)
+	fc.Result = res
+	return ec.marshalNDeviceSession2ᚕᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐDeviceSessionᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_login(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_login_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().Login(rctx, args["input"].(model.LoginInput))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*model.Token)
+	fc.Result = res
+	return ec.marshalNToken2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐToken(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_createUser(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_createUser_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().CreateUser(rctx, args["input"].(model.NewUserInput))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(bool)
+	fc.Result = res
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_refreshToken(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_refreshToken_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().RefreshToken(rctx, args["refreshToken"].(*string))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*model.Token)
+	fc.Result = res
+	return ec.marshalNToken2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐToken(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_updateUser(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_updateUser_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		directive0 := func(rctx context.Context) (interface{}, error) {
+			ctx = rctx // use context from middleware stack in children
+			return ec.resolvers.Mutation().UpdateUser(rctx, args["input"].(model.UpdateUserInput))
+		}
+		directive1 := func(ctx context.Context) (interface{}, error) {
+			if ec.directives.Auth == nil {
+				return nil, errors.New("directive auth is not implemented")
+			}
+			return ec.directives.Auth(ctx, nil, directive0)
+		}
+
+		tmp, err := directive1(rctx)
+		if err != nil {
+			return nil, err
+		}
+		if tmp == nil {
+			return nil, nil
+		}
+		if data, ok := tmp.(*model.User); ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf(`unexpected type %T from directive, should be *github.com/juleur/becrpe/graph/model.User`, tmp)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*model.User)
+	fc.Result = res
+	return ec.marshalNUser2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐUser(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_purchaseRefresherCourse(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_purchaseRefresherCourse_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		directive0 := func(rctx context.Context) (interface{}, error) {
+			ctx = rctx // use context from middleware stack in children
+			return ec.resolvers.Mutation().PurchaseRefresherCourse(rctx, args["input"].(model.PurchaseRefresherCourseInput))
+		}
+		directive1 := func(ctx context.Context) (interface{}, error) {
+			if ec.directives.Auth == nil {
+				return nil, errors.New("directive auth is not implemented")
+			}
+			return ec.directives.Auth(ctx, nil, directive0)
+		}
+
+		tmp, err := directive1(rctx)
+		if err != nil {
+			return nil, err
+		}
+		if tmp == nil {
+			return nil, nil
+		}
+		if data, ok := tmp.(bool); ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf(`unexpected type %T from directive, should be bool`, tmp)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(bool)
+	fc.Result = res
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_createRefresherCourse(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_createRefresherCourse_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		directive0 := func(rctx context.Context) (interface{}, error) {
+			ctx = rctx // use context from middleware stack in children
+			return ec.resolvers.Mutation().CreateRefresherCourse(rctx, args["input"].(model.NewSessionInput))
+		}
+		directive1 := func(ctx context.Context) (interface{}, error) {
+			permission, err := ec.unmarshalNPermission2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐPermission(ctx, "COURSE_PUBLISH")
+			if err != nil {
+				return nil, err
+			}
+			if ec.directives.HasPermission == nil {
+				return nil, errors.New("directive hasPermission is not implemented")
+			}
+			return ec.directives.HasPermission(ctx, nil, directive0, permission)
+		}
+
+		tmp, err := directive1(rctx)
+		if err != nil {
+			return nil, err
+		}
+		if tmp == nil {
+			return nil, nil
+		}
+		if data, ok := tmp.(bool); ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf(`unexpected type %T from directive, should be bool`, tmp)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(bool)
+	fc.Result = res
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_revokeDeviceSession(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_revokeDeviceSession_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		directive0 := func(rctx context.Context) (interface{}, error) {
+			ctx = rctx // use context from middleware stack in children
+			return ec.resolvers.Mutation().RevokeDeviceSession(rctx, args["deviceSessionId"].(int))
+		}
+		directive1 := func(ctx context.Context) (interface{}, error) {
+			if ec.directives.Auth == nil {
+				return nil, errors.New("directive auth is not implemented")
+			}
+			return ec.directives.Auth(ctx, nil, directive0)
+		}
+
+		tmp, err := directive1(rctx)
+		if err != nil {
+			return nil, err
+		}
+		if tmp == nil {
+			return nil, nil
+		}
+		if data, ok := tmp.(bool); ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf(`unexpected type %T from directive, should be bool`, tmp)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(bool)
+	fc.Result = res
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_logoutEverywhere(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		directive0 := func(rctx context.Context) (interface{}, error) {
+			ctx = rctx // use context from middleware stack in children
+			return ec.resolvers.Mutation().LogoutEverywhere(rctx)
+		}
+		directive1 := func(ctx context.Context) (interface{}, error) {
+			if ec.directives.Auth == nil {
+				return nil, errors.New("directive auth is not implemented")
+			}
+			return ec.directives.Auth(ctx, nil, directive0)
+		}
+
+		tmp, err := directive1(rctx)
+		if err != nil {
+			return nil, err
+		}
+		if tmp == nil {
+			return nil, nil
+		}
+		if data, ok := tmp.(bool); ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf(`unexpected type %T from directive, should be bool`, tmp)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(bool)
+	fc.Result = res
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_logout(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		directive0 := func(rctx context.Context) (interface{}, error) {
+			ctx = rctx // use context from middleware stack in children
+			return ec.resolvers.Mutation().Logout(rctx)
+		}
+		directive1 := func(ctx context.Context) (interface{}, error) {
+			if ec.directives.Auth == nil {
+				return nil, errors.New("directive auth is not implemented")
+			}
+			return ec.directives.Auth(ctx, nil, directive0)
+		}
+
+		tmp, err := directive1(rctx)
+		if err != nil {
+			return nil, err
+		}
+		if tmp == nil {
+			return nil, nil
+		}
+		if data, ok := tmp.(bool); ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf(`unexpected type %T from directive, should be bool`, tmp)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(bool)
+	fc.Result = res
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_requestPasswordReset(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_requestPasswordReset_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().RequestPasswordReset(rctx, args["email"].(string))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(bool)
+	fc.Result = res
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_resetPassword(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_resetPassword_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().ResetPassword(rctx, args["input"].(model.ResetPasswordInput))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(bool)
+	fc.Result = res
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_verifyEmail(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_verifyEmail_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().VerifyEmail(rctx, args["token"].(string))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(bool)
+	fc.Result = res
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_resendVerificationEmail(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		directive0 := func(rctx context.Context) (interface{}, error) {
+			ctx = rctx // use context from middleware stack in children
+			return ec.resolvers.Mutation().ResendVerificationEmail(rctx)
+		}
+		directive1 := func(ctx context.Context) (interface{}, error) {
+			if ec.directives.Auth == nil {
+				return nil, errors.New("directive auth is not implemented")
+			}
+			return ec.directives.Auth(ctx, nil, directive0)
+		}
+
+		tmp, err := directive1(rctx)
+		if err != nil {
+			return nil, err
+		}
+		if tmp == nil {
+			return nil, nil
+		}
+		if data, ok := tmp.(bool); ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf(`unexpected type %T from directive, should be bool`, tmp)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(bool)
+	fc.Result = res
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_enableTwoFactor(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_enableTwoFactor_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		directive0 := func(rctx context.Context) (interface{}, error) {
+			ctx = rctx // use context from middleware stack in children
+			return ec.resolvers.Mutation().EnableTwoFactor(rctx, args["input"].(model.EnableTwoFactorInput))
+		}
+		directive1 := func(ctx context.Context) (interface{}, error) {
+			if ec.directives.Auth == nil {
+				return nil, errors.New("directive auth is not implemented")
+			}
+			return ec.directives.Auth(ctx, nil, directive0)
+		}
+
+		tmp, err := directive1(rctx)
+		if err != nil {
+			return nil, err
+		}
+		if tmp == nil {
+			return nil, nil
+		}
+		if data, ok := tmp.(*model.TwoFactorEnrollment); ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf(`unexpected type %T from directive, should be *github.com/juleur/becrpe/graph/model.TwoFactorEnrollment`, tmp)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*model.TwoFactorEnrollment)
+	fc.Result = res
+	return ec.marshalNTwoFactorEnrollment2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐTwoFactorEnrollment(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_confirmTwoFactor(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_confirmTwoFactor_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		directive0 := func(rctx context.Context) (interface{}, error) {
+			ctx = rctx // use context from middleware stack in children
+			return ec.resolvers.Mutation().ConfirmTwoFactor(rctx, args["input"].(model.ConfirmTwoFactorInput))
+		}
+		directive1 := func(ctx context.Context) (interface{}, error) {
+			if ec.directives.Auth == nil {
+				return nil, errors.New("directive auth is not implemented")
+			}
+			return ec.directives.Auth(ctx, nil, directive0)
+		}
+
+		tmp, err := directive1(rctx)
+		if err != nil {
+			return nil, err
+		}
+		if tmp == nil {
+			return nil, nil
+		}
+		if data, ok := tmp.([]string); ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf(`unexpected type %T from directive, should be []string`, tmp)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.([]string)
+	fc.Result = res
+	return ec.marshalNString2ᚕstringᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_disableTwoFactor(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_disableTwoFactor_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		directive0 := func(rctx context.Context) (interface{}, error) {
+			ctx = rctx // use context from middleware stack in children
+			return ec.resolvers.Mutation().DisableTwoFactor(rctx, args["code"].(string))
+		}
+		directive1 := func(ctx context.Context) (interface{}, error) {
+			if ec.directives.Auth == nil {
+				return nil, errors.New("directive auth is not implemented")
+			}
+			return ec.directives.Auth(ctx, nil, directive0)
+		}
+
+		tmp, err := directive1(rctx)
+		if err != nil {
+			return nil, err
+		}
+		if tmp == nil {
+			return nil, nil
+		}
+		if data, ok := tmp.(bool); ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf(`unexpected type %T from directive, should be bool`, tmp)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(bool)
+	fc.Result = res
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_verifyTwoFactor(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_verifyTwoFactor_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Mutation().VerifyTwoFactor(rctx, args["input"].(model.VerifyTwoFactorInput))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*model.Token)
+	fc.Result = res
+	return ec.marshalNToken2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐToken(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_grantRole(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_grantRole_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		directive0 := func(rctx context.Context) (interface{}, error) {
+			ctx = rctx // use context from middleware stack in children
+			return ec.resolvers.Mutation().GrantRole(rctx, args["userId"].(int), args["role"].(model.Role))
+		}
+		directive1 := func(ctx context.Context) (interface{}, error) {
+			permission, err := ec.unmarshalNPermission2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐPermission(ctx, "ROLE_MANAGE")
+			if err != nil {
+				return nil, err
+			}
+			if ec.directives.HasPermission == nil {
+				return nil, errors.New("directive hasPermission is not implemented")
+			}
+			return ec.directives.HasPermission(ctx, nil, directive0, permission)
+		}
+
+		tmp, err := directive1(rctx)
+		if err != nil {
+			return nil, err
+		}
+		if tmp == nil {
+			return nil, nil
+		}
+		if data, ok := tmp.(bool); ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf(`unexpected type %T from directive, should be bool`, tmp)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(bool)
+	fc.Result = res
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_revokeRole(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_revokeRole_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		directive0 := func(rctx context.Context) (interface{}, error) {
+			ctx = rctx // use context from middleware stack in children
+			return ec.resolvers.Mutation().RevokeRole(rctx, args["userId"].(int), args["role"].(model.Role))
+		}
+		directive1 := func(ctx context.Context) (interface{}, error) {
+			permission, err := ec.unmarshalNPermission2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐPermission(ctx, "ROLE_MANAGE")
+			if err != nil {
+				return nil, err
+			}
+			if ec.directives.HasPermission == nil {
+				return nil, errors.New("directive hasPermission is not implemented")
+			}
+			return ec.directives.HasPermission(ctx, nil, directive0, permission)
+		}
+
+		tmp, err := directive1(rctx)
+		if err != nil {
+			return nil, err
+		}
+		if tmp == nil {
+			return nil, nil
+		}
+		if data, ok := tmp.(bool); ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf(`unexpected type %T from directive, should be bool`, tmp)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(bool)
+	fc.Result = res
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_exportMyData(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		directive0 := func(rctx context.Context) (interface{}, error) {
+			ctx = rctx // use context from middleware stack in children
+			return ec.resolvers.Mutation().ExportMyData(rctx)
+		}
+		directive1 := func(ctx context.Context) (interface{}, error) {
+			if ec.directives.Auth == nil {
+				return nil, errors.New("directive auth is not implemented")
+			}
+			return ec.directives.Auth(ctx, nil, directive0)
+		}
+
+		tmp, err := directive1(rctx)
+		if err != nil {
+			return nil, err
+		}
+		if tmp == nil {
+			return nil, nil
+		}
+		if data, ok := tmp.(*model.DataExport); ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf(`unexpected type %T from directive, should be *github.com/juleur/becrpe/graph/model.DataExport`, tmp)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*model.DataExport)
+	fc.Result = res
+	return ec.marshalNDataExport2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐDataExport(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Mutation_deleteAccount(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Mutation",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Mutation_deleteAccount_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		directive0 := func(rctx context.Context) (interface{}, error) {
+			ctx = rctx // use context from middleware stack in children
+			return ec.resolvers.Mutation().DeleteAccount(rctx, args["input"].(model.DeleteAccountInput))
+		}
+		directive1 := func(ctx context.Context) (interface{}, error) {
+			if ec.directives.Auth == nil {
+				return nil, errors.New("directive auth is not implemented")
+			}
+			return ec.directives.Auth(ctx, nil, directive0)
+		}
+
+		tmp, err := directive1(rctx)
+		if err != nil {
+			return nil, err
+		}
+		if tmp == nil {
+			return nil, nil
+		}
+		if data, ok := tmp.(bool); ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf(`unexpected type %T from directive, should be bool`, tmp)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(bool)
+	fc.Result = res
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _PurchasedCourse_refresherCourse(ctx context.Context, field graphql.CollectedField, obj *model.PurchasedCourse) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "PurchasedCourse",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.RefresherCourse, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*model.RefresherCourse)
+	fc.Result = res
+	return ec.marshalNRefresherCourse2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRefresherCourse(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _PurchasedCourse_purchasedAt(ctx context.Context, field graphql.CollectedField, obj *model.PurchasedCourse) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "PurchasedCourse",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.PurchasedAt, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*time.Time)
+	fc.Result = res
+	return ec.marshalOTime2ᚖtimeᚐTime(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _PurchasedCourse_viewedSessions(ctx context.Context, field graphql.CollectedField, obj *model.PurchasedCourse) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "PurchasedCourse",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ViewedSessions, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	fc.Result = res
+	return ec.marshalNInt2int(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _PurchasedCourse_totalSessions(ctx context.Context, field graphql.CollectedField, obj *model.PurchasedCourse) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "PurchasedCourse",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.TotalSessions, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	fc.Result = res
+	return ec.marshalNInt2int(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Query_login(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Query",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
 	}
 
 	ctx = graphql.WithFieldContext(ctx, fc)
@@ -1474,7 +3693,61 @@ func (ec *executionContext) _Query_login(ctx context.Context, field graphql.Coll
 	}
 	res := resTmp.(*model.Token)
 	fc.Result = res
-	return ec.marshalNToken2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐToken(ctx, field.Selections, res)
+	return ec.marshalNToken2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐToken(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Query_me(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Query",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		directive0 := func(rctx context.Context) (interface{}, error) {
+			ctx = rctx // use context from middleware stack in children
+			return ec.resolvers.Query().Me(rctx)
+		}
+		directive1 := func(ctx context.Context) (interface{}, error) {
+			if ec.directives.Auth == nil {
+				return nil, errors.New("directive auth is not implemented")
+			}
+			return ec.directives.Auth(ctx, nil, directive0)
+		}
+
+		tmp, err := directive1(rctx)
+		if err != nil {
+			return nil, err
+		}
+		if tmp == nil {
+			return nil, nil
+		}
+		if data, ok := tmp.(*model.Me); ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf(`unexpected type %T from directive, should be *github.com/juleur/becrpe/graph/model.Me`, tmp)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*model.Me)
+	fc.Result = res
+	return ec.marshalNMe2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐMe(ctx, field.Selections, res)
 }
 
 func (ec *executionContext) _Query_refresherCourses(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
@@ -1515,7 +3788,7 @@ func (ec *executionContext) _Query_refresherCourses(ctx context.Context, field g
 	}
 	res := resTmp.([]*model.RefresherCourse)
 	fc.Result = res
-	return ec.marshalNRefresherCourse2ᚕᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐRefresherCourse(ctx, field.Selections, res)
+	return ec.marshalNRefresherCourse2ᚕᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRefresherCourse(ctx, field.Selections, res)
 }
 
 func (ec *executionContext) _Query_refresherCourse(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
@@ -1556,7 +3829,7 @@ func (ec *executionContext) _Query_refresherCourse(ctx context.Context, field gr
 	}
 	res := resTmp.(*model.RefresherCourseResponse)
 	fc.Result = res
-	return ec.marshalNRefresherCourseResponse2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐRefresherCourseResponse(ctx, field.Selections, res)
+	return ec.marshalNRefresherCourseResponse2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRefresherCourseResponse(ctx, field.Selections, res)
 }
 
 func (ec *executionContext) _Query_playerCheckUser(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
@@ -1575,8 +3848,28 @@ func (ec *executionContext) _Query_playerCheckUser(ctx context.Context, field gr
 
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
-		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Query().PlayerCheckUser(rctx)
+		directive0 := func(rctx context.Context) (interface{}, error) {
+			ctx = rctx // use context from middleware stack in children
+			return ec.resolvers.Query().PlayerCheckUser(rctx)
+		}
+		directive1 := func(ctx context.Context) (interface{}, error) {
+			if ec.directives.Auth == nil {
+				return nil, errors.New("directive auth is not implemented")
+			}
+			return ec.directives.Auth(ctx, nil, directive0)
+		}
+
+		tmp, err := directive1(rctx)
+		if err != nil {
+			return nil, err
+		}
+		if tmp == nil {
+			return nil, nil
+		}
+		if data, ok := tmp.(bool); ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf(`unexpected type %T from directive, should be bool`, tmp)
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -1616,8 +3909,28 @@ func (ec *executionContext) _Query_profile(ctx context.Context, field graphql.Co
 	}
 	fc.Args = args
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
-		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Query().Profile(rctx, args["userId"].(int))
+		directive0 := func(rctx context.Context) (interface{}, error) {
+			ctx = rctx // use context from middleware stack in children
+			return ec.resolvers.Query().Profile(rctx, args["userId"].(*int))
+		}
+		directive1 := func(ctx context.Context) (interface{}, error) {
+			if ec.directives.Auth == nil {
+				return nil, errors.New("directive auth is not implemented")
+			}
+			return ec.directives.Auth(ctx, nil, directive0)
+		}
+
+		tmp, err := directive1(rctx)
+		if err != nil {
+			return nil, err
+		}
+		if tmp == nil {
+			return nil, nil
+		}
+		if data, ok := tmp.(*model.User); ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf(`unexpected type %T from directive, should be *github.com/juleur/becrpe/graph/model.User`, tmp)
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -1631,7 +3944,7 @@ func (ec *executionContext) _Query_profile(ctx context.Context, field graphql.Co
 	}
 	res := resTmp.(*model.User)
 	fc.Result = res
-	return ec.marshalNUser2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐUser(ctx, field.Selections, res)
+	return ec.marshalNUser2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐUser(ctx, field.Selections, res)
 }
 
 func (ec *executionContext) _Query_sessionCourse(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
@@ -1657,8 +3970,28 @@ func (ec *executionContext) _Query_sessionCourse(ctx context.Context, field grap
 	}
 	fc.Args = args
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
-		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Query().SessionCourse(rctx, args["input"].(model.SessionInput))
+		directive0 := func(rctx context.Context) (interface{}, error) {
+			ctx = rctx // use context from middleware stack in children
+			return ec.resolvers.Query().SessionCourse(rctx, args["input"].(model.SessionInput))
+		}
+		directive1 := func(ctx context.Context) (interface{}, error) {
+			if ec.directives.Auth == nil {
+				return nil, errors.New("directive auth is not implemented")
+			}
+			return ec.directives.Auth(ctx, nil, directive0)
+		}
+
+		tmp, err := directive1(rctx)
+		if err != nil {
+			return nil, err
+		}
+		if tmp == nil {
+			return nil, nil
+		}
+		if data, ok := tmp.(*model.SessionResponse); ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf(`unexpected type %T from directive, should be *github.com/juleur/becrpe/graph/model.SessionResponse`, tmp)
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -1672,7 +4005,7 @@ func (ec *executionContext) _Query_sessionCourse(ctx context.Context, field grap
 	}
 	res := resTmp.(*model.SessionResponse)
 	fc.Result = res
-	return ec.marshalNSessionResponse2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSessionResponse(ctx, field.Selections, res)
+	return ec.marshalNSessionResponse2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSessionResponse(ctx, field.Selections, res)
 }
 
 func (ec *executionContext) _Query_authTeacher(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
@@ -1698,8 +4031,32 @@ func (ec *executionContext) _Query_authTeacher(ctx context.Context, field graphq
 	}
 	fc.Args = args
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
-		ctx = rctx // use context from middleware stack in children
-		return ec.resolvers.Query().AuthTeacher(rctx, args["userId"].(int))
+		directive0 := func(rctx context.Context) (interface{}, error) {
+			ctx = rctx // use context from middleware stack in children
+			return ec.resolvers.Query().AuthTeacher(rctx, args["userId"].(int))
+		}
+		directive1 := func(ctx context.Context) (interface{}, error) {
+			permission, err := ec.unmarshalNPermission2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐPermission(ctx, "COURSE_PUBLISH")
+			if err != nil {
+				return nil, err
+			}
+			if ec.directives.HasPermission == nil {
+				return nil, errors.New("directive hasPermission is not implemented")
+			}
+			return ec.directives.HasPermission(ctx, nil, directive0, permission)
+		}
+
+		tmp, err := directive1(rctx)
+		if err != nil {
+			return nil, err
+		}
+		if tmp == nil {
+			return nil, nil
+		}
+		if data, ok := tmp.(bool); ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf(`unexpected type %T from directive, should be bool`, tmp)
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -1784,6 +4141,60 @@ func (ec *executionContext) _Query_totalHoursCourses(ctx context.Context, field
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
+func (ec *executionContext) _Query_deviceSessions(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Query",
+		Field:    field,
+		Args:     nil,
+		IsMethod: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		directive0 := func(rctx context.Context) (interface{}, error) {
+			ctx = rctx // use context from middleware stack in children
+			return ec.resolvers.Query().DeviceSessions(rctx)
+		}
+		directive1 := func(ctx context.Context) (interface{}, error) {
+			if ec.directives.Auth == nil {
+				return nil, errors.New("directive auth is not implemented")
+			}
+			return ec.directives.Auth(ctx, nil, directive0)
+		}
+
+		tmp, err := directive1(rctx)
+		if err != nil {
+			return nil, err
+		}
+		if tmp == nil {
+			return nil, nil
+		}
+		if data, ok := tmp.([]*model.DeviceSession); ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf(`unexpected type %T from directive, should be []*github.com/juleur/becrpe/graph/model.DeviceSession`, tmp)
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.([]*model.DeviceSession)
+	fc.Result = res
+	return ec.marshalNDeviceSession2ᚕᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐDeviceSessionᚄ(ctx, field.Selections, res)
+}
+
 func (ec *executionContext) _Query___type(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -1915,7 +4326,7 @@ func (ec *executionContext) _RefresherCourse_subject(ctx context.Context, field
 	}
 	res := resTmp.(*model.SubjectEnum)
 	fc.Result = res
-	return ec.marshalOSubjectEnum2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSubjectEnum(ctx, field.Selections, res)
+	return ec.marshalOSubjectEnum2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSubjectEnum(ctx, field.Selections, res)
 }
 
 func (ec *executionContext) _RefresherCourse_year(ctx context.Context, field graphql.CollectedField, obj *model.RefresherCourse) (ret graphql.Marshaler) {
@@ -2166,7 +4577,7 @@ func (ec *executionContext) _RefresherCourse_teachers(ctx context.Context, field
 	}
 	res := resTmp.([]*model.User)
 	fc.Result = res
-	return ec.marshalNUser2ᚕᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐUser(ctx, field.Selections, res)
+	return ec.marshalNUser2ᚕᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐUser(ctx, field.Selections, res)
 }
 
 func (ec *executionContext) _RefresherCourseResponse_refresherCourse(ctx context.Context, field graphql.CollectedField, obj *model.RefresherCourseResponse) (ret graphql.Marshaler) {
@@ -2200,7 +4611,7 @@ func (ec *executionContext) _RefresherCourseResponse_refresherCourse(ctx context
 	}
 	res := resTmp.(*model.RefresherCourse)
 	fc.Result = res
-	return ec.marshalNRefresherCourse2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐRefresherCourse(ctx, field.Selections, res)
+	return ec.marshalNRefresherCourse2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRefresherCourse(ctx, field.Selections, res)
 }
 
 func (ec *executionContext) _RefresherCourseResponse_sessions(ctx context.Context, field graphql.CollectedField, obj *model.RefresherCourseResponse) (ret graphql.Marshaler) {
@@ -2234,7 +4645,7 @@ func (ec *executionContext) _RefresherCourseResponse_sessions(ctx context.Contex
 	}
 	res := resTmp.([]*model.Session)
 	fc.Result = res
-	return ec.marshalNSession2ᚕᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSession(ctx, field.Selections, res)
+	return ec.marshalNSession2ᚕᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSession(ctx, field.Selections, res)
 }
 
 func (ec *executionContext) _Session_id(ctx context.Context, field graphql.CollectedField, obj *model.Session) (ret graphql.Marshaler) {
@@ -2330,7 +4741,7 @@ func (ec *executionContext) _Session_section(ctx context.Context, field graphql.
 	}
 	res := resTmp.(*model.SectionEnum)
 	fc.Result = res
-	return ec.marshalOSectionEnum2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSectionEnum(ctx, field.Selections, res)
+	return ec.marshalOSectionEnum2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSectionEnum(ctx, field.Selections, res)
 }
 
 func (ec *executionContext) _Session_type(ctx context.Context, field graphql.CollectedField, obj *model.Session) (ret graphql.Marshaler) {
@@ -2361,7 +4772,7 @@ func (ec *executionContext) _Session_type(ctx context.Context, field graphql.Col
 	}
 	res := resTmp.(*model.TypeEnum)
 	fc.Result = res
-	return ec.marshalOTypeEnum2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐTypeEnum(ctx, field.Selections, res)
+	return ec.marshalOTypeEnum2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐTypeEnum(ctx, field.Selections, res)
 }
 
 func (ec *executionContext) _Session_description(ctx context.Context, field graphql.CollectedField, obj *model.Session) (ret graphql.Marshaler) {
@@ -2550,7 +4961,7 @@ func (ec *executionContext) _SessionResponse_session(ctx context.Context, field
 	}
 	res := resTmp.(*model.Session)
 	fc.Result = res
-	return ec.marshalNSession2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSession(ctx, field.Selections, res)
+	return ec.marshalNSession2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSession(ctx, field.Selections, res)
 }
 
 func (ec *executionContext) _SessionResponse_video(ctx context.Context, field graphql.CollectedField, obj *model.SessionResponse) (ret graphql.Marshaler) {
@@ -2584,10 +4995,211 @@ func (ec *executionContext) _SessionResponse_video(ctx context.Context, field gr
 	}
 	res := resTmp.(*model.Video)
 	fc.Result = res
-	return ec.marshalNVideo2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐVideo(ctx, field.Selections, res)
+	return ec.marshalNVideo2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐVideo(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _SessionResponse_classPapers(ctx context.Context, field graphql.CollectedField, obj *model.SessionResponse) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "SessionResponse",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.ClassPapers, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.([]*model.ClassPaper)
+	fc.Result = res
+	return ec.marshalNClassPaper2ᚕᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐClassPaperᚄ(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _SessionResponse_teacher(ctx context.Context, field graphql.CollectedField, obj *model.SessionResponse) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "SessionResponse",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Teacher, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(*model.User)
+	fc.Result = res
+	return ec.marshalNUser2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐUser(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Token_jwt(ctx context.Context, field graphql.CollectedField, obj *model.Token) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Token",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Jwt, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Token_refreshToken(ctx context.Context, field graphql.CollectedField, obj *model.Token) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Token",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.RefreshToken, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _Token_twoFactorChallenge(ctx context.Context, field graphql.CollectedField, obj *model.Token) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "Token",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.TwoFactorChallenge, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		return graphql.Null
+	}
+	res := resTmp.(*string)
+	fc.Result = res
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _TwoFactorEnrollment_otpauthUri(ctx context.Context, field graphql.CollectedField, obj *model.TwoFactorEnrollment) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:   "TwoFactorEnrollment",
+		Field:    field,
+		Args:     nil,
+		IsMethod: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.OtpauthURI, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _SessionResponse_classPapers(ctx context.Context, field graphql.CollectedField, obj *model.SessionResponse) (ret graphql.Marshaler) {
+func (ec *executionContext) _TwoFactorEnrollment_secret(ctx context.Context, field graphql.CollectedField, obj *model.TwoFactorEnrollment) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -2595,7 +5207,7 @@ func (ec *executionContext) _SessionResponse_classPapers(ctx context.Context, fi
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "SessionResponse",
+		Object:   "TwoFactorEnrollment",
 		Field:    field,
 		Args:     nil,
 		IsMethod: false,
@@ -2604,7 +5216,7 @@ func (ec *executionContext) _SessionResponse_classPapers(ctx context.Context, fi
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ClassPapers, nil
+		return obj.Secret, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -2616,12 +5228,12 @@ func (ec *executionContext) _SessionResponse_classPapers(ctx context.Context, fi
 		}
 		return graphql.Null
 	}
-	res := resTmp.([]*model.ClassPaper)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalNClassPaper2ᚕᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐClassPaperᚄ(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _SessionResponse_teacher(ctx context.Context, field graphql.CollectedField, obj *model.SessionResponse) (ret graphql.Marshaler) {
+func (ec *executionContext) _User_id(ctx context.Context, field graphql.CollectedField, obj *model.User) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -2629,7 +5241,7 @@ func (ec *executionContext) _SessionResponse_teacher(ctx context.Context, field
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "SessionResponse",
+		Object:   "User",
 		Field:    field,
 		Args:     nil,
 		IsMethod: false,
@@ -2638,7 +5250,7 @@ func (ec *executionContext) _SessionResponse_teacher(ctx context.Context, field
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Teacher, nil
+		return obj.ID, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -2650,12 +5262,12 @@ func (ec *executionContext) _SessionResponse_teacher(ctx context.Context, field
 		}
 		return graphql.Null
 	}
-	res := resTmp.(*model.User)
+	res := resTmp.(int)
 	fc.Result = res
-	return ec.marshalNUser2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐUser(ctx, field.Selections, res)
+	return ec.marshalNID2int(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _Token_jwt(ctx context.Context, field graphql.CollectedField, obj *model.Token) (ret graphql.Marshaler) {
+func (ec *executionContext) _User_username(ctx context.Context, field graphql.CollectedField, obj *model.User) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -2663,7 +5275,7 @@ func (ec *executionContext) _Token_jwt(ctx context.Context, field graphql.Collec
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "Token",
+		Object:   "User",
 		Field:    field,
 		Args:     nil,
 		IsMethod: false,
@@ -2672,24 +5284,21 @@ func (ec *executionContext) _Token_jwt(ctx context.Context, field graphql.Collec
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Jwt, nil
+		return obj.Username, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
-			ec.Errorf(ctx, "must not be null")
-		}
 		return graphql.Null
 	}
 	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalOString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _Token_refreshToken(ctx context.Context, field graphql.CollectedField, obj *model.Token) (ret graphql.Marshaler) {
+func (ec *executionContext) _User_fullname(ctx context.Context, field graphql.CollectedField, obj *model.User) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -2697,33 +5306,30 @@ func (ec *executionContext) _Token_refreshToken(ctx context.Context, field graph
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:   "Token",
+		Object:   "User",
 		Field:    field,
 		Args:     nil,
-		IsMethod: false,
+		IsMethod: true,
 	}
 
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.RefreshToken, nil
+		return ec.resolvers.User().Fullname(rctx, obj)
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
-			ec.Errorf(ctx, "must not be null")
-		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(*string)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _User_id(ctx context.Context, field graphql.CollectedField, obj *model.User) (ret graphql.Marshaler) {
+func (ec *executionContext) _User_email(ctx context.Context, field graphql.CollectedField, obj *model.User) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -2740,24 +5346,21 @@ func (ec *executionContext) _User_id(ctx context.Context, field graphql.Collecte
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.ID, nil
+		return obj.Email, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
-			ec.Errorf(ctx, "must not be null")
-		}
 		return graphql.Null
 	}
-	res := resTmp.(int)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalNID2int(ctx, field.Selections, res)
+	return ec.marshalOString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _User_username(ctx context.Context, field graphql.CollectedField, obj *model.User) (ret graphql.Marshaler) {
+func (ec *executionContext) _User_emailVerified(ctx context.Context, field graphql.CollectedField, obj *model.User) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -2768,27 +5371,30 @@ func (ec *executionContext) _User_username(ctx context.Context, field graphql.Co
 		Object:   "User",
 		Field:    field,
 		Args:     nil,
-		IsMethod: false,
+		IsMethod: true,
 	}
 
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Username, nil
+		return ec.resolvers.User().EmailVerified(rctx, obj)
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(bool)
 	fc.Result = res
-	return ec.marshalOString2string(ctx, field.Selections, res)
+	return ec.marshalNBoolean2bool(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _User_fullname(ctx context.Context, field graphql.CollectedField, obj *model.User) (ret graphql.Marshaler) {
+func (ec *executionContext) _User_twoFactorEnabled(ctx context.Context, field graphql.CollectedField, obj *model.User) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -2799,13 +5405,13 @@ func (ec *executionContext) _User_fullname(ctx context.Context, field graphql.Co
 		Object:   "User",
 		Field:    field,
 		Args:     nil,
-		IsMethod: false,
+		IsMethod: true,
 	}
 
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Fullname, nil
+		return ec.resolvers.User().TwoFactorEnabled(rctx, obj)
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -2814,12 +5420,12 @@ func (ec *executionContext) _User_fullname(ctx context.Context, field graphql.Co
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(*bool)
 	fc.Result = res
-	return ec.marshalOString2string(ctx, field.Selections, res)
+	return ec.marshalOBoolean2ᚖbool(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _User_email(ctx context.Context, field graphql.CollectedField, obj *model.User) (ret graphql.Marshaler) {
+func (ec *executionContext) _User_isTeacher(ctx context.Context, field graphql.CollectedField, obj *model.User) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -2830,13 +5436,13 @@ func (ec *executionContext) _User_email(ctx context.Context, field graphql.Colle
 		Object:   "User",
 		Field:    field,
 		Args:     nil,
-		IsMethod: false,
+		IsMethod: true,
 	}
 
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Email, nil
+		return ec.resolvers.User().IsTeacher(rctx, obj)
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -2845,12 +5451,12 @@ func (ec *executionContext) _User_email(ctx context.Context, field graphql.Colle
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(*bool)
 	fc.Result = res
-	return ec.marshalOString2string(ctx, field.Selections, res)
+	return ec.marshalOBoolean2ᚖbool(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _User_isTeacher(ctx context.Context, field graphql.CollectedField, obj *model.User) (ret graphql.Marshaler) {
+func (ec *executionContext) _User_roles(ctx context.Context, field graphql.CollectedField, obj *model.User) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -2861,13 +5467,13 @@ func (ec *executionContext) _User_isTeacher(ctx context.Context, field graphql.C
 		Object:   "User",
 		Field:    field,
 		Args:     nil,
-		IsMethod: false,
+		IsMethod: true,
 	}
 
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.IsTeacher, nil
+		return ec.resolvers.User().Roles(rctx, obj)
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -2876,9 +5482,9 @@ func (ec *executionContext) _User_isTeacher(ctx context.Context, field graphql.C
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(bool)
+	res := resTmp.([]model.Role)
 	fc.Result = res
-	return ec.marshalOBoolean2bool(ctx, field.Selections, res)
+	return ec.marshalORole2ᚕgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRoleᚄ(ctx, field.Selections, res)
 }
 
 func (ec *executionContext) _User_createdAt(ctx context.Context, field graphql.CollectedField, obj *model.User) (ret graphql.Marshaler) {
@@ -2923,13 +5529,13 @@ func (ec *executionContext) _User_updatedAt(ctx context.Context, field graphql.C
 		Object:   "User",
 		Field:    field,
 		Args:     nil,
-		IsMethod: false,
+		IsMethod: true,
 	}
 
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.UpdatedAt, nil
+		return ec.resolvers.User().UpdatedAt(rctx, obj)
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -2938,9 +5544,9 @@ func (ec *executionContext) _User_updatedAt(ctx context.Context, field graphql.C
 	if resTmp == nil {
 		return graphql.Null
 	}
-	res := resTmp.(time.Time)
+	res := resTmp.(*time.Time)
 	fc.Result = res
-	return ec.marshalOTime2timeᚐTime(ctx, field.Selections, res)
+	return ec.marshalOTime2ᚖtimeᚐTime(ctx, field.Selections, res)
 }
 
 func (ec *executionContext) _Video_id(ctx context.Context, field graphql.CollectedField, obj *model.Video) (ret graphql.Marshaler) {
@@ -4156,6 +6762,54 @@ func (ec *executionContext) ___Type_ofType(ctx context.Context, field graphql.Co
 
 // region    **************************** input.gotpl *****************************
 
+func (ec *executionContext) unmarshalInputConfirmTwoFactorInput(ctx context.Context, obj interface{}) (model.ConfirmTwoFactorInput, error) {
+	var it model.ConfirmTwoFactorInput
+	var asMap = obj.(map[string]interface{})
+
+	for k, v := range asMap {
+		switch k {
+		case "password":
+			var err error
+			it.Password, err = ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+		case "code":
+			var err error
+			it.Code, err = ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+		}
+	}
+
+	return it, nil
+}
+
+func (ec *executionContext) unmarshalInputDeleteAccountInput(ctx context.Context, obj interface{}) (model.DeleteAccountInput, error) {
+	var it model.DeleteAccountInput
+	var asMap = obj.(map[string]interface{})
+
+	for k, v := range asMap {
+		switch k {
+		case "password":
+			var err error
+			it.Password, err = ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+		case "twoFactorCode":
+			var err error
+			it.TwoFactorCode, err = ec.unmarshalOString2ᚖstring(ctx, v)
+			if err != nil {
+				return it, err
+			}
+		}
+	}
+
+	return it, nil
+}
+
 func (ec *executionContext) unmarshalInputDocUploadFile(ctx context.Context, obj interface{}) (model.DocUploadFile, error) {
 	var it model.DocUploadFile
 	var asMap = obj.(map[string]interface{})
@@ -4180,6 +6834,24 @@ func (ec *executionContext) unmarshalInputDocUploadFile(ctx context.Context, obj
 	return it, nil
 }
 
+func (ec *executionContext) unmarshalInputEnableTwoFactorInput(ctx context.Context, obj interface{}) (model.EnableTwoFactorInput, error) {
+	var it model.EnableTwoFactorInput
+	var asMap = obj.(map[string]interface{})
+
+	for k, v := range asMap {
+		switch k {
+		case "password":
+			var err error
+			it.Password, err = ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+		}
+	}
+
+	return it, nil
+}
+
 func (ec *executionContext) unmarshalInputLoginInput(ctx context.Context, obj interface{}) (model.LoginInput, error) {
 	var it model.LoginInput
 	var asMap = obj.(map[string]interface{})
@@ -4224,13 +6896,13 @@ func (ec *executionContext) unmarshalInputNewSessionInput(ctx context.Context, o
 			}
 		case "section":
 			var err error
-			it.Section, err = ec.unmarshalNSectionEnum2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSectionEnum(ctx, v)
+			it.Section, err = ec.unmarshalNSectionEnum2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSectionEnum(ctx, v)
 			if err != nil {
 				return it, err
 			}
 		case "type":
 			var err error
-			it.Type, err = ec.unmarshalNTypeEnum2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐTypeEnum(ctx, v)
+			it.Type, err = ec.unmarshalNTypeEnum2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐTypeEnum(ctx, v)
 			if err != nil {
 				return it, err
 			}
@@ -4260,7 +6932,7 @@ func (ec *executionContext) unmarshalInputNewSessionInput(ctx context.Context, o
 			}
 		case "docFiles":
 			var err error
-			it.DocFiles, err = ec.unmarshalODocUploadFile2ᚕᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐDocUploadFile(ctx, v)
+			it.DocFiles, err = ec.unmarshalODocUploadFile2ᚕᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐDocUploadFile(ctx, v)
 			if err != nil {
 				return it, err
 			}
@@ -4344,13 +7016,52 @@ func (ec *executionContext) unmarshalInputRefresherCourseInput(ctx context.Conte
 		switch k {
 		case "byUserId":
 			var err error
-			it.ByUserID, err = ec.unmarshalOInt2ᚖint(ctx, v)
+			directive0 := func(ctx context.Context) (interface{}, error) { return ec.unmarshalOInt2ᚖint(ctx, v) }
+			directive1 := func(ctx context.Context) (interface{}, error) {
+				if ec.directives.Auth == nil {
+					return nil, errors.New("directive auth is not implemented")
+				}
+				return ec.directives.Auth(ctx, obj, directive0)
+			}
+
+			tmp, err := directive1(ctx)
 			if err != nil {
 				return it, err
 			}
+			if data, ok := tmp.(*int); ok {
+				it.ByUserID = data
+			} else if tmp == nil {
+				it.ByUserID = nil
+			} else {
+				return it, fmt.Errorf(`unexpected type %T from directive, should be *int`, tmp)
+			}
 		case "bySubject":
 			var err error
-			it.BySubject, err = ec.unmarshalOSubjectEnum2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSubjectEnum(ctx, v)
+			it.BySubject, err = ec.unmarshalOSubjectEnum2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSubjectEnum(ctx, v)
+			if err != nil {
+				return it, err
+			}
+		}
+	}
+
+	return it, nil
+}
+
+func (ec *executionContext) unmarshalInputResetPasswordInput(ctx context.Context, obj interface{}) (model.ResetPasswordInput, error) {
+	var it model.ResetPasswordInput
+	var asMap = obj.(map[string]interface{})
+
+	for k, v := range asMap {
+		switch k {
+		case "token":
+			var err error
+			it.Token, err = ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+		case "password":
+			var err error
+			it.Password, err = ec.unmarshalNString2string(ctx, v)
 			if err != nil {
 				return it, err
 			}
@@ -4368,7 +7079,7 @@ func (ec *executionContext) unmarshalInputSessionInput(ctx context.Context, obj
 		switch k {
 		case "userId":
 			var err error
-			it.UserID, err = ec.unmarshalNInt2int(ctx, v)
+			it.UserID, err = ec.unmarshalOInt2ᚖint(ctx, v)
 			if err != nil {
 				return it, err
 			}
@@ -4422,42 +7133,198 @@ func (ec *executionContext) unmarshalInputUpdateUserInput(ctx context.Context, o
 			}
 		}
 	}
-
-	return it, nil
+
+	return it, nil
+}
+
+func (ec *executionContext) unmarshalInputVerifyTwoFactorInput(ctx context.Context, obj interface{}) (model.VerifyTwoFactorInput, error) {
+	var it model.VerifyTwoFactorInput
+	var asMap = obj.(map[string]interface{})
+
+	for k, v := range asMap {
+		switch k {
+		case "challenge":
+			var err error
+			it.Challenge, err = ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+		case "code":
+			var err error
+			it.Code, err = ec.unmarshalNString2string(ctx, v)
+			if err != nil {
+				return it, err
+			}
+		}
+	}
+
+	return it, nil
+}
+
+// endregion **************************** input.gotpl *****************************
+
+// region    ************************** interface.gotpl ***************************
+
+// endregion ************************** interface.gotpl ***************************
+
+// region    **************************** object.gotpl ****************************
+
+var classPaperImplementors = []string{"ClassPaper"}
+
+func (ec *executionContext) _ClassPaper(ctx context.Context, sel ast.SelectionSet, obj *model.ClassPaper) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, classPaperImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("ClassPaper")
+		case "id":
+			out.Values[i] = ec._ClassPaper_id(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "title":
+			out.Values[i] = ec._ClassPaper_title(ctx, field, obj)
+		case "path":
+			out.Values[i] = ec._ClassPaper_path(ctx, field, obj)
+		case "createdAt":
+			out.Values[i] = ec._ClassPaper_createdAt(ctx, field, obj)
+		case "updatedAt":
+			out.Values[i] = ec._ClassPaper_updatedAt(ctx, field, obj)
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch()
+	if invalids > 0 {
+		return graphql.Null
+	}
+	return out
+}
+
+var dataExportImplementors = []string{"DataExport"}
+
+func (ec *executionContext) _DataExport(ctx context.Context, sel ast.SelectionSet, obj *model.DataExport) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, dataExportImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("DataExport")
+		case "filename":
+			out.Values[i] = ec._DataExport_filename(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "contentType":
+			out.Values[i] = ec._DataExport_contentType(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "content":
+			out.Values[i] = ec._DataExport_content(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch()
+	if invalids > 0 {
+		return graphql.Null
+	}
+	return out
 }
 
-// endregion **************************** input.gotpl *****************************
-
-// region    ************************** interface.gotpl ***************************
+var deviceSessionImplementors = []string{"DeviceSession"}
 
-// endregion ************************** interface.gotpl ***************************
+func (ec *executionContext) _DeviceSession(ctx context.Context, sel ast.SelectionSet, obj *model.DeviceSession) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, deviceSessionImplementors)
 
-// region    **************************** object.gotpl ****************************
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("DeviceSession")
+		case "id":
+			out.Values[i] = ec._DeviceSession_id(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "userAgent":
+			out.Values[i] = ec._DeviceSession_userAgent(ctx, field, obj)
+		case "ipAddress":
+			out.Values[i] = ec._DeviceSession_ipAddress(ctx, field, obj)
+		case "deliveredAt":
+			out.Values[i] = ec._DeviceSession_deliveredAt(ctx, field, obj)
+		case "origin":
+			out.Values[i] = ec._DeviceSession_origin(ctx, field, obj)
+		case "current":
+			out.Values[i] = ec._DeviceSession_current(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch()
+	if invalids > 0 {
+		return graphql.Null
+	}
+	return out
+}
 
-var classPaperImplementors = []string{"ClassPaper"}
+var meImplementors = []string{"Me"}
 
-func (ec *executionContext) _ClassPaper(ctx context.Context, sel ast.SelectionSet, obj *model.ClassPaper) graphql.Marshaler {
-	fields := graphql.CollectFields(ec.OperationContext, sel, classPaperImplementors)
+func (ec *executionContext) _Me(ctx context.Context, sel ast.SelectionSet, obj *model.Me) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, meImplementors)
 
 	out := graphql.NewFieldSet(fields)
 	var invalids uint32
 	for i, field := range fields {
 		switch field.Name {
 		case "__typename":
-			out.Values[i] = graphql.MarshalString("ClassPaper")
-		case "id":
-			out.Values[i] = ec._ClassPaper_id(ctx, field, obj)
+			out.Values[i] = graphql.MarshalString("Me")
+		case "user":
+			out.Values[i] = ec._Me_user(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
-				invalids++
+				atomic.AddUint32(&invalids, 1)
 			}
-		case "title":
-			out.Values[i] = ec._ClassPaper_title(ctx, field, obj)
-		case "path":
-			out.Values[i] = ec._ClassPaper_path(ctx, field, obj)
-		case "createdAt":
-			out.Values[i] = ec._ClassPaper_createdAt(ctx, field, obj)
-		case "updatedAt":
-			out.Values[i] = ec._ClassPaper_updatedAt(ctx, field, obj)
+		case "purchasedCourses":
+			field := field
+			out.Concurrently(i, func() (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._Me_purchasedCourses(ctx, field, obj)
+				if res == graphql.Null {
+					atomic.AddUint32(&invalids, 1)
+				}
+				return res
+			})
+		case "deviceSessions":
+			field := field
+			out.Concurrently(i, func() (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._Me_deviceSessions(ctx, field, obj)
+				if res == graphql.Null {
+					atomic.AddUint32(&invalids, 1)
+				}
+				return res
+			})
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -4484,6 +7351,11 @@ func (ec *executionContext) _Mutation(ctx context.Context, sel ast.SelectionSet)
 		switch field.Name {
 		case "__typename":
 			out.Values[i] = graphql.MarshalString("Mutation")
+		case "login":
+			out.Values[i] = ec._Mutation_login(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
 		case "createUser":
 			out.Values[i] = ec._Mutation_createUser(ctx, field)
 			if out.Values[i] == graphql.Null {
@@ -4509,6 +7381,120 @@ func (ec *executionContext) _Mutation(ctx context.Context, sel ast.SelectionSet)
 			if out.Values[i] == graphql.Null {
 				invalids++
 			}
+		case "revokeDeviceSession":
+			out.Values[i] = ec._Mutation_revokeDeviceSession(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "logoutEverywhere":
+			out.Values[i] = ec._Mutation_logoutEverywhere(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "logout":
+			out.Values[i] = ec._Mutation_logout(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "requestPasswordReset":
+			out.Values[i] = ec._Mutation_requestPasswordReset(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "resetPassword":
+			out.Values[i] = ec._Mutation_resetPassword(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "verifyEmail":
+			out.Values[i] = ec._Mutation_verifyEmail(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "resendVerificationEmail":
+			out.Values[i] = ec._Mutation_resendVerificationEmail(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "enableTwoFactor":
+			out.Values[i] = ec._Mutation_enableTwoFactor(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "confirmTwoFactor":
+			out.Values[i] = ec._Mutation_confirmTwoFactor(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "disableTwoFactor":
+			out.Values[i] = ec._Mutation_disableTwoFactor(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "verifyTwoFactor":
+			out.Values[i] = ec._Mutation_verifyTwoFactor(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "grantRole":
+			out.Values[i] = ec._Mutation_grantRole(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "revokeRole":
+			out.Values[i] = ec._Mutation_revokeRole(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "exportMyData":
+			out.Values[i] = ec._Mutation_exportMyData(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "deleteAccount":
+			out.Values[i] = ec._Mutation_deleteAccount(ctx, field)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch()
+	if invalids > 0 {
+		return graphql.Null
+	}
+	return out
+}
+
+var purchasedCourseImplementors = []string{"PurchasedCourse"}
+
+func (ec *executionContext) _PurchasedCourse(ctx context.Context, sel ast.SelectionSet, obj *model.PurchasedCourse) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, purchasedCourseImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("PurchasedCourse")
+		case "refresherCourse":
+			out.Values[i] = ec._PurchasedCourse_refresherCourse(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "purchasedAt":
+			out.Values[i] = ec._PurchasedCourse_purchasedAt(ctx, field, obj)
+		case "viewedSessions":
+			out.Values[i] = ec._PurchasedCourse_viewedSessions(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "totalSessions":
+			out.Values[i] = ec._PurchasedCourse_totalSessions(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -4549,6 +7535,20 @@ func (ec *executionContext) _Query(ctx context.Context, sel ast.SelectionSet) gr
 				}
 				return res
 			})
+		case "me":
+			field := field
+			out.Concurrently(i, func() (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._Query_me(ctx, field)
+				if res == graphql.Null {
+					atomic.AddUint32(&invalids, 1)
+				}
+				return res
+			})
 		case "refresherCourses":
 			field := field
 			out.Concurrently(i, func() (res graphql.Marshaler) {
@@ -4661,6 +7661,20 @@ func (ec *executionContext) _Query(ctx context.Context, sel ast.SelectionSet) gr
 				}
 				return res
 			})
+		case "deviceSessions":
+			field := field
+			out.Concurrently(i, func() (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._Query_deviceSessions(ctx, field)
+				if res == graphql.Null {
+					atomic.AddUint32(&invalids, 1)
+				}
+				return res
+			})
 		case "__type":
 			out.Values[i] = ec._Query___type(ctx, field)
 		case "__schema":
@@ -4889,6 +7903,40 @@ func (ec *executionContext) _Token(ctx context.Context, sel ast.SelectionSet, ob
 			if out.Values[i] == graphql.Null {
 				invalids++
 			}
+		case "twoFactorChallenge":
+			out.Values[i] = ec._Token_twoFactorChallenge(ctx, field, obj)
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch()
+	if invalids > 0 {
+		return graphql.Null
+	}
+	return out
+}
+
+var twoFactorEnrollmentImplementors = []string{"TwoFactorEnrollment"}
+
+func (ec *executionContext) _TwoFactorEnrollment(ctx context.Context, sel ast.SelectionSet, obj *model.TwoFactorEnrollment) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, twoFactorEnrollmentImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("TwoFactorEnrollment")
+		case "otpauthUri":
+			out.Values[i] = ec._TwoFactorEnrollment_otpauthUri(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "secret":
+			out.Values[i] = ec._TwoFactorEnrollment_secret(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -4914,20 +7962,83 @@ func (ec *executionContext) _User(ctx context.Context, sel ast.SelectionSet, obj
 		case "id":
 			out.Values[i] = ec._User_id(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
-				invalids++
+				atomic.AddUint32(&invalids, 1)
 			}
 		case "username":
 			out.Values[i] = ec._User_username(ctx, field, obj)
 		case "fullname":
-			out.Values[i] = ec._User_fullname(ctx, field, obj)
+			field := field
+			out.Concurrently(i, func() (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._User_fullname(ctx, field, obj)
+				return res
+			})
 		case "email":
 			out.Values[i] = ec._User_email(ctx, field, obj)
+		case "emailVerified":
+			field := field
+			out.Concurrently(i, func() (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._User_emailVerified(ctx, field, obj)
+				if res == graphql.Null {
+					atomic.AddUint32(&invalids, 1)
+				}
+				return res
+			})
+		case "twoFactorEnabled":
+			field := field
+			out.Concurrently(i, func() (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._User_twoFactorEnabled(ctx, field, obj)
+				return res
+			})
 		case "isTeacher":
-			out.Values[i] = ec._User_isTeacher(ctx, field, obj)
+			field := field
+			out.Concurrently(i, func() (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._User_isTeacher(ctx, field, obj)
+				return res
+			})
+		case "roles":
+			field := field
+			out.Concurrently(i, func() (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._User_roles(ctx, field, obj)
+				return res
+			})
 		case "createdAt":
 			out.Values[i] = ec._User_createdAt(ctx, field, obj)
 		case "updatedAt":
-			out.Values[i] = ec._User_updatedAt(ctx, field, obj)
+			field := field
+			out.Concurrently(i, func() (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._User_updatedAt(ctx, field, obj)
+				return res
+			})
 		default:
 			panic("unknown field " + strconv.Quote(field.Name))
 		}
@@ -5233,11 +8344,11 @@ func (ec *executionContext) marshalNBoolean2bool(ctx context.Context, sel ast.Se
 	return res
 }
 
-func (ec *executionContext) marshalNClassPaper2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐClassPaper(ctx context.Context, sel ast.SelectionSet, v model.ClassPaper) graphql.Marshaler {
+func (ec *executionContext) marshalNClassPaper2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐClassPaper(ctx context.Context, sel ast.SelectionSet, v model.ClassPaper) graphql.Marshaler {
 	return ec._ClassPaper(ctx, sel, &v)
 }
 
-func (ec *executionContext) marshalNClassPaper2ᚕᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐClassPaperᚄ(ctx context.Context, sel ast.SelectionSet, v []*model.ClassPaper) graphql.Marshaler {
+func (ec *executionContext) marshalNClassPaper2ᚕᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐClassPaperᚄ(ctx context.Context, sel ast.SelectionSet, v []*model.ClassPaper) graphql.Marshaler {
 	ret := make(graphql.Array, len(v))
 	var wg sync.WaitGroup
 	isLen1 := len(v) == 1
@@ -5261,7 +8372,7 @@ func (ec *executionContext) marshalNClassPaper2ᚕᚖgithubᚗcomᚋjuleurᚋecr
 			if !isLen1 {
 				defer wg.Done()
 			}
-			ret[i] = ec.marshalNClassPaper2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐClassPaper(ctx, sel, v[i])
+			ret[i] = ec.marshalNClassPaper2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐClassPaper(ctx, sel, v[i])
 		}
 		if isLen1 {
 			f(i)
@@ -5274,7 +8385,7 @@ func (ec *executionContext) marshalNClassPaper2ᚕᚖgithubᚗcomᚋjuleurᚋecr
 	return ret
 }
 
-func (ec *executionContext) marshalNClassPaper2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐClassPaper(ctx context.Context, sel ast.SelectionSet, v *model.ClassPaper) graphql.Marshaler {
+func (ec *executionContext) marshalNClassPaper2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐClassPaper(ctx context.Context, sel ast.SelectionSet, v *model.ClassPaper) graphql.Marshaler {
 	if v == nil {
 		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
 			ec.Errorf(ctx, "must not be null")
@@ -5284,6 +8395,83 @@ func (ec *executionContext) marshalNClassPaper2ᚖgithubᚗcomᚋjuleurᚋecrpe
 	return ec._ClassPaper(ctx, sel, v)
 }
 
+func (ec *executionContext) unmarshalNConfirmTwoFactorInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐConfirmTwoFactorInput(ctx context.Context, v interface{}) (model.ConfirmTwoFactorInput, error) {
+	return ec.unmarshalInputConfirmTwoFactorInput(ctx, v)
+}
+
+func (ec *executionContext) marshalNDataExport2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐDataExport(ctx context.Context, sel ast.SelectionSet, v model.DataExport) graphql.Marshaler {
+	return ec._DataExport(ctx, sel, &v)
+}
+
+func (ec *executionContext) marshalNDataExport2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐDataExport(ctx context.Context, sel ast.SelectionSet, v *model.DataExport) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	return ec._DataExport(ctx, sel, v)
+}
+
+func (ec *executionContext) unmarshalNDeleteAccountInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐDeleteAccountInput(ctx context.Context, v interface{}) (model.DeleteAccountInput, error) {
+	return ec.unmarshalInputDeleteAccountInput(ctx, v)
+}
+
+func (ec *executionContext) marshalNDeviceSession2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐDeviceSession(ctx context.Context, sel ast.SelectionSet, v model.DeviceSession) graphql.Marshaler {
+	return ec._DeviceSession(ctx, sel, &v)
+}
+
+func (ec *executionContext) marshalNDeviceSession2ᚕᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐDeviceSessionᚄ(ctx context.Context, sel ast.SelectionSet, v []*model.DeviceSession) graphql.Marshaler {
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNDeviceSession2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐDeviceSession(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+	return ret
+}
+
+func (ec *executionContext) marshalNDeviceSession2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐDeviceSession(ctx context.Context, sel ast.SelectionSet, v *model.DeviceSession) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	return ec._DeviceSession(ctx, sel, v)
+}
+
+func (ec *executionContext) unmarshalNEnableTwoFactorInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐEnableTwoFactorInput(ctx context.Context, v interface{}) (model.EnableTwoFactorInput, error) {
+	return ec.unmarshalInputEnableTwoFactorInput(ctx, v)
+}
+
 func (ec *executionContext) unmarshalNID2int(ctx context.Context, v interface{}) (int, error) {
 	return graphql.UnmarshalInt(v)
 }
@@ -5326,27 +8514,101 @@ func (ec *executionContext) marshalNInt2int(ctx context.Context, sel ast.Selecti
 	return res
 }
 
-func (ec *executionContext) unmarshalNLoginInput2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐLoginInput(ctx context.Context, v interface{}) (model.LoginInput, error) {
+func (ec *executionContext) unmarshalNLoginInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐLoginInput(ctx context.Context, v interface{}) (model.LoginInput, error) {
 	return ec.unmarshalInputLoginInput(ctx, v)
 }
 
-func (ec *executionContext) unmarshalNNewSessionInput2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐNewSessionInput(ctx context.Context, v interface{}) (model.NewSessionInput, error) {
+func (ec *executionContext) marshalNMe2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐMe(ctx context.Context, sel ast.SelectionSet, v model.Me) graphql.Marshaler {
+	return ec._Me(ctx, sel, &v)
+}
+
+func (ec *executionContext) marshalNMe2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐMe(ctx context.Context, sel ast.SelectionSet, v *model.Me) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	return ec._Me(ctx, sel, v)
+}
+
+func (ec *executionContext) unmarshalNNewSessionInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐNewSessionInput(ctx context.Context, v interface{}) (model.NewSessionInput, error) {
 	return ec.unmarshalInputNewSessionInput(ctx, v)
 }
 
-func (ec *executionContext) unmarshalNNewUserInput2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐNewUserInput(ctx context.Context, v interface{}) (model.NewUserInput, error) {
+func (ec *executionContext) unmarshalNNewUserInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐNewUserInput(ctx context.Context, v interface{}) (model.NewUserInput, error) {
 	return ec.unmarshalInputNewUserInput(ctx, v)
 }
 
-func (ec *executionContext) unmarshalNPurchaseRefresherCourseInput2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐPurchaseRefresherCourseInput(ctx context.Context, v interface{}) (model.PurchaseRefresherCourseInput, error) {
+func (ec *executionContext) unmarshalNPermission2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐPermission(ctx context.Context, v interface{}) (model.Permission, error) {
+	var res model.Permission
+	return res, res.UnmarshalGQL(v)
+}
+
+func (ec *executionContext) marshalNPermission2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐPermission(ctx context.Context, sel ast.SelectionSet, v model.Permission) graphql.Marshaler {
+	return v
+}
+
+func (ec *executionContext) unmarshalNPurchaseRefresherCourseInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐPurchaseRefresherCourseInput(ctx context.Context, v interface{}) (model.PurchaseRefresherCourseInput, error) {
 	return ec.unmarshalInputPurchaseRefresherCourseInput(ctx, v)
 }
 
-func (ec *executionContext) marshalNRefresherCourse2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐRefresherCourse(ctx context.Context, sel ast.SelectionSet, v model.RefresherCourse) graphql.Marshaler {
+func (ec *executionContext) marshalNPurchasedCourse2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐPurchasedCourse(ctx context.Context, sel ast.SelectionSet, v model.PurchasedCourse) graphql.Marshaler {
+	return ec._PurchasedCourse(ctx, sel, &v)
+}
+
+func (ec *executionContext) marshalNPurchasedCourse2ᚕᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐPurchasedCourseᚄ(ctx context.Context, sel ast.SelectionSet, v []*model.PurchasedCourse) graphql.Marshaler {
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNPurchasedCourse2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐPurchasedCourse(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+	return ret
+}
+
+func (ec *executionContext) marshalNPurchasedCourse2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐPurchasedCourse(ctx context.Context, sel ast.SelectionSet, v *model.PurchasedCourse) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	return ec._PurchasedCourse(ctx, sel, v)
+}
+
+func (ec *executionContext) marshalNRefresherCourse2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRefresherCourse(ctx context.Context, sel ast.SelectionSet, v model.RefresherCourse) graphql.Marshaler {
 	return ec._RefresherCourse(ctx, sel, &v)
 }
 
-func (ec *executionContext) marshalNRefresherCourse2ᚕᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐRefresherCourse(ctx context.Context, sel ast.SelectionSet, v []*model.RefresherCourse) graphql.Marshaler {
+func (ec *executionContext) marshalNRefresherCourse2ᚕᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRefresherCourse(ctx context.Context, sel ast.SelectionSet, v []*model.RefresherCourse) graphql.Marshaler {
 	ret := make(graphql.Array, len(v))
 	var wg sync.WaitGroup
 	isLen1 := len(v) == 1
@@ -5370,7 +8632,7 @@ func (ec *executionContext) marshalNRefresherCourse2ᚕᚖgithubᚗcomᚋjuleur
 			if !isLen1 {
 				defer wg.Done()
 			}
-			ret[i] = ec.marshalORefresherCourse2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐRefresherCourse(ctx, sel, v[i])
+			ret[i] = ec.marshalORefresherCourse2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRefresherCourse(ctx, sel, v[i])
 		}
 		if isLen1 {
 			f(i)
@@ -5383,7 +8645,7 @@ func (ec *executionContext) marshalNRefresherCourse2ᚕᚖgithubᚗcomᚋjuleur
 	return ret
 }
 
-func (ec *executionContext) marshalNRefresherCourse2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐRefresherCourse(ctx context.Context, sel ast.SelectionSet, v *model.RefresherCourse) graphql.Marshaler {
+func (ec *executionContext) marshalNRefresherCourse2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRefresherCourse(ctx context.Context, sel ast.SelectionSet, v *model.RefresherCourse) graphql.Marshaler {
 	if v == nil {
 		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
 			ec.Errorf(ctx, "must not be null")
@@ -5393,15 +8655,15 @@ func (ec *executionContext) marshalNRefresherCourse2ᚖgithubᚗcomᚋjuleurᚋe
 	return ec._RefresherCourse(ctx, sel, v)
 }
 
-func (ec *executionContext) unmarshalNRefresherCourseInput2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐRefresherCourseInput(ctx context.Context, v interface{}) (model.RefresherCourseInput, error) {
+func (ec *executionContext) unmarshalNRefresherCourseInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRefresherCourseInput(ctx context.Context, v interface{}) (model.RefresherCourseInput, error) {
 	return ec.unmarshalInputRefresherCourseInput(ctx, v)
 }
 
-func (ec *executionContext) marshalNRefresherCourseResponse2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐRefresherCourseResponse(ctx context.Context, sel ast.SelectionSet, v model.RefresherCourseResponse) graphql.Marshaler {
+func (ec *executionContext) marshalNRefresherCourseResponse2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRefresherCourseResponse(ctx context.Context, sel ast.SelectionSet, v model.RefresherCourseResponse) graphql.Marshaler {
 	return ec._RefresherCourseResponse(ctx, sel, &v)
 }
 
-func (ec *executionContext) marshalNRefresherCourseResponse2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐRefresherCourseResponse(ctx context.Context, sel ast.SelectionSet, v *model.RefresherCourseResponse) graphql.Marshaler {
+func (ec *executionContext) marshalNRefresherCourseResponse2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRefresherCourseResponse(ctx context.Context, sel ast.SelectionSet, v *model.RefresherCourseResponse) graphql.Marshaler {
 	if v == nil {
 		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
 			ec.Errorf(ctx, "must not be null")
@@ -5411,20 +8673,90 @@ func (ec *executionContext) marshalNRefresherCourseResponse2ᚖgithubᚗcomᚋju
 	return ec._RefresherCourseResponse(ctx, sel, v)
 }
 
-func (ec *executionContext) unmarshalNSectionEnum2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSectionEnum(ctx context.Context, v interface{}) (model.SectionEnum, error) {
+func (ec *executionContext) unmarshalNResetPasswordInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐResetPasswordInput(ctx context.Context, v interface{}) (model.ResetPasswordInput, error) {
+	return ec.unmarshalInputResetPasswordInput(ctx, v)
+}
+
+func (ec *executionContext) unmarshalNRole2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRole(ctx context.Context, v interface{}) (model.Role, error) {
+	var res model.Role
+	return res, res.UnmarshalGQL(v)
+}
+
+func (ec *executionContext) marshalNRole2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRole(ctx context.Context, sel ast.SelectionSet, v model.Role) graphql.Marshaler {
+	return v
+}
+
+func (ec *executionContext) unmarshalNRole2ᚕgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRoleᚄ(ctx context.Context, v interface{}) ([]model.Role, error) {
+	var vSlice []interface{}
+	if v != nil {
+		if tmp1, ok := v.([]interface{}); ok {
+			vSlice = tmp1
+		} else {
+			vSlice = []interface{}{v}
+		}
+	}
+	var err error
+	res := make([]model.Role, len(vSlice))
+	for i := range vSlice {
+		res[i], err = ec.unmarshalNRole2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRole(ctx, vSlice[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (ec *executionContext) marshalNRole2ᚕgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRoleᚄ(ctx context.Context, sel ast.SelectionSet, v []model.Role) graphql.Marshaler {
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNRole2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRole(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+	return ret
+}
+
+func (ec *executionContext) unmarshalNSectionEnum2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSectionEnum(ctx context.Context, v interface{}) (model.SectionEnum, error) {
 	var res model.SectionEnum
 	return res, res.UnmarshalGQL(v)
 }
 
-func (ec *executionContext) marshalNSectionEnum2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSectionEnum(ctx context.Context, sel ast.SelectionSet, v model.SectionEnum) graphql.Marshaler {
+func (ec *executionContext) marshalNSectionEnum2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSectionEnum(ctx context.Context, sel ast.SelectionSet, v model.SectionEnum) graphql.Marshaler {
 	return v
 }
 
-func (ec *executionContext) marshalNSession2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSession(ctx context.Context, sel ast.SelectionSet, v model.Session) graphql.Marshaler {
+func (ec *executionContext) marshalNSession2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSession(ctx context.Context, sel ast.SelectionSet, v model.Session) graphql.Marshaler {
 	return ec._Session(ctx, sel, &v)
 }
 
-func (ec *executionContext) marshalNSession2ᚕᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSession(ctx context.Context, sel ast.SelectionSet, v []*model.Session) graphql.Marshaler {
+func (ec *executionContext) marshalNSession2ᚕᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSession(ctx context.Context, sel ast.SelectionSet, v []*model.Session) graphql.Marshaler {
 	ret := make(graphql.Array, len(v))
 	var wg sync.WaitGroup
 	isLen1 := len(v) == 1
@@ -5448,7 +8780,7 @@ func (ec *executionContext) marshalNSession2ᚕᚖgithubᚗcomᚋjuleurᚋecrpe
 			if !isLen1 {
 				defer wg.Done()
 			}
-			ret[i] = ec.marshalOSession2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSession(ctx, sel, v[i])
+			ret[i] = ec.marshalOSession2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSession(ctx, sel, v[i])
 		}
 		if isLen1 {
 			f(i)
@@ -5461,7 +8793,7 @@ func (ec *executionContext) marshalNSession2ᚕᚖgithubᚗcomᚋjuleurᚋecrpe
 	return ret
 }
 
-func (ec *executionContext) marshalNSession2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSession(ctx context.Context, sel ast.SelectionSet, v *model.Session) graphql.Marshaler {
+func (ec *executionContext) marshalNSession2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSession(ctx context.Context, sel ast.SelectionSet, v *model.Session) graphql.Marshaler {
 	if v == nil {
 		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
 			ec.Errorf(ctx, "must not be null")
@@ -5471,15 +8803,15 @@ func (ec *executionContext) marshalNSession2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋg
 	return ec._Session(ctx, sel, v)
 }
 
-func (ec *executionContext) unmarshalNSessionInput2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSessionInput(ctx context.Context, v interface{}) (model.SessionInput, error) {
+func (ec *executionContext) unmarshalNSessionInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSessionInput(ctx context.Context, v interface{}) (model.SessionInput, error) {
 	return ec.unmarshalInputSessionInput(ctx, v)
 }
 
-func (ec *executionContext) marshalNSessionResponse2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSessionResponse(ctx context.Context, sel ast.SelectionSet, v model.SessionResponse) graphql.Marshaler {
+func (ec *executionContext) marshalNSessionResponse2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSessionResponse(ctx context.Context, sel ast.SelectionSet, v model.SessionResponse) graphql.Marshaler {
 	return ec._SessionResponse(ctx, sel, &v)
 }
 
-func (ec *executionContext) marshalNSessionResponse2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSessionResponse(ctx context.Context, sel ast.SelectionSet, v *model.SessionResponse) graphql.Marshaler {
+func (ec *executionContext) marshalNSessionResponse2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSessionResponse(ctx context.Context, sel ast.SelectionSet, v *model.SessionResponse) graphql.Marshaler {
 	if v == nil {
 		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
 			ec.Errorf(ctx, "must not be null")
@@ -5546,11 +8878,11 @@ func (ec *executionContext) marshalNTime2timeᚐTime(ctx context.Context, sel as
 	return res
 }
 
-func (ec *executionContext) marshalNToken2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐToken(ctx context.Context, sel ast.SelectionSet, v model.Token) graphql.Marshaler {
+func (ec *executionContext) marshalNToken2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐToken(ctx context.Context, sel ast.SelectionSet, v model.Token) graphql.Marshaler {
 	return ec._Token(ctx, sel, &v)
 }
 
-func (ec *executionContext) marshalNToken2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐToken(ctx context.Context, sel ast.SelectionSet, v *model.Token) graphql.Marshaler {
+func (ec *executionContext) marshalNToken2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐToken(ctx context.Context, sel ast.SelectionSet, v *model.Token) graphql.Marshaler {
 	if v == nil {
 		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
 			ec.Errorf(ctx, "must not be null")
@@ -5560,16 +8892,30 @@ func (ec *executionContext) marshalNToken2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgra
 	return ec._Token(ctx, sel, v)
 }
 
-func (ec *executionContext) unmarshalNTypeEnum2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐTypeEnum(ctx context.Context, v interface{}) (model.TypeEnum, error) {
+func (ec *executionContext) marshalNTwoFactorEnrollment2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐTwoFactorEnrollment(ctx context.Context, sel ast.SelectionSet, v model.TwoFactorEnrollment) graphql.Marshaler {
+	return ec._TwoFactorEnrollment(ctx, sel, &v)
+}
+
+func (ec *executionContext) marshalNTwoFactorEnrollment2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐTwoFactorEnrollment(ctx context.Context, sel ast.SelectionSet, v *model.TwoFactorEnrollment) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	return ec._TwoFactorEnrollment(ctx, sel, v)
+}
+
+func (ec *executionContext) unmarshalNTypeEnum2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐTypeEnum(ctx context.Context, v interface{}) (model.TypeEnum, error) {
 	var res model.TypeEnum
 	return res, res.UnmarshalGQL(v)
 }
 
-func (ec *executionContext) marshalNTypeEnum2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐTypeEnum(ctx context.Context, sel ast.SelectionSet, v model.TypeEnum) graphql.Marshaler {
+func (ec *executionContext) marshalNTypeEnum2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐTypeEnum(ctx context.Context, sel ast.SelectionSet, v model.TypeEnum) graphql.Marshaler {
 	return v
 }
 
-func (ec *executionContext) unmarshalNUpdateUserInput2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐUpdateUserInput(ctx context.Context, v interface{}) (model.UpdateUserInput, error) {
+func (ec *executionContext) unmarshalNUpdateUserInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐUpdateUserInput(ctx context.Context, v interface{}) (model.UpdateUserInput, error) {
 	return ec.unmarshalInputUpdateUserInput(ctx, v)
 }
 
@@ -5587,11 +8933,11 @@ func (ec *executionContext) marshalNUpload2githubᚗcomᚋ99designsᚋgqlgenᚋg
 	return res
 }
 
-func (ec *executionContext) marshalNUser2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐUser(ctx context.Context, sel ast.SelectionSet, v model.User) graphql.Marshaler {
+func (ec *executionContext) marshalNUser2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐUser(ctx context.Context, sel ast.SelectionSet, v model.User) graphql.Marshaler {
 	return ec._User(ctx, sel, &v)
 }
 
-func (ec *executionContext) marshalNUser2ᚕᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐUser(ctx context.Context, sel ast.SelectionSet, v []*model.User) graphql.Marshaler {
+func (ec *executionContext) marshalNUser2ᚕᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐUser(ctx context.Context, sel ast.SelectionSet, v []*model.User) graphql.Marshaler {
 	ret := make(graphql.Array, len(v))
 	var wg sync.WaitGroup
 	isLen1 := len(v) == 1
@@ -5615,7 +8961,7 @@ func (ec *executionContext) marshalNUser2ᚕᚖgithubᚗcomᚋjuleurᚋecrpeᚋg
 			if !isLen1 {
 				defer wg.Done()
 			}
-			ret[i] = ec.marshalOUser2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐUser(ctx, sel, v[i])
+			ret[i] = ec.marshalOUser2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐUser(ctx, sel, v[i])
 		}
 		if isLen1 {
 			f(i)
@@ -5628,7 +8974,7 @@ func (ec *executionContext) marshalNUser2ᚕᚖgithubᚗcomᚋjuleurᚋecrpeᚋg
 	return ret
 }
 
-func (ec *executionContext) marshalNUser2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐUser(ctx context.Context, sel ast.SelectionSet, v *model.User) graphql.Marshaler {
+func (ec *executionContext) marshalNUser2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐUser(ctx context.Context, sel ast.SelectionSet, v *model.User) graphql.Marshaler {
 	if v == nil {
 		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
 			ec.Errorf(ctx, "must not be null")
@@ -5638,11 +8984,15 @@ func (ec *executionContext) marshalNUser2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgrap
 	return ec._User(ctx, sel, v)
 }
 
-func (ec *executionContext) marshalNVideo2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐVideo(ctx context.Context, sel ast.SelectionSet, v model.Video) graphql.Marshaler {
+func (ec *executionContext) unmarshalNVerifyTwoFactorInput2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐVerifyTwoFactorInput(ctx context.Context, v interface{}) (model.VerifyTwoFactorInput, error) {
+	return ec.unmarshalInputVerifyTwoFactorInput(ctx, v)
+}
+
+func (ec *executionContext) marshalNVideo2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐVideo(ctx context.Context, sel ast.SelectionSet, v model.Video) graphql.Marshaler {
 	return ec._Video(ctx, sel, &v)
 }
 
-func (ec *executionContext) marshalNVideo2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐVideo(ctx context.Context, sel ast.SelectionSet, v *model.Video) graphql.Marshaler {
+func (ec *executionContext) marshalNVideo2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐVideo(ctx context.Context, sel ast.SelectionSet, v *model.Video) graphql.Marshaler {
 	if v == nil {
 		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
 			ec.Errorf(ctx, "must not be null")
@@ -5901,11 +9251,11 @@ func (ec *executionContext) marshalOBoolean2ᚖbool(ctx context.Context, sel ast
 	return ec.marshalOBoolean2bool(ctx, sel, *v)
 }
 
-func (ec *executionContext) unmarshalODocUploadFile2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐDocUploadFile(ctx context.Context, v interface{}) (model.DocUploadFile, error) {
+func (ec *executionContext) unmarshalODocUploadFile2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐDocUploadFile(ctx context.Context, v interface{}) (model.DocUploadFile, error) {
 	return ec.unmarshalInputDocUploadFile(ctx, v)
 }
 
-func (ec *executionContext) unmarshalODocUploadFile2ᚕᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐDocUploadFile(ctx context.Context, v interface{}) ([]*model.DocUploadFile, error) {
+func (ec *executionContext) unmarshalODocUploadFile2ᚕᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐDocUploadFile(ctx context.Context, v interface{}) ([]*model.DocUploadFile, error) {
 	var vSlice []interface{}
 	if v != nil {
 		if tmp1, ok := v.([]interface{}); ok {
@@ -5917,7 +9267,7 @@ func (ec *executionContext) unmarshalODocUploadFile2ᚕᚖgithubᚗcomᚋjuleur
 	var err error
 	res := make([]*model.DocUploadFile, len(vSlice))
 	for i := range vSlice {
-		res[i], err = ec.unmarshalODocUploadFile2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐDocUploadFile(ctx, vSlice[i])
+		res[i], err = ec.unmarshalODocUploadFile2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐDocUploadFile(ctx, vSlice[i])
 		if err != nil {
 			return nil, err
 		}
@@ -5925,11 +9275,11 @@ func (ec *executionContext) unmarshalODocUploadFile2ᚕᚖgithubᚗcomᚋjuleur
 	return res, nil
 }
 
-func (ec *executionContext) unmarshalODocUploadFile2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐDocUploadFile(ctx context.Context, v interface{}) (*model.DocUploadFile, error) {
+func (ec *executionContext) unmarshalODocUploadFile2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐDocUploadFile(ctx context.Context, v interface{}) (*model.DocUploadFile, error) {
 	if v == nil {
 		return nil, nil
 	}
-	res, err := ec.unmarshalODocUploadFile2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐDocUploadFile(ctx, v)
+	res, err := ec.unmarshalODocUploadFile2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐDocUploadFile(ctx, v)
 	return &res, err
 }
 
@@ -5979,52 +9329,121 @@ func (ec *executionContext) marshalOInt2ᚖint(ctx context.Context, sel ast.Sele
 	return ec.marshalOInt2int(ctx, sel, *v)
 }
 
-func (ec *executionContext) marshalORefresherCourse2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐRefresherCourse(ctx context.Context, sel ast.SelectionSet, v model.RefresherCourse) graphql.Marshaler {
+func (ec *executionContext) marshalORefresherCourse2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRefresherCourse(ctx context.Context, sel ast.SelectionSet, v model.RefresherCourse) graphql.Marshaler {
 	return ec._RefresherCourse(ctx, sel, &v)
 }
 
-func (ec *executionContext) marshalORefresherCourse2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐRefresherCourse(ctx context.Context, sel ast.SelectionSet, v *model.RefresherCourse) graphql.Marshaler {
+func (ec *executionContext) marshalORefresherCourse2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRefresherCourse(ctx context.Context, sel ast.SelectionSet, v *model.RefresherCourse) graphql.Marshaler {
 	if v == nil {
 		return graphql.Null
 	}
 	return ec._RefresherCourse(ctx, sel, v)
 }
 
-func (ec *executionContext) unmarshalOSectionEnum2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSectionEnum(ctx context.Context, v interface{}) (model.SectionEnum, error) {
+func (ec *executionContext) unmarshalORole2ᚕgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRoleᚄ(ctx context.Context, v interface{}) ([]model.Role, error) {
+	var vSlice []interface{}
+	if v != nil {
+		if tmp1, ok := v.([]interface{}); ok {
+			vSlice = tmp1
+		} else {
+			vSlice = []interface{}{v}
+		}
+	}
+	var err error
+	res := make([]model.Role, len(vSlice))
+	for i := range vSlice {
+		res[i], err = ec.unmarshalNRole2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRole(ctx, vSlice[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (ec *executionContext) marshalORole2ᚕgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRoleᚄ(ctx context.Context, sel ast.SelectionSet, v []model.Role) graphql.Marshaler {
+	if v == nil {
+		return graphql.Null
+	}
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNRole2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐRole(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+	return ret
+}
+
+func (ec *executionContext) unmarshalOSectionEnum2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSectionEnum(ctx context.Context, v interface{}) (model.SectionEnum, error) {
 	var res model.SectionEnum
 	return res, res.UnmarshalGQL(v)
 }
 
-func (ec *executionContext) marshalOSectionEnum2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSectionEnum(ctx context.Context, sel ast.SelectionSet, v model.SectionEnum) graphql.Marshaler {
+func (ec *executionContext) marshalOSectionEnum2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSectionEnum(ctx context.Context, sel ast.SelectionSet, v model.SectionEnum) graphql.Marshaler {
 	return v
 }
 
-func (ec *executionContext) unmarshalOSectionEnum2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSectionEnum(ctx context.Context, v interface{}) (*model.SectionEnum, error) {
+func (ec *executionContext) unmarshalOSectionEnum2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSectionEnum(ctx context.Context, v interface{}) (*model.SectionEnum, error) {
 	if v == nil {
 		return nil, nil
 	}
-	res, err := ec.unmarshalOSectionEnum2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSectionEnum(ctx, v)
+	res, err := ec.unmarshalOSectionEnum2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSectionEnum(ctx, v)
 	return &res, err
 }
 
-func (ec *executionContext) marshalOSectionEnum2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSectionEnum(ctx context.Context, sel ast.SelectionSet, v *model.SectionEnum) graphql.Marshaler {
+func (ec *executionContext) marshalOSectionEnum2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSectionEnum(ctx context.Context, sel ast.SelectionSet, v *model.SectionEnum) graphql.Marshaler {
 	if v == nil {
 		return graphql.Null
 	}
 	return v
 }
 
-func (ec *executionContext) marshalOSession2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSession(ctx context.Context, sel ast.SelectionSet, v model.Session) graphql.Marshaler {
+func (ec *executionContext) marshalOSession2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSession(ctx context.Context, sel ast.SelectionSet, v model.Session) graphql.Marshaler {
 	return ec._Session(ctx, sel, &v)
 }
 
-func (ec *executionContext) marshalOSession2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSession(ctx context.Context, sel ast.SelectionSet, v *model.Session) graphql.Marshaler {
+func (ec *executionContext) marshalOSession2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSession(ctx context.Context, sel ast.SelectionSet, v *model.Session) graphql.Marshaler {
 	if v == nil {
 		return graphql.Null
 	}
 	return ec._Session(ctx, sel, v)
 }
 
+func (ec *executionContext) unmarshalOSessionOriginEnum2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSessionOriginEnum(ctx context.Context, v interface{}) (model.SessionOriginEnum, error) {
+	var res model.SessionOriginEnum
+	return res, res.UnmarshalGQL(v)
+}
+
+func (ec *executionContext) marshalOSessionOriginEnum2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSessionOriginEnum(ctx context.Context, sel ast.SelectionSet, v model.SessionOriginEnum) graphql.Marshaler {
+	return v
+}
+
 func (ec *executionContext) unmarshalOString2string(ctx context.Context, v interface{}) (string, error) {
 	return graphql.UnmarshalString(v)
 }
@@ -6048,24 +9467,24 @@ func (ec *executionContext) marshalOString2ᚖstring(ctx context.Context, sel as
 	return ec.marshalOString2string(ctx, sel, *v)
 }
 
-func (ec *executionContext) unmarshalOSubjectEnum2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSubjectEnum(ctx context.Context, v interface{}) (model.SubjectEnum, error) {
+func (ec *executionContext) unmarshalOSubjectEnum2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSubjectEnum(ctx context.Context, v interface{}) (model.SubjectEnum, error) {
 	var res model.SubjectEnum
 	return res, res.UnmarshalGQL(v)
 }
 
-func (ec *executionContext) marshalOSubjectEnum2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSubjectEnum(ctx context.Context, sel ast.SelectionSet, v model.SubjectEnum) graphql.Marshaler {
+func (ec *executionContext) marshalOSubjectEnum2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSubjectEnum(ctx context.Context, sel ast.SelectionSet, v model.SubjectEnum) graphql.Marshaler {
 	return v
 }
 
-func (ec *executionContext) unmarshalOSubjectEnum2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSubjectEnum(ctx context.Context, v interface{}) (*model.SubjectEnum, error) {
+func (ec *executionContext) unmarshalOSubjectEnum2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSubjectEnum(ctx context.Context, v interface{}) (*model.SubjectEnum, error) {
 	if v == nil {
 		return nil, nil
 	}
-	res, err := ec.unmarshalOSubjectEnum2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSubjectEnum(ctx, v)
+	res, err := ec.unmarshalOSubjectEnum2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSubjectEnum(ctx, v)
 	return &res, err
 }
 
-func (ec *executionContext) marshalOSubjectEnum2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐSubjectEnum(ctx context.Context, sel ast.SelectionSet, v *model.SubjectEnum) graphql.Marshaler {
+func (ec *executionContext) marshalOSubjectEnum2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐSubjectEnum(ctx context.Context, sel ast.SelectionSet, v *model.SubjectEnum) graphql.Marshaler {
 	if v == nil {
 		return graphql.Null
 	}
@@ -6095,35 +9514,35 @@ func (ec *executionContext) marshalOTime2ᚖtimeᚐTime(ctx context.Context, sel
 	return ec.marshalOTime2timeᚐTime(ctx, sel, *v)
 }
 
-func (ec *executionContext) unmarshalOTypeEnum2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐTypeEnum(ctx context.Context, v interface{}) (model.TypeEnum, error) {
+func (ec *executionContext) unmarshalOTypeEnum2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐTypeEnum(ctx context.Context, v interface{}) (model.TypeEnum, error) {
 	var res model.TypeEnum
 	return res, res.UnmarshalGQL(v)
 }
 
-func (ec *executionContext) marshalOTypeEnum2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐTypeEnum(ctx context.Context, sel ast.SelectionSet, v model.TypeEnum) graphql.Marshaler {
+func (ec *executionContext) marshalOTypeEnum2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐTypeEnum(ctx context.Context, sel ast.SelectionSet, v model.TypeEnum) graphql.Marshaler {
 	return v
 }
 
-func (ec *executionContext) unmarshalOTypeEnum2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐTypeEnum(ctx context.Context, v interface{}) (*model.TypeEnum, error) {
+func (ec *executionContext) unmarshalOTypeEnum2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐTypeEnum(ctx context.Context, v interface{}) (*model.TypeEnum, error) {
 	if v == nil {
 		return nil, nil
 	}
-	res, err := ec.unmarshalOTypeEnum2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐTypeEnum(ctx, v)
+	res, err := ec.unmarshalOTypeEnum2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐTypeEnum(ctx, v)
 	return &res, err
 }
 
-func (ec *executionContext) marshalOTypeEnum2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐTypeEnum(ctx context.Context, sel ast.SelectionSet, v *model.TypeEnum) graphql.Marshaler {
+func (ec *executionContext) marshalOTypeEnum2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐTypeEnum(ctx context.Context, sel ast.SelectionSet, v *model.TypeEnum) graphql.Marshaler {
 	if v == nil {
 		return graphql.Null
 	}
 	return v
 }
 
-func (ec *executionContext) marshalOUser2githubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐUser(ctx context.Context, sel ast.SelectionSet, v model.User) graphql.Marshaler {
+func (ec *executionContext) marshalOUser2githubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐUser(ctx context.Context, sel ast.SelectionSet, v model.User) graphql.Marshaler {
 	return ec._User(ctx, sel, &v)
 }
 
-func (ec *executionContext) marshalOUser2ᚖgithubᚗcomᚋjuleurᚋecrpeᚋgraphᚋmodelᚐUser(ctx context.Context, sel ast.SelectionSet, v *model.User) graphql.Marshaler {
+func (ec *executionContext) marshalOUser2ᚖgithubᚗcomᚋjuleurᚋbecrpeᚋgraphᚋmodelᚐUser(ctx context.Context, sel ast.SelectionSet, v *model.User) graphql.Marshaler {
 	if v == nil {
 		return graphql.Null
 	}
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+)
+
+// DeviceSession is a refresh token family seen by its user,
+// one per device logged in, ID is the family id
+type DeviceSession struct {
+	ID          int               `json:"id"`
+	UserAgent   string            `json:"userAgent"`
+	IPAddress   string            `json:"ipAddress"`
+	DeliveredAt time.Time         `json:"deliveredAt"`
+	Origin      SessionOriginEnum `json:"origin"`
+	Current     bool              `json:"current"`
+}
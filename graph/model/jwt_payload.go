@@ -7,5 +7,9 @@ type CustomPayload struct {
 	jwt.Payload
 	Username string `json:"username"`
 	UserID   int    `json:"userId"`
-	Teacher  bool   `json:"teacher"`
+	// Roles and Permissions are Role.Name and Permission.Name values
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	// SessionID is the refresh token family, 0 in tokens issued before families
+	SessionID int `json:"sid,omitempty"`
 }
@@ -0,0 +1,8 @@
+package model
+
+// Me is the authenticated user, its other fields are only resolved for them
+type Me struct {
+	User *User `json:"user"`
+	// SessionID is the device session of the JWT, see DeviceSession.Current
+	SessionID int `json:"-"`
+}
@@ -11,11 +11,25 @@ import (
 	"github.com/99designs/gqlgen/graphql"
 )
 
+type ConfirmTwoFactorInput struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+type DeleteAccountInput struct {
+	Password      string  `json:"password"`
+	TwoFactorCode *string `json:"twoFactorCode"`
+}
+
 type DocUploadFile struct {
 	Title *string        `json:"title"`
 	File  graphql.Upload `json:"file"`
 }
 
+type EnableTwoFactorInput struct {
+	Password string `json:"password"`
+}
+
 type LoginInput struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
@@ -69,6 +83,11 @@ type RefresherCourseResponse struct {
 	Sessions        []*Session       `json:"sessions"`
 }
 
+type ResetPasswordInput struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
 type Session struct {
 	ID            string       `json:"id" db:"id"`
 	Title         *string      `json:"title" db:"title"`
@@ -82,9 +101,9 @@ type Session struct {
 }
 
 type SessionInput struct {
-	UserID            int `json:"userId"`
-	RefresherCourseID int `json:"refresherCourseId"`
-	SessionID         int `json:"sessionId"`
+	UserID            *int `json:"userId"`
+	RefresherCourseID int  `json:"refresherCourseId"`
+	SessionID         int  `json:"sessionId"`
 }
 
 type SessionResponse struct {
@@ -94,6 +113,11 @@ type SessionResponse struct {
 	Teacher     *User         `json:"teacher"`
 }
 
+type TwoFactorEnrollment struct {
+	OtpauthURI string `json:"otpauthUri"`
+	Secret     string `json:"secret"`
+}
+
 type UpdateUserInput struct {
 	Username *string `json:"username"`
 	Email    *string `json:"email"`
@@ -101,6 +125,99 @@ type UpdateUserInput struct {
 	Password string  `json:"password"`
 }
 
+type VerifyTwoFactorInput struct {
+	Challenge string `json:"challenge"`
+	Code      string `json:"code"`
+}
+
+type Permission string
+
+const (
+	PermissionCoursePublish Permission = "COURSE_PUBLISH"
+	PermissionUserRead      Permission = "USER_READ"
+	PermissionRoleManage    Permission = "ROLE_MANAGE"
+)
+
+var AllPermission = []Permission{
+	PermissionCoursePublish,
+	PermissionUserRead,
+	PermissionRoleManage,
+}
+
+func (e Permission) IsValid() bool {
+	switch e {
+	case PermissionCoursePublish, PermissionUserRead, PermissionRoleManage:
+		return true
+	}
+	return false
+}
+
+func (e Permission) String() string {
+	return string(e)
+}
+
+func (e *Permission) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Permission(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Permission", str)
+	}
+	return nil
+}
+
+func (e Permission) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type Role string
+
+const (
+	RoleStudent Role = "STUDENT"
+	RoleTeacher Role = "TEACHER"
+	RoleAdmin   Role = "ADMIN"
+	RoleSupport Role = "SUPPORT"
+)
+
+var AllRole = []Role{
+	RoleStudent,
+	RoleTeacher,
+	RoleAdmin,
+	RoleSupport,
+}
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleStudent, RoleTeacher, RoleAdmin, RoleSupport:
+		return true
+	}
+	return false
+}
+
+func (e Role) String() string {
+	return string(e)
+}
+
+func (e *Role) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Role(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Role", str)
+	}
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type SectionEnum string
 
 const (
@@ -142,6 +259,47 @@ func (e SectionEnum) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type SessionOriginEnum string
+
+const (
+	SessionOriginEnumLogin   SessionOriginEnum = "LOGIN"
+	SessionOriginEnumRefresh SessionOriginEnum = "REFRESH"
+)
+
+var AllSessionOriginEnum = []SessionOriginEnum{
+	SessionOriginEnumLogin,
+	SessionOriginEnumRefresh,
+}
+
+func (e SessionOriginEnum) IsValid() bool {
+	switch e {
+	case SessionOriginEnumLogin, SessionOriginEnumRefresh:
+		return true
+	}
+	return false
+}
+
+func (e SessionOriginEnum) String() string {
+	return string(e)
+}
+
+func (e *SessionOriginEnum) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SessionOriginEnum(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SessionOriginEnum", str)
+	}
+	return nil
+}
+
+func (e SessionOriginEnum) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type SubjectEnum string
 
 const (
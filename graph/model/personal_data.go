@@ -0,0 +1,128 @@
+package model
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PersonalData is everything stored about a user, exportMyData writes each
+// field in its own JSON file of the archive
+type PersonalData struct {
+	Account          *PersonalAccount         `json:"account"`
+	Roles            []string                 `json:"roles"`
+	DeviceSessions   []*PersonalDeviceSession `json:"deviceSessions"`
+	Purchases        []*PersonalPurchase      `json:"purchases"`
+	SessionViews     []*PersonalSessionView   `json:"sessionViews"`
+	PasswordResets   []*PersonalPasswordReset `json:"passwordResets"`
+	TwoFactor        *PersonalTwoFactor       `json:"twoFactor"`
+	AuthoredSessions []*PersonalSession       `json:"authoredSessions"`
+}
+
+// PersonalAccount is the users row without the password hash and TOTP secret
+type PersonalAccount struct {
+	ID              int        `json:"id" db:"id"`
+	Username        string     `json:"username" db:"username"`
+	Fullname        *string    `json:"fullname" db:"fullname"`
+	Email           string     `json:"email" db:"email"`
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt" db:"email_verified_at"`
+	CreatedAt       time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt       *time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// PersonalDeviceSession is a refresh token delivered to the user, without its hash
+type PersonalDeviceSession struct {
+	UserAgent   string     `json:"userAgent" db:"user_agent"`
+	IPAddress   string     `json:"ipAddress" db:"ip_address"`
+	DeliveredAt time.Time  `json:"deliveredAt" db:"delivered_at"`
+	OnLogin     bool       `json:"onLogin" db:"on_login"`
+	RevokedAt   *time.Time `json:"revokedAt" db:"revoked_at"`
+}
+
+// PersonalPurchase is a course the user was enrolled in with its PayPal
+// payment, the payment fields are empty for granted courses
+type PersonalPurchase struct {
+	RefresherCourseID int        `json:"refresherCourseId" db:"refresher_course_id"`
+	Subject           string     `json:"subject" db:"subject"`
+	Year              string     `json:"year" db:"year"`
+	Price             *float64   `json:"price" db:"price"`
+	PaypalPayerID     *string    `json:"paypalPayerId" db:"paypal_payer_id"`
+	PaypalOrderID     *string    `json:"paypalOrderId" db:"paypal_order_id"`
+	PaidAt            *time.Time `json:"paidAt" db:"paid_at"`
+}
+
+// PersonalSessionView is a session the user opened
+type PersonalSessionView struct {
+	SessionID     int       `json:"sessionId" db:"session_id"`
+	Title         string    `json:"title" db:"title"`
+	FirstViewedAt time.Time `json:"firstViewedAt" db:"first_viewed_at"`
+	LastViewedAt  time.Time `json:"lastViewedAt" db:"last_viewed_at"`
+}
+
+// PersonalPasswordReset is a password reset asked by the user, without its token
+type PersonalPasswordReset struct {
+	CreatedAt time.Time  `json:"createdAt" db:"created_at"`
+	ExpiresAt time.Time  `json:"expiresAt" db:"expires_at"`
+	UsedAt    *time.Time `json:"usedAt" db:"used_at"`
+}
+
+// PersonalTwoFactor tells whether two-factor authentication is enabled and
+// how many recovery codes are left, never the secret nor the codes
+type PersonalTwoFactor struct {
+	EnabledAt              *time.Time `json:"enabledAt" db:"totp_enabled_at"`
+	RecoveryCodes          int        `json:"recoveryCodes" db:"recovery_codes"`
+	RecoveryCodesRemaining int        `json:"recoveryCodesRemaining" db:"recovery_codes_remaining"`
+}
+
+// PersonalSession is a session the user, a teacher, recorded
+type PersonalSession struct {
+	ID                int       `json:"id" db:"id"`
+	Title             string    `json:"title" db:"title"`
+	RefresherCourseID int       `json:"refresherCourseId" db:"refresher_course_id"`
+	CreatedAt         time.Time `json:"createdAt" db:"created_at"`
+}
+
+// Archive returns the ZIP of exportMyData, one JSON file per field
+func (d *PersonalData) Archive() ([]byte, error) {
+	files := []struct {
+		name    string
+		content interface{}
+	}{
+		{"account.json", d.Account},
+		{"roles.json", d.Roles},
+		{"device_sessions.json", d.DeviceSessions},
+		{"purchases.json", d.Purchases},
+		{"session_views.json", d.SessionViews},
+		{"password_resets.json", d.PasswordResets},
+		{"two_factor.json", d.TwoFactor},
+		{"authored_sessions.json", d.AuthoredSessions},
+	}
+	buf := bytes.Buffer{}
+	zw := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := zw.Create(file.name)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(file.content); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf.Bytes(), nil
+}
+
+// DataExport is the archive of exportMyData
+type DataExport struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	// Content is the base64 of the archive
+	Content string `json:"content"`
+}
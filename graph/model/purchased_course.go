@@ -0,0 +1,14 @@
+package model
+
+import (
+	"time"
+)
+
+// PurchasedCourse is a refresher course bought by a user with their progress,
+// the sessions counted are the ready ones
+type PurchasedCourse struct {
+	RefresherCourse *RefresherCourse `json:"refresherCourse" db:"refresher_course"`
+	PurchasedAt     *time.Time       `json:"purchasedAt" db:"purchased_at"`
+	ViewedSessions  int              `json:"viewedSessions" db:"viewed_sessions"`
+	TotalSessions   int              `json:"totalSessions" db:"total_sessions"`
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RoleNames are the role names of a user, loaded with the user from the
+// GROUP_CONCAT of the repository queries
+type RoleNames []string
+
+// Scan implements sql.Scanner, NULL is a user without role
+func (n *RoleNames) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*n = RoleNames{}
+	case []byte:
+		*n = strings.Split(string(v), ",")
+	case string:
+		*n = strings.Split(v, ",")
+	default:
+		return errors.Errorf("model: cannot scan %T into RoleNames", src)
+	}
+	return nil
+}
+
+// Name is the name of the role in the database and the JWT claims
+func (e Role) Name() string {
+	return strings.ToLower(string(e))
+}
+
+// Name is the name of the permission in the database and the JWT claims
+func (e Permission) Name() string {
+	return strings.ToLower(string(e))
+}
+
+// RoleFromName is the reverse of Role.Name
+func RoleFromName(name string) Role {
+	return Role(strings.ToUpper(name))
+}
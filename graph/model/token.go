@@ -3,4 +3,6 @@ package model
 type Token struct {
 	Jwt          string `json:"jwt"`
 	RefreshToken string `json:"refreshToken"`
+	// TwoFactorChallenge replaces the tokens until the TOTP code is verified
+	TwoFactorChallenge *string `json:"twoFactorChallenge"`
 }
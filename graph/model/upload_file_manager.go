@@ -1,7 +1,7 @@
 package model
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,144 +10,468 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 
 	"github.com/99designs/gqlgen/graphql"
 	"github.com/jmoiron/sqlx"
+	"github.com/juleur/becrpe/config"
+	"github.com/juleur/becrpe/tracing"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/text/runes"
 	"golang.org/x/text/transform"
 	"golang.org/x/text/unicode/norm"
 )
 
+// PackagingTools are the binaries shelled out by ProcessVideo
+var PackagingTools = []string{"mp4fragment", "mp4dash", "ffprobe"}
+
+// UploadQueueStats is a snapshot of the upload pipeline load, QueuedJobs
+// wait for one of the MaxRunningJobs slots
+type UploadQueueStats struct {
+	RunningJobs    int `json:"runningJobs"`
+	QueuedJobs     int `json:"queuedJobs"`
+	MaxRunningJobs int `json:"maxRunningJobs"`
+	MaxQueuedJobs  int `json:"maxQueuedJobs"`
+}
+
+// Saturated is true once the jobs started fill every slot and the queue
+func (s UploadQueueStats) Saturated() bool {
+	return s.RunningJobs+s.QueuedJobs >= s.MaxRunningJobs+s.MaxQueuedJobs
+}
+
 type UploadFileManager struct {
-	VideoCh      chan Video
-	ClassPaperCh chan ClassPaper
-	Logger       *logrus.Logger
-	DB           *sqlx.DB
+	Logger  *logrus.Logger
+	DB      *sqlx.DB
+	Storage config.Storage
+	// StageObserver is called after each pipeline stage when set
+	StageObserver func(stage string, duration time.Duration, err error)
+
+	// ctx is cancelled when Shutdown deadline is reached, it kills
+	// running packaging commands and storage requests
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	closing bool
+	// jobs holds the started jobs, running holds a slot for each running one
+	jobs    map[*UploadJob]struct{}
+	running chan struct{}
 }
 
-func NewUploadFileManager(db *sqlx.DB, logger *logrus.Logger) *UploadFileManager {
+func NewUploadFileManager(db *sqlx.DB, logger *logrus.Logger, storage config.Storage) (*UploadFileManager, error) {
+	if err := os.MkdirAll(storage.SpoolDir, 0750); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	slots := storage.MaxRunningJobs
+	if slots < 1 {
+		slots = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
 	ufm := &UploadFileManager{
-		VideoCh:      make(chan Video, 50),
-		ClassPaperCh: make(chan ClassPaper, 50),
-		DB:           db,
-		Logger:       logger,
+		DB:      db,
+		Logger:  logger,
+		Storage: storage,
+		ctx:     ctx,
+		cancel:  cancel,
+		jobs:    make(map[*UploadJob]struct{}),
+		running: make(chan struct{}, slots),
 	}
-	return ufm
+	return ufm, nil
 }
 
-func (ufm *UploadFileManager) DoneProcesses() {
-	for {
-		select {
-		case videoDone := <-ufm.VideoCh:
-			if _, err := ufm.DB.Exec(
-				"INSERT INTO videos (path, duration, created_at, session_id) VALUES (?,?,?,?)",
-				videoDone.Path, videoDone.Duration, time.Now(), videoDone.SessionID,
-			); err != nil {
-				ufm.Logger.Errorln(err)
-			}
-			if _, err := ufm.DB.Queryx(`
-				UPDATE sessions SET is_ready = 1, updated_at = ? WHERE id = ?
-			`, time.Now(), videoDone.SessionID); err != nil {
-				ufm.Logger.Errorln(err)
-			}
-		case cpDone := <-ufm.ClassPaperCh:
-			if _, err := ufm.DB.Exec(
-				"INSERT INTO class_papers (title, path, created_at, session_id) VALUES (?,?,?,?)",
-				cpDone.Title, cpDone.Path, time.Now(), cpDone.SessionID,
-			); err != nil {
-				ufm.Logger.Errorln(err)
+// QueueStats returns the current load of the pipeline
+func (ufm *UploadFileManager) QueueStats() UploadQueueStats {
+	ufm.mu.Lock()
+	defer ufm.mu.Unlock()
+	running := len(ufm.running)
+	// a job leaves jobs just before releasing its slot
+	queued := len(ufm.jobs) - running
+	if queued < 0 {
+		queued = 0
+	}
+	return UploadQueueStats{
+		RunningJobs:    running,
+		QueuedJobs:     queued,
+		MaxRunningJobs: cap(ufm.running),
+		MaxQueuedJobs:  ufm.Storage.MaxQueuedJobs,
+	}
+}
+
+// Shutdown stops accepting jobs then waits for the running ones until ctx is done,
+// jobs still running at the deadline are killed, their upload_jobs rows stay
+// so ResumeJobs can restart them on next start
+func (ufm *UploadFileManager) Shutdown(ctx context.Context) error {
+	ufm.mu.Lock()
+	ufm.closing = true
+	ufm.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		ufm.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-ctx.Done():
+		ufm.cancel()
+		<-finished
+	}
+
+	ufm.mu.Lock()
+	defer ufm.mu.Unlock()
+	for job := range ufm.jobs {
+		ufm.Logger.Warnf("%s job of session n°%d interrupted, kept for resuming", job.Kind, job.SessionID)
+	}
+	return nil
+}
+
+// ResumeJobs restarts the jobs interrupted by the last Shutdown or a crash,
+// failed jobs wait for RequeueSession
+func (ufm *UploadFileManager) ResumeJobs() error {
+	_, err := ufm.resume(`
+		SELECT id, kind, session_id, dir_path, source_path, title, created_at, failed_at, error
+		FROM upload_jobs WHERE failed_at IS NULL ORDER BY id
+	`)
+	return err
+}
+
+// RequeueSession restarts the failed jobs of the session and returns how many
+// were started, the others may be running on the server
+func (ufm *UploadFileManager) RequeueSession(sessionID int) (int, error) {
+	return ufm.resume(`
+		SELECT id, kind, session_id, dir_path, source_path, title, created_at, failed_at, error
+		FROM upload_jobs WHERE session_id = ? AND failed_at IS NOT NULL ORDER BY id
+	`, sessionID)
+}
+
+func (ufm *UploadFileManager) resume(query string, args ...interface{}) (int, error) {
+	jobs := make([]*UploadJob, 0)
+	if err := ufm.DB.Select(&jobs, query, args...); err != nil {
+		return 0, errors.WithStack(err)
+	}
+	started := 0
+	for _, job := range jobs {
+		if _, err := os.Stat(job.SourcePath); err != nil {
+			ufm.Logger.Errorln(err)
+			if _, err := ufm.DB.Exec("DELETE FROM upload_jobs WHERE id = ?", job.ID); err != nil {
+				return started, errors.WithStack(err)
 			}
+			continue
+		}
+		// a crash while it runs again must resume it
+		if _, err := ufm.DB.Exec("UPDATE upload_jobs SET failed_at = NULL, error = NULL WHERE id = ?", job.ID); err != nil {
+			return started, errors.WithStack(err)
 		}
+		if err := ufm.start(job); err != nil {
+			return started, err
+		}
+		started++
+		ufm.Logger.Infof("%s job of session n°%d resumed", job.Kind, job.SessionID)
 	}
+	return started, nil
 }
 
-func (ufm *UploadFileManager) ProcessVideo(dirPath string, sessionID int, videoFile graphql.Upload, refCourse RefresherCourse) {
-	// video.xxxxxxx
-	videoTmpFile, err := ioutil.TempFile(os.TempDir(), "video.*")
+// stage runs fn in its own span then reports its duration to StageObserver
+func (ufm *UploadFileManager) stage(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	ctx, span := tracing.Tracer().Start(ctx, "upload."+name)
+	start := time.Now()
+	err := fn(ctx)
+	tracing.End(span, err)
+	if ufm.StageObserver != nil {
+		ufm.StageObserver(name, time.Since(start), err)
+	}
+	return err
+}
+
+// start registers job, saved in upload_jobs, then processes it in background
+func (ufm *UploadFileManager) start(job *UploadJob) error {
+	ufm.mu.Lock()
+	defer ufm.mu.Unlock()
+	if ufm.closing {
+		return errors.New("upload file manager is shutting down")
+	}
+	ufm.jobs[job] = struct{}{}
+	ufm.wg.Add(1)
+	go ufm.run(job)
+	return nil
+}
+
+// enqueue saves jobs in upload_jobs in one transaction then starts them, a
+// crash then loses none of them. Nothing is started when it fails.
+func (ufm *UploadFileManager) enqueue(jobs []*UploadJob) error {
+	ufm.mu.Lock()
+	closing := ufm.closing
+	ufm.mu.Unlock()
+	if closing {
+		return errors.New("upload file manager is shutting down")
+	}
+	tx, err := ufm.DB.Beginx()
 	if err != nil {
-		ufm.Logger.Errorln(err)
-		return
+		return errors.WithStack(err)
 	}
-	defer os.Remove(videoTmpFile.Name())
+	// no-op once committed
+	defer tx.Rollback()
+	for _, job := range jobs {
+		job.CreatedAt = time.Now()
+		res, err := tx.Exec(`
+			INSERT INTO upload_jobs (kind, session_id, dir_path, source_path, title, created_at) VALUES (?,?,?,?,?,?)
+		`, job.Kind, job.SessionID, job.DirPath, job.SourcePath, job.Title, job.CreatedAt)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		job.ID = int(id)
+	}
+	if err := tx.Commit(); err != nil {
+		return errors.WithStack(err)
+	}
+	for _, job := range jobs {
+		// only Shutdown refuses, the saved job is resumed on next start
+		if err := ufm.start(job); err != nil {
+			ufm.Logger.Warnf("%s job of session n°%d saved for resuming: %v", job.Kind, job.SessionID, err)
+		}
+	}
+	return nil
+}
 
-	fileBytes := bytes.Buffer{}
-	fileBytes.Grow(int(videoFile.Size))
-	if _, err = fileBytes.ReadFrom(videoFile.File); err != nil {
-		ufm.Logger.Errorln(err)
+func (ufm *UploadFileManager) run(job *UploadJob) {
+	defer ufm.wg.Done()
+	select {
+	case ufm.running <- struct{}{}:
+		defer func() { <-ufm.running }()
+	case <-ufm.ctx.Done():
+		// killed by Shutdown while queued, resumed on next start
 		return
 	}
-	if _, err := videoTmpFile.Write(fileBytes.Bytes()); err != nil {
+	// the job outlives the request, it is only cancelled by Shutdown
+	// but stays in the trace of the request which spooled it
+	ctx := trace.ContextWithSpanContext(ufm.ctx, job.parent)
+	ctx, span := tracing.Tracer().Start(ctx, "upload.job "+job.Kind, trace.WithAttributes(
+		attribute.Int("session.id", job.SessionID),
+		attribute.String("upload.dir_path", job.DirPath),
+	))
+	var err error
+	switch job.Kind {
+	case UploadJobVideo:
+		err = ufm.processVideo(ctx, job)
+	case UploadJobDoc:
+		err = ufm.processDoc(ctx, job)
+	default:
+		err = fmt.Errorf("unknown upload job kind %s", job.Kind)
+	}
+	tracing.End(span, err)
+	if err != nil {
 		ufm.Logger.Errorln(err)
-		return
+		// killed by Shutdown, keeps both job and spooled file for resuming
+		if ufm.ctx.Err() != nil {
+			return
+		}
 	}
-	// split /tmp/video.xxxxxxx
-	videoName := strings.Split(videoTmpFile.Name(), "/")
-	fragMP4 := fmt.Sprintf("mp4fragment --fragment-duration 4000 %s %s-f.mp4", videoTmpFile.Name(), videoTmpFile.Name())
-	cmd := exec.Command("bash", "-c", fragMP4)
-	if err := cmd.Run(); err != nil {
-		ufm.Logger.Error(err)
+	ufm.mu.Lock()
+	delete(ufm.jobs, job)
+	ufm.mu.Unlock()
+	if err != nil {
+		ufm.fail(job, err)
 		return
 	}
-	defer os.Remove(videoTmpFile.Name() + "-f.mp4")
+	os.Remove(job.SourcePath)
+}
 
-	dashMP4 := fmt.Sprintf("mp4dash --language-map=en:fr,und:fr --media-prefix %s --mpd-name %s.mpd --profiles on-demand --use-segment-timeline %s-f.mp4 -f -o %s", videoName[len(videoName)-1], videoName[len(videoName)-1], videoTmpFile.Name(), os.TempDir())
-	cmd = exec.Command("bash", "-c", dashMP4)
-	if err := cmd.Run(); err != nil {
+// finish saves what job produced and deletes its upload_jobs row in one
+// transaction, on error the row stays for ResumeJobs or RequeueSession
+func (ufm *UploadFileManager) finish(ctx context.Context, job *UploadJob, save func(tx *sqlx.Tx) error) error {
+	tx, err := ufm.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	// no-op once committed
+	defer tx.Rollback()
+	if err := save(tx); err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM upload_jobs WHERE id = ?", job.ID); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(tx.Commit())
+}
+
+// fail records the error in the upload_jobs row of job, the spooled file is
+// kept so the job can be requeued once the cause is fixed
+func (ufm *UploadFileManager) fail(job *UploadJob, jobErr error) {
+	msg := jobErr.Error()
+	if len(msg) > 255 {
+		msg = msg[:255]
+	}
+	if _, err := ufm.DB.Exec(`
+		UPDATE upload_jobs SET failed_at = ?, error = ? WHERE id = ?
+	`, time.Now(), msg, job.ID); err != nil {
 		ufm.Logger.Errorln(err)
-		return
 	}
-	defer os.Remove(videoTmpFile.Name() + "-video-avc1.mp4")
-	defer os.Remove(videoTmpFile.Name() + "-audio-fr-mp4a.mp4")
-	defer os.Remove(videoTmpFile.Name() + ".mpd")
+}
+
+// spool copies an uploaded file into the spool directory, uploaded files
+// are closed by the transport as soon as the request ends
+func (ufm *UploadFileManager) spool(pattern string, file io.Reader) (string, error) {
+	spoolFile, err := ioutil.TempFile(ufm.Storage.SpoolDir, pattern)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer spoolFile.Close()
+	if _, err := io.Copy(spoolFile, file); err != nil {
+		os.Remove(spoolFile.Name())
+		return "", errors.WithStack(err)
+	}
+	return spoolFile.Name(), nil
+}
+
+// ProcessSession spools the video and every docUploadFiles of a session then
+// processes them in background, either every job is saved or none is
+func (ufm *UploadFileManager) ProcessSession(ctx context.Context, dirPath string, sessionID int, videoFile graphql.Upload, docUploadFiles []*DocUploadFile) error {
+	job, err := ufm.videoJob(ctx, dirPath, sessionID, videoFile)
+	if err != nil {
+		return err
+	}
+	jobs := []*UploadJob{job}
+	docJobs, err := ufm.docJobs(ctx, dirPath, sessionID, docUploadFiles)
+	jobs = append(jobs, docJobs...)
+	if err == nil {
+		err = ufm.enqueue(jobs)
+	}
+	if err != nil {
+		removeSpooled(jobs)
+		return err
+	}
+	return nil
+}
+
+// ProcessVideo spools videoFile then packages it for DASH and sends it to storage in background
+func (ufm *UploadFileManager) ProcessVideo(ctx context.Context, dirPath string, sessionID int, videoFile graphql.Upload) error {
+	job, err := ufm.videoJob(ctx, dirPath, sessionID, videoFile)
+	if err != nil {
+		return err
+	}
+	if err := ufm.enqueue([]*UploadJob{job}); err != nil {
+		removeSpooled([]*UploadJob{job})
+		return err
+	}
+	return nil
+}
+
+func (ufm *UploadFileManager) videoJob(ctx context.Context, dirPath string, sessionID int, videoFile graphql.Upload) (*UploadJob, error) {
+	// video.xxxxxxx
+	sourcePath, err := ufm.spool("video.*", videoFile.File)
+	if err != nil {
+		return nil, err
+	}
+	return &UploadJob{
+		Kind:       UploadJobVideo,
+		SessionID:  sessionID,
+		DirPath:    dirPath,
+		SourcePath: sourcePath,
+		parent:     trace.SpanContextFromContext(ctx),
+	}, nil
+}
+
+func (ufm *UploadFileManager) processVideo(ctx context.Context, job *UploadJob) error {
+	// split /spool/video.xxxxxxx
+	videoName := filepath.Base(job.SourcePath)
+	fragmentedPath := job.SourcePath + "-f.mp4"
+	if err := ufm.stage(ctx, "mp4fragment", func(ctx context.Context) error {
+		return exec.CommandContext(ctx, "mp4fragment", "--fragment-duration", "4000", job.SourcePath, fragmentedPath).Run()
+	}); err != nil {
+		return errors.Wrap(err, "mp4fragment")
+	}
+	defer os.Remove(fragmentedPath)
+
+	dashDir := job.SourcePath + "-dash"
+	if err := ufm.stage(ctx, "mp4dash", func(ctx context.Context) error {
+		return exec.CommandContext(ctx, "mp4dash",
+			"--language-map=en:fr,und:fr", "--media-prefix", videoName, "--mpd-name", videoName+".mpd",
+			"--profiles", "on-demand", "--use-segment-timeline", fragmentedPath, "-f", "-o", dashDir,
+		).Run()
+	}); err != nil {
+		return errors.Wrap(err, "mp4dash")
+	}
+	defer os.RemoveAll(dashDir)
 
 	argsFfprobe := []string{
 		"-show_entries", "format=duration", "-v", "quiet", "-of", "csv=p=0",
-		"-sexagesimal", videoTmpFile.Name(),
+		"-sexagesimal", job.SourcePath,
 	}
-	durVideo, err := exec.Command("ffprobe", argsFfprobe...).Output()
-	if err != nil {
-		ufm.Logger.Errorln(err)
-		return
+	var durVideo []byte
+	if err := ufm.stage(ctx, "ffprobe", func(ctx context.Context) (err error) {
+		durVideo, err = exec.CommandContext(ctx, "ffprobe", argsFfprobe...).Output()
+		return err
+	}); err != nil {
+		return errors.Wrap(err, "ffprobe")
 	}
-	videoFluxFile, err := os.OpenFile(videoTmpFile.Name()+"-video-avc1.mp4", os.O_RDONLY, 0444)
+	videoFluxFile, err := os.OpenFile(filepath.Join(dashDir, videoName+"-video-avc1.mp4"), os.O_RDONLY, 0444)
 	if err != nil {
-		ufm.Logger.Errorln(err)
-		return
+		return errors.WithStack(err)
 	}
 	defer videoFluxFile.Close()
-	audioFluxFile, err := os.OpenFile(videoTmpFile.Name()+"-audio-fr-mp4a.mp4", os.O_RDONLY, 0444)
+	audioFluxFile, err := os.OpenFile(filepath.Join(dashDir, videoName+"-audio-fr-mp4a.mp4"), os.O_RDONLY, 0444)
 	if err != nil {
-		ufm.Logger.Errorln(err)
-		return
+		return errors.WithStack(err)
 	}
 	defer audioFluxFile.Close()
-	mpdFile, err := os.OpenFile(videoTmpFile.Name()+".mpd", os.O_RDONLY, 0444)
+	mpdFile, err := os.OpenFile(filepath.Join(dashDir, videoName+".mpd"), os.O_RDONLY, 0444)
 	if err != nil {
-		ufm.Logger.Errorln(err)
-		return
+		return errors.WithStack(err)
 	}
 	defer mpdFile.Close()
 
 	// http request to o2switch
-	finalDirPath, err := ufm.sendVideoFiles(dirPath, videoFluxFile, audioFluxFile, mpdFile)
-	if err != nil {
-		ufm.Logger.Errorln(err)
-		return
+	var finalDirPath string
+	if err := ufm.stage(ctx, "upload", func(ctx context.Context) (err error) {
+		finalDirPath, err = ufm.sendVideoFiles(ctx, job.DirPath, videoFluxFile, audioFluxFile, mpdFile)
+		return err
+	}); err != nil {
+		return err
 	}
 
-	video := Video{
-		Path:      finalDirPath,
-		Duration:  prettifyDurationOutput(durVideo),
-		SessionID: sessionID,
+	// the session is ready with its video
+	return ufm.finish(ctx, job, func(tx *sqlx.Tx) error {
+		now := time.Now()
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO videos (path, duration, created_at, session_id) VALUES (?,?,?,?)",
+			finalDirPath, prettifyDurationOutput(durVideo), now, job.SessionID,
+		); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, "UPDATE sessions SET is_ready = 1, updated_at = ? WHERE id = ?", now, job.SessionID)
+		return err
+	})
+}
+
+// ProcessDoc spools every docUploadFiles then sends them to storage in background
+func (ufm *UploadFileManager) ProcessDoc(ctx context.Context, dirPath string, sessionID int, docUploadFiles []*DocUploadFile) error {
+	jobs, err := ufm.docJobs(ctx, dirPath, sessionID, docUploadFiles)
+	if err == nil {
+		err = ufm.enqueue(jobs)
+	}
+	if err != nil {
+		removeSpooled(jobs)
+		return err
 	}
-	ufm.VideoCh <- video
+	return nil
 }
 
-func (ufm *UploadFileManager) ProcessDoc(dirPath string, sessionID int, docUploadFiles []*DocUploadFile) {
+// docJobs spools docUploadFiles, the jobs spooled before an error are returned with it
+func (ufm *UploadFileManager) docJobs(ctx context.Context, dirPath string, sessionID int, docUploadFiles []*DocUploadFile) ([]*UploadJob, error) {
+	jobs := make([]*UploadJob, 0, len(docUploadFiles))
 	for _, docUploadFile := range docUploadFiles {
 		var fileName string
 		splitFile := strings.SplitAfter(docUploadFile.File.Filename, ".")
@@ -156,173 +480,130 @@ func (ufm *UploadFileManager) ProcessDoc(dirPath string, sessionID int, docUploa
 		} else {
 			fileName = strings.Replace(normSubject(*docUploadFile.Title), " ", "_", -1)
 		}
-		tmpFile, err := ioutil.TempFile(os.TempDir(), fileName+".*."+splitFile[1])
-		if err != nil {
-			ufm.Logger.Errorln(err)
-			return
-		}
-		defer os.Remove(tmpFile.Name())
 		// 20mb maxi
 		if docUploadFile.File.Size > 20000000 {
 			ufm.Logger.Errorln(fmt.Errorf("%s size is too big", fileName))
-			return
-		}
-		fileBytes := bytes.Buffer{}
-		fileBytes.Grow(int(docUploadFile.File.Size))
-		if _, err := fileBytes.ReadFrom(docUploadFile.File.File); err != nil {
-			ufm.Logger.Errorln(err)
-			return
-		}
-
-		docFile, err := os.OpenFile(tmpFile.Name(), os.O_RDONLY, 0444)
-		if err != nil {
-			ufm.Logger.Errorln(err)
-			return
+			continue
 		}
-		defer docFile.Close()
-
-		// http request to o2switch
-		finalDirPath, err := ufm.sendDocumentFile(dirPath, docFile)
+		sourcePath, err := ufm.spool(fileName+".*."+splitFile[len(splitFile)-1], docUploadFile.File.File)
 		if err != nil {
-			ufm.Logger.Errorln(err)
-			return
-		}
-
-		ufm.ClassPaperCh <- ClassPaper{
-			Title:     fileName,
-			Path:      finalDirPath,
-			SessionID: sessionID,
+			return jobs, err
 		}
+		jobs = append(jobs, &UploadJob{
+			Kind:       UploadJobDoc,
+			SessionID:  sessionID,
+			DirPath:    dirPath,
+			SourcePath: sourcePath,
+			Title:      fileName,
+			parent:     trace.SpanContextFromContext(ctx),
+		})
 	}
+	return jobs, nil
 }
 
-func (ufm *UploadFileManager) sendVideoFiles(dirPath string, vFile, aFile, mpdFile *os.File) (string, error) {
-	const urlServer = "http://localhost:8080/storage_video"
-	r, w := io.Pipe()
-	m := multipart.NewWriter(w)
-
-	go func() {
-		defer w.Close()
-		defer m.Close()
-		if err := m.WriteField("dir_path", dirPath); err != nil {
-			ufm.Logger.Errorln(err)
-			return
-		}
-		// video
-		filename := strings.Split(vFile.Name(), "/")
-		part, err := m.CreateFormFile("vfile", filename[len(filename)-1])
-		if err != nil {
-			ufm.Logger.Errorln(err)
-			return
-		}
-		file, err := os.Open(vFile.Name())
-		if err != nil {
-			ufm.Logger.Errorln(err)
-			return
-		}
-		if _, err := io.Copy(part, file); err != nil {
-			ufm.Logger.Errorln(err)
-			return
-		}
-		// audio
-		filename = strings.Split(aFile.Name(), "/")
-		part, err = m.CreateFormFile("afile", filename[len(filename)-1])
-		if err != nil {
-			ufm.Logger.Errorln(err)
-			return
-		}
-		file, err = os.Open(aFile.Name())
-		if err != nil {
-			ufm.Logger.Errorln(err)
-			return
-		}
-		if _, err := io.Copy(part, file); err != nil {
-			ufm.Logger.Errorln(err)
-			return
-		}
-		// mpd
-		filename = strings.Split(mpdFile.Name(), "/")
-		part, err = m.CreateFormFile("mpdfile", filename[len(filename)-1])
-		if err != nil {
-			ufm.Logger.Errorln(err)
-			return
-		}
-		file, err = os.Open(mpdFile.Name())
-		if err != nil {
-			ufm.Logger.Errorln(err)
-			return
-		}
-		if _, err := io.Copy(part, file); err != nil {
-			ufm.Logger.Errorln(err)
-			return
-		}
-		defer file.Close()
-	}()
-	resp, err := http.Post(urlServer, m.FormDataContentType(), r)
-	if err != nil {
-		ufm.Logger.Errorln(err)
-		return "", err
+func removeSpooled(jobs []*UploadJob) {
+	for _, job := range jobs {
+		os.Remove(job.SourcePath)
 	}
-	rBody, err := ioutil.ReadAll(resp.Body)
+}
+
+func (ufm *UploadFileManager) processDoc(ctx context.Context, job *UploadJob) error {
+	docFile, err := os.OpenFile(job.SourcePath, os.O_RDONLY, 0444)
 	if err != nil {
-		ufm.Logger.Errorln(err)
-		return "", err
+		return errors.WithStack(err)
 	}
-	var dat map[string]interface{}
-	if err := json.Unmarshal(rBody, &dat); err != nil {
-		ufm.Logger.Errorln(err)
-		return "", err
+	defer docFile.Close()
+
+	// http request to o2switch
+	var finalDirPath string
+	if err := ufm.stage(ctx, "upload", func(ctx context.Context) (err error) {
+		finalDirPath, err = ufm.sendDocumentFile(ctx, job.DirPath, docFile)
+		return err
+	}); err != nil {
+		return err
 	}
-	dirP := dat["dir_path"].(string)
-	return dirP, nil
+
+	return ufm.finish(ctx, job, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO class_papers (title, path, created_at, session_id) VALUES (?,?,?,?)",
+			job.Title, finalDirPath, time.Now(), job.SessionID,
+		)
+		return err
+	})
+}
+
+func (ufm *UploadFileManager) sendVideoFiles(ctx context.Context, dirPath string, vFile, aFile, mpdFile *os.File) (string, error) {
+	return ufm.send(ctx, ufm.Storage.VideoURL, dirPath, []formFile{
+		{"vfile", vFile}, {"afile", aFile}, {"mpdfile", mpdFile},
+	})
+}
+
+func (ufm *UploadFileManager) sendDocumentFile(ctx context.Context, dirPath string, doc *os.File) (string, error) {
+	return ufm.send(ctx, ufm.Storage.DocURL, dirPath, []formFile{{"docfile", doc}})
 }
 
-func (ufm *UploadFileManager) sendDocumentFile(dirPath string, doc *os.File) (string, error) {
-	const urlServer = "http://localhost:8080/storage_doc"
+type formFile struct {
+	field string
+	file  *os.File
+}
+
+// send streams dirPath then files to the storage server, see package storage
+// for the contract, and returns the stored path it answers
+func (ufm *UploadFileManager) send(ctx context.Context, url string, dirPath string, files []formFile) (string, error) {
 	r, w := io.Pipe()
 	m := multipart.NewWriter(w)
 
 	go func() {
-		w.Close()
-		m.Close()
-		if err := m.WriteField("dir_path", dirPath); err != nil {
-			ufm.Logger.Errorln(err)
-			return
-		}
-		// doc
-		part, err := m.CreateFormFile("docfile", doc.Name())
-		if err != nil {
-			ufm.Logger.Errorln(err)
-			return
-		}
-		file, err := os.Open(doc.Name())
-		if err != nil {
-			ufm.Logger.Errorln(err)
-			return
-		}
-		if _, err := io.Copy(part, file); err != nil {
-			ufm.Logger.Errorln(err)
-			return
-		}
+		// the request reads the pipe until it gets the error or EOF
+		w.CloseWithError(func() error {
+			if err := m.WriteField("dir_path", dirPath); err != nil {
+				return err
+			}
+			for _, f := range files {
+				part, err := m.CreateFormFile(f.field, filepath.Base(f.file.Name()))
+				if err != nil {
+					return err
+				}
+				if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+					return err
+				}
+				if _, err := io.Copy(part, f.file); err != nil {
+					return err
+				}
+			}
+			return m.Close()
+		}())
 	}()
-	// doc field
-	resp, err := http.Post(urlServer, m.FormDataContentType(), r)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, r)
 	if err != nil {
-		ufm.Logger.Errorln(err)
-		return "", err
+		r.Close()
+		return "", errors.WithStack(err)
 	}
+	req.Header.Set("Content-Type", m.FormDataContentType())
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer resp.Body.Close()
 	rBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		ufm.Logger.Errorln(err)
-		return "", err
+		return "", errors.WithStack(err)
+	}
+	var dat struct {
+		DirPath string `json:"dir_path"`
+		Error   string `json:"error"`
 	}
-	var dat map[string]interface{}
 	if err := json.Unmarshal(rBody, &dat); err != nil {
-		ufm.Logger.Errorln(err)
-		return "", err
+		return "", errors.Wrapf(err, "storage answered %s", resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("storage answered %s: %s", resp.Status, dat.Error)
+	}
+	if dat.DirPath == "" {
+		return "", errors.New("storage answered without dir_path")
 	}
-	dirP := dat["dir_path"].(string)
-	return dirP, nil
+	return dat.DirPath, nil
 }
 
 func prettifyDurationOutput(durationInMS []byte) string {
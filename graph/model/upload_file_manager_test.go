@@ -0,0 +1,272 @@
+package model_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/jmoiron/sqlx"
+	"github.com/juleur/becrpe/config"
+	"github.com/juleur/becrpe/graph/model"
+	"github.com/juleur/becrpe/storage"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/sirupsen/logrus"
+)
+
+// fakeTools stand for the packaging tools, mp4dash writes the files named
+// like the real one does
+var fakeTools = map[string]string{
+	"mp4fragment": `#!/bin/sh
+cp "$3" "$4"
+`,
+	"mp4dash": `#!/bin/sh
+while [ $# -gt 0 ]; do
+	case "$1" in
+	--media-prefix) prefix=$2; shift ;;
+	--mpd-name) mpd=$2; shift ;;
+	-o) out=$2; shift ;;
+	esac
+	shift
+done
+mkdir -p "$out"
+echo video > "$out/$prefix-video-avc1.mp4"
+echo audio > "$out/$prefix-audio-fr-mp4a.mp4"
+echo "<MPD/>" > "$out/$mpd"
+`,
+	"ffprobe": `#!/bin/sh
+echo 0:01:02.500000
+`,
+}
+
+const contractSchema = `
+CREATE TABLE sessions (id INTEGER PRIMARY KEY, is_ready INTEGER NOT NULL DEFAULT 0, updated_at DATETIME NULL);
+CREATE TABLE videos (id INTEGER PRIMARY KEY AUTOINCREMENT, path TEXT, duration TEXT, created_at DATETIME NOT NULL, session_id INTEGER NOT NULL);
+CREATE TABLE class_papers (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT, path TEXT, created_at DATETIME NOT NULL, session_id INTEGER);
+CREATE TABLE upload_jobs (
+	id INTEGER PRIMARY KEY AUTOINCREMENT, kind TEXT NOT NULL, session_id INTEGER NOT NULL, dir_path TEXT NOT NULL,
+	source_path TEXT NOT NULL, title TEXT NOT NULL DEFAULT '', created_at DATETIME NOT NULL, failed_at DATETIME NULL, error TEXT NULL
+);
+INSERT INTO sessions (id) VALUES (12);
+`
+
+const dirPath = "/player/french/2020/rc/session-12"
+
+// contractEnv runs an UploadFileManager against the storage server of
+// cmd/storage, root is the directory the server writes to
+type contractEnv struct {
+	ufm  *model.UploadFileManager
+	db   *sqlx.DB
+	root string
+}
+
+func newContractEnv(t *testing.T) *contractEnv {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake packaging tools are shell scripts")
+	}
+	bin := t.TempDir()
+	for name, script := range fakeTools {
+		if err := ioutil.WriteFile(filepath.Join(bin, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	root := t.TempDir()
+	server, err := storage.NewServer(root, storage.DefaultPublicPrefix, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server.Handler())
+	t.Cleanup(ts.Close)
+
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every connection would get its own :memory: database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	if _, err := db.Exec(contractSchema); err != nil {
+		t.Fatal(err)
+	}
+
+	ufm, err := model.NewUploadFileManager(db, logger, config.Storage{
+		VideoURL: ts.URL + "/storage_video",
+		DocURL:   ts.URL + "/storage_doc",
+		SpoolDir: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &contractEnv{ufm: ufm, db: db, root: root}
+}
+
+// wait lets the jobs finish and checks nothing of them is left behind
+func (env *contractEnv) wait(t *testing.T) {
+	t.Helper()
+	if err := env.ufm.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var jobs int
+	if err := env.db.Get(&jobs, "SELECT COUNT(*) FROM upload_jobs"); err != nil {
+		t.Fatal(err)
+	}
+	if jobs != 0 {
+		t.Errorf("%d upload jobs left", jobs)
+	}
+	spooled, err := ioutil.ReadDir(env.ufm.Storage.SpoolDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range spooled {
+		t.Errorf("%s left in the spool directory", f.Name())
+	}
+}
+
+// stored lists the files written under dirPath
+func (env *contractEnv) stored(t *testing.T) []string {
+	t.Helper()
+	files, err := ioutil.ReadDir(filepath.Join(env.root, filepath.FromSlash(dirPath)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	return names
+}
+
+// publicPath checks the prefix the API strips and returns the path under it
+func publicPath(t *testing.T, stored string) string {
+	t.Helper()
+	if !strings.HasPrefix(stored, storage.DefaultPublicPrefix) {
+		t.Fatalf("dir_path %q lacks the public prefix %q", stored, storage.DefaultPublicPrefix)
+	}
+	return stored[storage.PublicPrefixLen:]
+}
+
+func TestProcessVideoStorageContract(t *testing.T) {
+	env := newContractEnv(t)
+	err := env.ufm.ProcessVideo(context.Background(), dirPath, 12, graphql.Upload{
+		File:     strings.NewReader("mp4 bytes"),
+		Filename: "cours.mp4",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.wait(t)
+
+	video := struct {
+		Path     string `db:"path"`
+		Duration string `db:"duration"`
+		IsReady  bool   `db:"is_ready"`
+	}{}
+	if err := env.db.Get(&video, `
+		SELECT v.path, v.duration, s.is_ready FROM videos AS v JOIN sessions AS s ON s.id = v.session_id
+	`); err != nil {
+		t.Fatal(err)
+	}
+	manifest := publicPath(t, video.Path)
+	if path.Dir(manifest) != dirPath || path.Ext(manifest) != ".mpd" {
+		t.Errorf("dir_path %q does not name a manifest in %s", manifest, dirPath)
+	}
+	if video.Duration != "0:01:02" {
+		t.Errorf("duration %q, want 0:01:02", video.Duration)
+	}
+	if !video.IsReady {
+		t.Error("session not ready")
+	}
+
+	prefix := strings.TrimSuffix(path.Base(manifest), ".mpd")
+	want := []string{prefix + "-audio-fr-mp4a.mp4", prefix + "-video-avc1.mp4", prefix + ".mpd"}
+	sort.Strings(want)
+	if got := env.stored(t); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("stored %v, want %v", got, want)
+	}
+}
+
+func TestProcessDocStorageContract(t *testing.T) {
+	env := newContractEnv(t)
+	title := "Fiche de révision"
+	err := env.ufm.ProcessDoc(context.Background(), dirPath, 12, []*model.DocUploadFile{{
+		Title: &title,
+		File:  graphql.Upload{File: strings.NewReader("pdf bytes"), Filename: "fiche.pdf", Size: 9},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.wait(t)
+
+	classPaper := struct {
+		Title string `db:"title"`
+		Path  string `db:"path"`
+	}{}
+	if err := env.db.Get(&classPaper, "SELECT title, path FROM class_papers WHERE session_id = 12"); err != nil {
+		t.Fatal(err)
+	}
+	if classPaper.Title != "fiche_de_revision" {
+		t.Errorf("title %q, want fiche_de_revision", classPaper.Title)
+	}
+	doc := publicPath(t, classPaper.Path)
+	if path.Dir(doc) != dirPath || path.Ext(doc) != ".pdf" {
+		t.Errorf("dir_path %q does not name a class paper in %s", doc, dirPath)
+	}
+
+	if got := env.stored(t); len(got) != 1 || got[0] != path.Base(doc) {
+		t.Errorf("stored %v, want [%s]", got, path.Base(doc))
+	}
+	content, err := ioutil.ReadFile(filepath.Join(env.root, filepath.FromSlash(doc)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "pdf bytes" {
+		t.Errorf("stored %q, want the uploaded bytes", content)
+	}
+}
+
+func TestProcessVideoKeepsJobWhenSaveFails(t *testing.T) {
+	env := newContractEnv(t)
+	if _, err := env.db.Exec("DROP TABLE videos"); err != nil {
+		t.Fatal(err)
+	}
+	err := env.ufm.ProcessVideo(context.Background(), dirPath, 12, graphql.Upload{
+		File:     strings.NewReader("mp4 bytes"),
+		Filename: "cours.mp4",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := env.ufm.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	job := model.UploadJob{}
+	if err := env.db.Get(&job, "SELECT id, source_path, failed_at FROM upload_jobs"); err != nil {
+		t.Fatal(err)
+	}
+	if !job.FailedAt.Valid {
+		t.Error("job not marked failed")
+	}
+	if _, err := os.Stat(job.SourcePath); err != nil {
+		t.Errorf("spooled video removed: %v", err)
+	}
+	var ready bool
+	if err := env.db.Get(&ready, "SELECT is_ready FROM sessions WHERE id = 12"); err != nil {
+		t.Fatal(err)
+	}
+	if ready {
+		t.Error("session ready without video")
+	}
+}
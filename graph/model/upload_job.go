@@ -0,0 +1,29 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	UploadJobVideo = "VIDEO"
+	UploadJobDoc   = "DOC"
+)
+
+// UploadJob is a spooled upload waiting to be processed, it is saved in
+// upload_jobs when queued and deleted once done, FailedAt is set when it fails
+type UploadJob struct {
+	ID         int            `json:"id,omitempty" db:"id,omitempty"`
+	Kind       string         `json:"kind,omitempty" db:"kind,omitempty"`
+	SessionID  int            `json:"sessionId,omitempty" db:"session_id,omitempty"`
+	DirPath    string         `json:"dirPath,omitempty" db:"dir_path,omitempty"`
+	SourcePath string         `json:"sourcePath,omitempty" db:"source_path,omitempty"`
+	Title      string         `json:"title,omitempty" db:"title,omitempty"`
+	CreatedAt  time.Time      `json:"createdAt,omitempty" db:"created_at,omitempty"`
+	FailedAt   sql.NullTime   `json:"failedAt,omitempty" db:"failed_at,omitempty"`
+	Error      sql.NullString `json:"error,omitempty" db:"error,omitempty"`
+	// parent is the span of the request which created the job
+	parent trace.SpanContext
+}
@@ -6,14 +6,16 @@ import (
 )
 
 type User struct {
-	ID           int            `json:"id,omitempty" db:"id,omitempty"`
-	Username     string         `json:"username,omitempty" db:"username,omitempty"`
-	Fullname     sql.NullString `json:"fullname,omitempty" db:"fullname,omitempty"`
-	Email        string         `json:"email,omitempty" db:"email,omitempty"`
-	EncryptedPWD string         `db:"encrypted_pwd,omitempty"`
-	IsTeacher    bool           `json:"isTeacher,omitempty" db:"is_teacher,omitempty"`
-	CreatedAt    time.Time      `json:"createdAt,omitempty" db:"created_at,omitempty"`
-	UpdatedAt    sql.NullTime   `json:"updatedAt,omitempty" db:"updated_at,omitempty"`
+	ID              int            `json:"id,omitempty" db:"id,omitempty"`
+	Username        string         `json:"username,omitempty" db:"username,omitempty"`
+	Fullname        sql.NullString `json:"fullname,omitempty" db:"fullname,omitempty"`
+	Email           string         `json:"email,omitempty" db:"email,omitempty"`
+	EmailVerifiedAt sql.NullTime   `json:"emailVerifiedAt,omitempty" db:"email_verified_at,omitempty"`
+	EncryptedPWD    string         `db:"encrypted_pwd,omitempty"`
+	TOTPEnabledAt   sql.NullTime   `json:"totpEnabledAt,omitempty" db:"totp_enabled_at,omitempty"`
+	CreatedAt       time.Time      `json:"createdAt,omitempty" db:"created_at,omitempty"`
+	UpdatedAt       sql.NullTime   `json:"updatedAt,omitempty" db:"updated_at,omitempty"`
+	RoleNames       RoleNames      `json:"-" db:"role_names"`
 }
 
 //sql.NullString
@@ -1,10 +1,30 @@
 package graph
 
 import (
-	"github.com/jmoiron/sqlx"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/gbrlsnchs/jwt/v3"
 	"github.com/juleur/becrpe/cache"
+	"github.com/juleur/becrpe/config"
 	"github.com/juleur/becrpe/graph/model"
+	"github.com/juleur/becrpe/interceptors"
+	"github.com/juleur/becrpe/jwtkeys"
+	"github.com/juleur/becrpe/logging"
+	"github.com/juleur/becrpe/mail"
+	"github.com/juleur/becrpe/password"
+	"github.com/juleur/becrpe/repository"
+	"github.com/juleur/becrpe/utils"
 	"github.com/sirupsen/logrus"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
 // This file will not be regenerated automatically.
@@ -12,9 +32,402 @@ import (
 // It serves as dependency injection for your app, add any dependencies you require here.
 
 type Resolver struct {
-	DB                *sqlx.DB
-	SecretKey         string
+	repository.Repositories
+	JWT               config.JWT
+	JWTKeys           *jwtkeys.KeySet
+	Account           config.Account
+	RateLimit         config.RateLimit
+	TwoFactorConfig   config.TwoFactor
+	Passwords         *password.Hasher
+	Mailer            mail.Sender
 	RedisCache        *cache.Cache
 	UploadFileManager *model.UploadFileManager
 	Logger            *logrus.Logger
 }
+
+// log returns the request-scoped entry so every line carries the request ID
+func (r *Resolver) log(ctx context.Context) *logrus.Entry {
+	return logging.ForContext(ctx, r.Logger)
+}
+
+// revokeFamily ends every session descending from the login of a replayed
+// refresh token, the thief and the user both have to authenticate again
+func (r *Resolver) revokeFamily(ctx context.Context, userAuth *model.UserAuth) error {
+	n, err := r.AuthTokens.RevokeFamily(ctx, userAuth.UserID, userAuth.FamilyID)
+	r.revokeSessions(ctx, userAuth.FamilyID)
+	entry := r.log(ctx).WithFields(logrus.Fields{
+		"security_event": "refresh_token_reuse",
+		"user_id":        userAuth.UserID,
+		"family_id":      userAuth.FamilyID,
+		"user_auth_id":   userAuth.ID,
+		"revoked":        n,
+	})
+	if err != nil {
+		entry.Errorln(err)
+	} else {
+		entry.Warnln("revoked refresh token presented again, token family revoked")
+	}
+	return &gqlerror.Error{
+		Message: "Par mesure de sécurité votre session a été fermée sur tous vos appareils, veuillez vous réauthentifier",
+		Extensions: map[string]interface{}{
+			"statusCode": http.StatusUnauthorized,
+			"statusText": http.StatusText(http.StatusUnauthorized),
+		},
+	}
+}
+
+// signJWT issues the access token of a device session, the roles are read
+// at each refresh so granting or revoking one applies within a JWT lifetime
+func (r *Resolver) signJWT(ctx context.Context, username string, userID int, sessionID int) (string, error) {
+	roles, permissions, err := r.Roles.ByUser(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	jti, err := utils.JWTIDGenerator()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	pl := model.CustomPayload{
+		Payload: jwt.Payload{
+			Issuer:         r.JWT.Issuer,
+			ExpirationTime: jwt.NumericDate(now.Add(r.JWT.Expiration)),
+			IssuedAt:       jwt.NumericDate(now),
+			JWTID:          jti,
+		},
+		Username:    username,
+		UserID:      userID,
+		Roles:       roles,
+		Permissions: permissions,
+		SessionID:   sessionID,
+	}
+	token, err := r.JWTKeys.Sign(pl)
+	return string(token), err
+}
+
+// revokeSessions makes JWTCheck reject the JWTs already issued to the
+// families, their refresh tokens must be revoked in user_auths beforehand
+func (r *Resolver) revokeSessions(ctx context.Context, familyIDs ...int) {
+	for _, familyID := range familyIDs {
+		if err := r.RedisCache.RevokeSession(ctx, strconv.Itoa(familyID), r.JWT.Expiration); err != nil {
+			r.log(ctx).Errorln(err)
+		}
+	}
+}
+
+// deviceSessions lists the device sessions of the user, current is the one of the JWT
+func (r *Resolver) deviceSessions(ctx context.Context, userID, current int) ([]*model.DeviceSession, error) {
+	auths, err := r.AuthTokens.Active(ctx, userID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return []*model.DeviceSession{}, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	deviceSessions := make([]*model.DeviceSession, 0, len(auths))
+	for _, auth := range auths {
+		origin := model.SessionOriginEnumRefresh
+		if auth.OnLogin {
+			origin = model.SessionOriginEnumLogin
+		}
+		deviceSessions = append(deviceSessions, &model.DeviceSession{
+			ID:          auth.FamilyID,
+			UserAgent:   auth.UserAgent,
+			IPAddress:   auth.IPAddress,
+			DeliveredAt: auth.DeliveredAt,
+			Origin:      origin,
+			Current:     auth.FamilyID == current,
+		})
+	}
+	return deviceSessions, nil
+}
+
+// upgradePassword replaces the hash of a password just verified by one
+// of the current algorithm and parameters, the login goes on if it fails
+func (r *Resolver) upgradePassword(ctx context.Context, userID int, pwd string) {
+	hash, err := r.Passwords.Hash(pwd)
+	if err == nil {
+		err = r.Users.UpdatePassword(ctx, userID, hash)
+	}
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return
+	}
+	r.log(ctx).WithField("security_event", "password_rehashed").Infoln(fmt.Sprintf("User n°%d password hash upgraded", userID))
+}
+
+// throttle counts a call of operation per client IP and per email and
+// refuses it once a cap of limit is reached, redis failures let it through
+func (r *Resolver) throttle(ctx context.Context, operation string, limit config.Limit, email string) error {
+	for _, hit := range []struct {
+		by, value string
+		max       int
+	}{
+		{"ip", interceptors.ForIPAddress(ctx), limit.PerIP},
+		{"email", normalizeEmail(email), limit.PerEmail},
+	} {
+		if hit.max <= 0 {
+			continue
+		}
+		allowed, retryAfter, err := r.RedisCache.Hit(ctx, operation+":"+hit.by+":"+hit.value, hit.max, limit.Window)
+		if err != nil {
+			r.log(ctx).Errorln(err)
+			continue
+		}
+		if !allowed {
+			r.log(ctx).WithFields(logrus.Fields{
+				"security_event": "rate_limited",
+				"operation":      operation,
+				"limited_by":     hit.by,
+			}).Warnln("rate limit reached")
+			return tooManyRequests("Trop de tentatives", retryAfter)
+		}
+	}
+	return nil
+}
+
+// lockedFor answers how long the lockout key (cache.LoginKey, ...) stays
+// locked after wrong passwords or codes, redis failures let it through
+func (r *Resolver) lockedFor(ctx context.Context, key string) time.Duration {
+	if r.RateLimit.Lockout.Threshold <= 0 {
+		return 0
+	}
+	locked, err := r.RedisCache.LockedFor(ctx, key)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+	}
+	return locked
+}
+
+// authFailed counts a wrong password (or unknown email) or code toward the lockout of key
+func (r *Resolver) authFailed(ctx context.Context, key string) {
+	lockout := r.RateLimit.Lockout
+	if lockout.Threshold <= 0 {
+		return
+	}
+	lock, err := r.RedisCache.AddFailure(ctx, key, lockout.Threshold, lockout.BaseDuration, lockout.MaxDuration)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return
+	}
+	if lock > 0 {
+		r.log(ctx).WithFields(logrus.Fields{
+			"security_event": "login_lockout",
+			"locked_for":     lock.String(),
+		}).Warnln("too many wrong passwords or codes, login locked")
+	}
+}
+
+// authSucceeded resets the lockout count of key
+func (r *Resolver) authSucceeded(ctx context.Context, key string) {
+	if r.RateLimit.Lockout.Threshold <= 0 {
+		return
+	}
+	if err := r.RedisCache.ClearFailures(ctx, key); err != nil {
+		r.log(ctx).Errorln(err)
+	}
+}
+
+// reauthenticate checks the password of the signed-in user before a sensitive
+// change, under the lockouts of login so a stolen JWT cannot guess it
+func (r *Resolver) reauthenticate(ctx context.Context, user *model.User, pwd, failure string) error {
+	lockKey := cache.LoginKey(user.Email)
+	if locked := r.lockedFor(ctx, lockKey); locked > 0 {
+		r.log(ctx).Errorln("password confirmation attempt on a locked account")
+		return tooManyRequests("Trop de mots de passe incorrects", locked)
+	}
+	if _, err := r.Passwords.Verify(user.EncryptedPWD, pwd); err != nil {
+		r.log(ctx).Errorln(err)
+		r.authFailed(ctx, lockKey)
+		return &gqlerror.Error{
+			Message: failure,
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusUnauthorized,
+				"statusText": http.StatusText(http.StatusUnauthorized),
+			},
+		}
+	}
+	r.authSucceeded(ctx, lockKey)
+	return nil
+}
+
+// normalizeEmail keys the limits so that case or spaces do not bypass them
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// tooManyRequests is the 429 error, extensions.retryAfter is in seconds
+func tooManyRequests(reason string, retryAfter time.Duration) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	wait, unit := seconds, "seconde"
+	if seconds > 60 {
+		wait, unit = int(math.Ceil(float64(seconds)/60)), "minute"
+	}
+	if wait > 1 {
+		unit += "s"
+	}
+	return &gqlerror.Error{
+		Message: fmt.Sprintf("%s, veuillez réessayer dans %d %s", reason, wait, unit),
+		Extensions: map[string]interface{}{
+			"statusCode": http.StatusTooManyRequests,
+			"statusText": http.StatusText(http.StatusTooManyRequests),
+			"retryAfter": seconds,
+		},
+	}
+}
+
+// startSession starts a device session (a refresh token family) of user
+// and returns its tokens
+func (r *Resolver) startSession(ctx context.Context, user *model.User) (*model.Token, error) {
+	refreshToken, err := utils.RefreshTokenGenerator()
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return &model.Token{}, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	// push refresh token, only its hash is stored
+	userAuth := model.UserAuth{
+		UserAgent:        interceptors.ForUserAgent(ctx),
+		IPAddress:        interceptors.ForIPAddress(ctx),
+		RefreshTokenHash: utils.RefreshTokenHash(r.JWT.RefreshTokenKey, refreshToken),
+		OnLogin:          true,
+		UserID:           user.ID,
+	}
+	if err := r.AuthTokens.Create(ctx, &userAuth); err != nil {
+		r.log(ctx).Errorln(err)
+		return &model.Token{}, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	// generate new jwt
+	jwtoken, err := r.signJWT(ctx, user.Username, user.ID, userAuth.FamilyID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return &model.Token{}, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	return r.deliverTokens(ctx, &model.Token{Jwt: jwtoken, RefreshToken: refreshToken})
+}
+
+// deliverTokens returns tokens, or sets them in the cookies of a cookie
+// session, only from mutations since queries skip the CSRF check
+func (r *Resolver) deliverTokens(ctx context.Context, tokens *model.Token) (*model.Token, error) {
+	cookies := interceptors.ForSessionCookies(ctx)
+	if cookies == nil {
+		return tokens, nil
+	}
+	if err := r.refuseCookieQuery(ctx); err != nil {
+		return &model.Token{}, err
+	}
+	if err := cookies.SetTokens(tokens.Jwt, tokens.RefreshToken); err != nil {
+		r.log(ctx).Errorln(err)
+		return &model.Token{}, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	return &model.Token{}, nil
+}
+
+// refuseCookieQuery refuses the queries of cookie sessions which would set
+// the session cookies, GET requests skip the CSRF check
+func (r *Resolver) refuseCookieQuery(ctx context.Context) error {
+	if interceptors.ForSessionCookies(ctx) == nil {
+		return nil
+	}
+	if rc := graphql.GetOperationContext(ctx); rc.Operation != nil && rc.Operation.Operation == ast.Mutation {
+		return nil
+	}
+	r.log(ctx).Errorln("session cookies asked by a query")
+	return &gqlerror.Error{
+		Message: "Veuillez vous connecter avec la mutation login",
+		Extensions: map[string]interface{}{
+			"statusCode": http.StatusBadRequest,
+			"statusText": http.StatusText(http.StatusBadRequest),
+		},
+	}
+}
+
+// twoFactorChallengePurpose prefixes the login challenges like emailVerificationPurpose
+const twoFactorChallengePurpose = "two-factor-challenge"
+
+// checkSecondFactor answers whether code is a TOTP code of sealedSecret or an
+// unused recovery code of userID, which it spends. TOTP codes are accepted once.
+func (r *Resolver) checkSecondFactor(ctx context.Context, userID int, sealedSecret, code string) (bool, error) {
+	secret, err := utils.Open(r.TwoFactorConfig.EncryptionKey, sealedSecret)
+	if err != nil {
+		return false, err
+	}
+	if step, ok := utils.TOTPValidate(secret, code, time.Now()); ok {
+		// a code stays valid up to 90s, without redis it could be replayed
+		first, err := r.RedisCache.Once(ctx, fmt.Sprintf("totp:%d:%d", userID, step), 2*time.Minute)
+		if err != nil {
+			return false, err
+		}
+		return first, nil
+	}
+	if err := r.TwoFactor.UseRecoveryCode(ctx, userID, utils.RecoveryCodeHash(code)); err != nil {
+		if err == repository.ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	r.log(ctx).WithField("security_event", "recovery_code_used").Warnln("two-factor recovery code used")
+	return true, nil
+}
+
+// emailVerificationPurpose prefixes the payload of the email verification
+// tokens so a token signed for something else is never accepted
+const emailVerificationPurpose = "email-verification"
+
+// sendVerificationEmail emails the link proving user owns user.Email, the
+// signed token carries the email so it is void once the email changes
+func (r *Resolver) sendVerificationEmail(ctx context.Context, user *model.User) {
+	expiresAt := time.Now().Add(r.Account.EmailVerificationTTL)
+	payload := fmt.Sprintf("%s|%d|%s", emailVerificationPurpose, user.ID, user.Email)
+	token := utils.SignToken(r.Account.VerificationKey, payload, expiresAt)
+	r.sendMail(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Confirmez votre adresse email",
+		Body: fmt.Sprintf("Bonjour %s,\n\n"+
+			"Pour confirmer votre adresse email, ouvrez ce lien avant le %s :\n%s/verify-email?token=%s\n\n"+
+			"Si vous n'êtes pas à l'origine de cette demande, ignorez ce message.\n",
+			user.Username, expiresAt.Format("02/01/2006 à 15h04"), r.Account.URL, url.QueryEscape(token)),
+	})
+}
+
+// sendMail sends msg in the background, failures are only logged
+func (r *Resolver) sendMail(ctx context.Context, msg mail.Message) {
+	entry := r.log(ctx)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := r.Mailer.Send(ctx, msg); err != nil {
+			entry.Errorln(err)
+		}
+	}()
+}
@@ -0,0 +1,525 @@
+package graph_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/juleur/becrpe/cache"
+	"github.com/juleur/becrpe/config"
+	"github.com/juleur/becrpe/graph"
+	"github.com/juleur/becrpe/graph/generated"
+	"github.com/juleur/becrpe/graph/model"
+	"github.com/juleur/becrpe/interceptors"
+	"github.com/juleur/becrpe/jwtkeys"
+	"github.com/juleur/becrpe/password"
+	"github.com/juleur/becrpe/repository"
+	"github.com/juleur/becrpe/repository/memory"
+	"github.com/juleur/becrpe/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const loginMutation = `mutation ($email: String!, $password: String!) {
+	login(input: {email: $email, password: $password}) { jwt refreshToken twoFactorChallenge }
+}`
+
+// harness serves the executable schema over the memory store and miniredis,
+// behind the interceptors of the server
+type harness struct {
+	store *memory.Store
+	repos repository.Repositories
+	c     *client.Client
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(mr.Close)
+	redisCache, err := cache.NewCache(mr.Addr(), "", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { redisCache.Close() })
+
+	cfg := config.Default()
+	cfg.JWT.SecretKey = "jwt-secret-key-of-the-resolver-tests"
+	cfg.JWT.Expiration = time.Hour
+	cfg.JWT.RefreshTokenKey = "refresh-token-key-of-the-resolver-tests"
+	cfg.Account.VerificationKey = "verification-key-of-the-resolver-tests"
+	cfg.TwoFactor.EncryptionKey = "encryption-key-of-the-resolver-tests"
+	cfg.TwoFactor.ChallengeKey = "challenge-key-of-the-resolver-tests"
+	cfg.Password = config.Password{Algorithm: config.PasswordBcrypt, BcryptCost: 4}
+	keys, err := jwtkeys.Load(cfg.JWT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	store := memory.New()
+	resolver := &graph.Resolver{
+		Repositories:    store.Repositories(),
+		JWT:             cfg.JWT,
+		JWTKeys:         keys,
+		Account:         cfg.Account,
+		RateLimit:       cfg.RateLimit,
+		TwoFactorConfig: cfg.TwoFactor,
+		Passwords:       password.New(cfg.Password),
+		RedisCache:      redisCache,
+		Logger:          logger,
+	}
+	srv := handler.New(generated.NewExecutableSchema(generated.Config{
+		Resolvers:  resolver,
+		Directives: resolver.Directives(),
+	}))
+	srv.AddTransport(transport.POST{})
+	var h http.Handler = srv
+	for _, mw := range []func(http.Handler) http.Handler{
+		interceptors.GetUserAgent(),
+		interceptors.GetIPAddress(),
+		interceptors.JWTCheck(cfg.JWT, keys, redisCache),
+	} {
+		h = mw(h)
+	}
+	return &harness{store: store, repos: store.Repositories(), c: client.New(h)}
+}
+
+// addUser creates a user whose password is pwd and grants roles besides student
+func (h *harness) addUser(t *testing.T, username, pwd string, roles ...string) *model.User {
+	t.Helper()
+	hash, err := password.New(config.Password{Algorithm: config.PasswordBcrypt, BcryptCost: 4}).Hash(pwd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &model.User{Username: username, Email: username + "@ecrpe.fr", EncryptedPWD: hash}
+	if err := h.repos.Users.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	for _, role := range roles {
+		if err := h.repos.Roles.Grant(context.Background(), user.ID, role); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return user
+}
+
+type gqlError struct {
+	Message    string `json:"message"`
+	Extensions struct {
+		StatusCode int `json:"statusCode"`
+	} `json:"extensions"`
+}
+
+// post runs query with the JWT of jwt when set, decodes data into out and
+// returns the statusCode of the first error, 0 without error
+func (h *harness) post(t *testing.T, jwt, query string, out interface{}, opts ...client.Option) int {
+	t.Helper()
+	if jwt != "" {
+		opts = append(opts, client.AddHeader("Authorization", "Bearer "+jwt))
+	}
+	resp, err := h.c.RawPost(query, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Errors) > 0 {
+		errs := []gqlError{}
+		if err := json.Unmarshal(resp.Errors, &errs); err != nil {
+			t.Fatal(err)
+		}
+		return errs[0].Extensions.StatusCode
+	}
+	data, err := json.Marshal(resp.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		t.Fatal(err)
+	}
+	return 0
+}
+
+type loginResponse struct {
+	Login struct {
+		Jwt                string
+		RefreshToken       string
+		TwoFactorChallenge *string
+	}
+}
+
+func (h *harness) login(t *testing.T, email, pwd string) (loginResponse, int) {
+	t.Helper()
+	resp := loginResponse{}
+	status := h.post(t, "", loginMutation, &resp, client.Var("email", email), client.Var("password", pwd))
+	return resp, status
+}
+
+func TestLogin(t *testing.T) {
+	tests := []struct {
+		name string
+		// setup runs before the login of alice with password
+		setup         func(t *testing.T, h *harness, alice *model.User)
+		password      string
+		wantStatus    int
+		wantChallenge bool
+	}{
+		{
+			name:     "success",
+			password: "alice-password",
+		},
+		{
+			name:       "wrong password",
+			password:   "not-alice-password",
+			wantStatus: 404,
+		},
+		{
+			name: "locked out after wrong passwords",
+			setup: func(t *testing.T, h *harness, alice *model.User) {
+				for i := 0; i < config.Default().RateLimit.Lockout.Threshold; i++ {
+					if _, status := h.login(t, alice.Email, "not-alice-password"); status != 404 {
+						t.Fatalf("wrong password %d: status %d, want 404", i, status)
+					}
+				}
+			},
+			password:   "alice-password",
+			wantStatus: 429,
+		},
+		{
+			name: "two-factor challenge",
+			setup: func(t *testing.T, h *harness, alice *model.User) {
+				ctx := context.Background()
+				if err := h.repos.TwoFactor.SetPendingSecret(ctx, alice.ID, "sealed-secret"); err != nil {
+					t.Fatal(err)
+				}
+				if err := h.repos.TwoFactor.Enable(ctx, alice.ID, nil); err != nil {
+					t.Fatal(err)
+				}
+			},
+			password:      "alice-password",
+			wantChallenge: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t)
+			alice := h.addUser(t, "alice", "alice-password")
+			if tt.setup != nil {
+				tt.setup(t, h, alice)
+			}
+			resp, status := h.login(t, alice.Email, tt.password)
+			if status != tt.wantStatus {
+				t.Fatalf("status %d, want %d", status, tt.wantStatus)
+			}
+			if tt.wantStatus != 0 {
+				return
+			}
+			token := resp.Login
+			if tt.wantChallenge {
+				if token.TwoFactorChallenge == nil || *token.TwoFactorChallenge == "" {
+					t.Error("no two-factor challenge")
+				}
+				if token.Jwt != "" || token.RefreshToken != "" {
+					t.Error("tokens delivered before the second factor")
+				}
+				if n := len(h.store.UserAuths()); n != 0 {
+					t.Errorf("%d device sessions started, want 0", n)
+				}
+				return
+			}
+			if token.TwoFactorChallenge != nil {
+				t.Error("two-factor challenge without two-factor authentication")
+			}
+			if token.Jwt == "" || token.RefreshToken == "" {
+				t.Error("tokens not delivered")
+			}
+			if n := len(h.store.UserAuths()); n != 1 {
+				t.Errorf("%d device sessions started, want 1", n)
+			}
+		})
+	}
+}
+
+func TestLoginQuery(t *testing.T) {
+	h := newHarness(t)
+	alice := h.addUser(t, "alice", "alice-password")
+	resp := loginResponse{}
+	status := h.post(t, "", `query ($email: String!, $password: String!) {
+		login(input: {email: $email, password: $password}) { jwt refreshToken }
+	}`, &resp, client.Var("email", alice.Email), client.Var("password", "alice-password"))
+	if status != 0 {
+		t.Fatalf("status %d, want 0", status)
+	}
+	if resp.Login.Jwt == "" || resp.Login.RefreshToken == "" {
+		t.Error("tokens not delivered to a bearer client")
+	}
+}
+
+func TestVerifyTwoFactor(t *testing.T) {
+	h := newHarness(t)
+	alice := h.addUser(t, "alice", "alice-password")
+	ctx := context.Background()
+	secret, err := utils.TOTPSecretGenerator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealedSecret, err := utils.Seal("encryption-key-of-the-resolver-tests", secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.repos.TwoFactor.SetPendingSecret(ctx, alice.ID, sealedSecret); err != nil {
+		t.Fatal(err)
+	}
+	recoveryCodes := []string{utils.RecoveryCodeHash("first-recovery-code"), utils.RecoveryCodeHash("second-recovery-code")}
+	if err := h.repos.TwoFactor.Enable(ctx, alice.ID, recoveryCodes); err != nil {
+		t.Fatal(err)
+	}
+	login, status := h.login(t, alice.Email, "alice-password")
+	if status != 0 || login.Login.TwoFactorChallenge == nil {
+		t.Fatalf("login status %d without challenge", status)
+	}
+	// the email verification links are signed with another key
+	forged := utils.SignToken("verification-key-of-the-resolver-tests",
+		fmt.Sprintf("two-factor-challenge|%d", alice.ID), time.Now().Add(time.Hour))
+
+	verify := func(challenge, code string) (loginResponse, int) {
+		out := struct{ VerifyTwoFactor struct{ Jwt string } }{}
+		status := h.post(t, "", `mutation ($challenge: String!, $code: String!) {
+			verifyTwoFactor(input: {challenge: $challenge, code: $code}) { jwt }
+		}`, &out, client.Var("challenge", challenge), client.Var("code", code))
+		resp := loginResponse{}
+		resp.Login.Jwt = out.VerifyTwoFactor.Jwt
+		return resp, status
+	}
+	if _, status := verify(forged, "first-recovery-code"); status != 401 {
+		t.Errorf("challenge signed with the verification key: status %d, want 401", status)
+	}
+	resp, status := verify(*login.Login.TwoFactorChallenge, "second-recovery-code")
+	if status != 0 || resp.Login.Jwt == "" {
+		t.Errorf("login challenge: status %d, want tokens", status)
+	}
+}
+
+func TestEnableTwoFactor(t *testing.T) {
+	tests := []struct {
+		name       string
+		password   string
+		wantStatus int
+	}{
+		{name: "success", password: "alice-password"},
+		{name: "wrong password", password: "not-alice-password", wantStatus: 401},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t)
+			alice := h.addUser(t, "alice", "alice-password")
+			resp, _ := h.login(t, alice.Email, "alice-password")
+			out := struct{ EnableTwoFactor struct{ Secret string } }{}
+			status := h.post(t, resp.Login.Jwt, `mutation ($password: String!) {
+				enableTwoFactor(input: {password: $password}) { secret }
+			}`, &out, client.Var("password", tt.password))
+			if status != tt.wantStatus {
+				t.Fatalf("status %d, want %d", status, tt.wantStatus)
+			}
+			_, _, err := h.repos.TwoFactor.Secret(context.Background(), alice.ID)
+			if tt.wantStatus == 0 && (err != nil || out.EnableTwoFactor.Secret == "") {
+				t.Errorf("enrolment not started: %v", err)
+			}
+			if tt.wantStatus != 0 && err != repository.ErrNotFound {
+				t.Errorf("enrolment started with a wrong password: %v", err)
+			}
+		})
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	h := newHarness(t)
+	alice := h.addUser(t, "alice", "alice-password")
+	for i := 0; i < config.Default().RateLimit.Lockout.Threshold; i++ {
+		h.login(t, alice.Email, "not-alice-password")
+	}
+	if _, status := h.login(t, alice.Email, "alice-password"); status != 429 {
+		t.Fatalf("login before the reset: status %d, want 429", status)
+	}
+	for _, token := range []string{"first-reset-token", "second-reset-token"} {
+		if err := h.repos.PasswordResets.Create(context.Background(), alice.ID, utils.OneTimeTokenHash(token), time.Now().Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reset := func(token, pwd string) int {
+		out := struct{ ResetPassword bool }{}
+		return h.post(t, "", `mutation ($token: String!, $password: String!) {
+			resetPassword(input: {token: $token, password: $password})
+		}`, &out, client.Var("token", token), client.Var("password", pwd))
+	}
+	if status := reset("second-reset-token", "new-alice-password"); status != 0 {
+		t.Fatalf("reset: status %d, want 0", status)
+	}
+	if status := reset("first-reset-token", "other-alice-password"); status != 400 {
+		t.Errorf("older reset link: status %d, want 400", status)
+	}
+	if _, status := h.login(t, alice.Email, "new-alice-password"); status != 0 {
+		t.Errorf("login after the reset: status %d, want 0", status)
+	}
+}
+
+const purchaseMutation = `mutation ($courseId: Int!) {
+	purchaseRefresherCourse(input: {refresherCourseId: $courseId, paypalOrderId: "ORDER-1", paypalPayerId: "PAYER-1"})
+}`
+
+func TestPurchaseRefresherCourseTwice(t *testing.T) {
+	h := newHarness(t)
+	year := "2020"
+	subject := model.SubjectEnumFrench
+	courseID := h.store.AddRefresherCourse(model.RefresherCourse{Subject: &subject, Year: &year})
+	alice := h.addUser(t, "alice", "alice-password")
+	resp, status := h.login(t, alice.Email, "alice-password")
+	if status != 0 {
+		t.Fatalf("login status %d", status)
+	}
+
+	tests := []struct {
+		name       string
+		wantStatus int
+	}{
+		{"first purchase", 0},
+		// the same PayPal order submitted again
+		{"double purchase", 500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := struct{ PurchaseRefresherCourse bool }{}
+			status := h.post(t, resp.Login.Jwt, purchaseMutation, &out, client.Var("courseId", courseID))
+			if status != tt.wantStatus {
+				t.Fatalf("status %d, want %d", status, tt.wantStatus)
+			}
+			if tt.wantStatus == 0 && !out.PurchaseRefresherCourse {
+				t.Error("purchase answered false")
+			}
+		})
+	}
+	purchased, err := h.repos.Enrollments.Purchased(context.Background(), alice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(purchased) != 1 {
+		t.Errorf("%d enrollments, want 1", len(purchased))
+	}
+}
+
+const sessionCourseQuery = `query ($userId: Int, $courseId: Int!, $sessionId: Int!) {
+	sessionCourse(input: {userId: $userId, refresherCourseId: $courseId, sessionId: $sessionId}) {
+		session { id }
+		video { path }
+		teacher { username }
+	}
+}`
+
+func TestSessionCourse(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	year := "2020"
+	subject := model.SubjectEnumFrench
+	courseID := h.store.AddRefresherCourse(model.RefresherCourse{Subject: &subject, Year: &year})
+	teacher := h.addUser(t, "teacher", "teacher-password", model.RoleTeacher.Name())
+	sessionID, err := h.repos.Sessions.Create(ctx, teacher.ID, model.NewSessionInput{
+		RefresherCourseID: courseID,
+		Title:             "Le récit",
+		Section:           model.SectionEnumDialectical,
+		Type:              model.TypeEnumLesson,
+		RecordedOn:        time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.store.AddVideo(model.Video{
+		Path:      "/player/french/2020/rc/session-" + strconv.Itoa(sessionID) + "/video.mpd",
+		Duration:  "1:00:00",
+		SessionID: sessionID,
+	})
+	// without video the session stays not ready
+	processingID, err := h.repos.Sessions.Create(ctx, teacher.ID, model.NewSessionInput{
+		RefresherCourseID: courseID,
+		Title:             "La poésie",
+		Section:           model.SectionEnumDialectical,
+		Type:              model.TypeEnumLesson,
+		RecordedOn:        time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	student := h.addUser(t, "student", "student-password")
+	if err := h.repos.Enrollments.Purchase(ctx, student.ID, courseID, "PAYER-1", "ORDER-1"); err != nil {
+		t.Fatal(err)
+	}
+	h.addUser(t, "other", "other-password")
+	h.addUser(t, "support", "support-password", "support")
+
+	tests := []struct {
+		name   string
+		caller string
+		// userID is the userId argument, nil for the caller
+		userID *int
+		// sessionID is the sessionId argument, 0 for the ready session
+		sessionID  int
+		wantStatus int
+	}{
+		{name: "enrolled", caller: "student"},
+		{name: "session not ready", caller: "student", sessionID: processingID, wantStatus: 404},
+		{name: "unknown session", caller: "student", sessionID: processingID + 100, wantStatus: 404},
+		{name: "not enrolled", caller: "other", wantStatus: 403},
+		{name: "other user without USER_READ", caller: "other", userID: &student.ID, wantStatus: 403},
+		{name: "USER_READ for an enrolled user", caller: "support", userID: &student.ID},
+		{name: "USER_READ for itself not enrolled", caller: "support", wantStatus: 403},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			login, status := h.login(t, tt.caller+"@ecrpe.fr", tt.caller+"-password")
+			if status != 0 {
+				t.Fatalf("login status %d", status)
+			}
+			out := struct {
+				SessionCourse struct {
+					Session struct{ ID string }
+					Video   struct{ Path string }
+					Teacher struct{ Username string }
+				}
+			}{}
+			id := sessionID
+			if tt.sessionID != 0 {
+				id = tt.sessionID
+			}
+			opts := []client.Option{client.Var("courseId", courseID), client.Var("sessionId", id)}
+			if tt.userID != nil {
+				opts = append(opts, client.Var("userId", *tt.userID))
+			}
+			status = h.post(t, login.Login.Jwt, sessionCourseQuery, &out, opts...)
+			if status != tt.wantStatus {
+				t.Fatalf("status %d, want %d", status, tt.wantStatus)
+			}
+			if tt.wantStatus != 0 {
+				return
+			}
+			if out.SessionCourse.Session.ID != strconv.Itoa(sessionID) {
+				t.Errorf("session %q, want %d", out.SessionCourse.Session.ID, sessionID)
+			}
+			if out.SessionCourse.Video.Path == "" {
+				t.Error("no video path")
+			}
+			if out.SessionCourse.Teacher.Username != teacher.Username {
+				t.Errorf("teacher %q, want %q", out.SessionCourse.Teacher.Username, teacher.Username)
+			}
+		})
+	}
+}
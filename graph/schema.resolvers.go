@@ -5,26 +5,110 @@ package graph
 
 import (
 	"context"
-	"database/sql"
+	"encoding/base64"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/gbrlsnchs/jwt/v3"
-	"github.com/go-sql-driver/mysql"
+	"github.com/juleur/becrpe/cache"
 	"github.com/juleur/becrpe/graph/generated"
 	"github.com/juleur/becrpe/graph/model"
 	"github.com/juleur/becrpe/interceptors"
+	"github.com/juleur/becrpe/mail"
+	"github.com/juleur/becrpe/repository"
 	"github.com/juleur/becrpe/utils"
 	"github.com/vektah/gqlparser/v2/gqlerror"
-	"golang.org/x/crypto/bcrypt"
 )
 
+func (r *meResolver) PurchasedCourses(ctx context.Context, obj *model.Me) ([]*model.PurchasedCourse, error) {
+	courses, err := r.Enrollments.Purchased(ctx, obj.User.ID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return []*model.PurchasedCourse{}, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	return courses, nil
+}
+
+func (r *meResolver) DeviceSessions(ctx context.Context, obj *model.Me) ([]*model.DeviceSession, error) {
+	return r.deviceSessions(ctx, obj.User.ID, obj.SessionID)
+}
+
+func (r *mutationResolver) Login(ctx context.Context, input model.LoginInput) (*model.Token, error) {
+	if err := r.throttle(ctx, "login", r.RateLimit.Login, input.Email); err != nil {
+		return &model.Token{}, err
+	}
+	// unknown emails are locked too, the answer must not tell they are unknown
+	if locked := r.lockedFor(ctx, cache.LoginKey(input.Email)); locked > 0 {
+		r.log(ctx).Errorln("login attempt on a locked email")
+		return &model.Token{}, tooManyRequests("Ce compte est temporairement bloqué suite à de trop nombreuses tentatives", locked)
+	}
+	user, err := r.Users.ByEmail(ctx, input.Email)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			r.log(ctx).Errorln(err)
+			r.authFailed(ctx, cache.LoginKey(input.Email))
+			return &model.Token{}, &gqlerror.Error{
+				Message: "L'email et le Mot de Passe saisis ne correspondent pas à de nos archives, veuillez vérifier vos identifiants puis réessayez",
+				Extensions: map[string]interface{}{
+					"statusCode": http.StatusNotFound,
+					"statusText": http.StatusText(http.StatusNotFound),
+				},
+			}
+		}
+		r.log(ctx).Errorln(err)
+		return &model.Token{}, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	// check if password matches with the one in db
+	rehash, err := r.Passwords.Verify(user.EncryptedPWD, input.Password)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		r.authFailed(ctx, cache.LoginKey(input.Email))
+		return &model.Token{}, &gqlerror.Error{
+			Message: "L'email et le Mot de Passe saisis ne correspondent à aucunes de nos archives, veuillez vérifier vos identifiants puis réessayez !",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusNotFound,
+				"statusText": http.StatusText(http.StatusNotFound),
+			},
+		}
+	}
+	r.authSucceeded(ctx, cache.LoginKey(input.Email))
+	if rehash {
+		r.upgradePassword(ctx, user.ID, input.Password)
+	}
+	if user.TOTPEnabledAt.Valid {
+		// the tokens wait for the code, the challenge proves the password was right
+		challenge := utils.SignToken(r.TwoFactorConfig.ChallengeKey,
+			fmt.Sprintf("%s|%d", twoFactorChallengePurpose, user.ID),
+			time.Now().Add(r.TwoFactorConfig.ChallengeTTL))
+		return &model.Token{TwoFactorChallenge: &challenge}, nil
+	}
+	// every login starts a new device session (a refresh token family)
+	return r.startSession(ctx, user)
+}
+
 func (r *mutationResolver) CreateUser(ctx context.Context, input model.NewUserInput) (bool, error) {
-	hashPWD, err := bcrypt.GenerateFromPassword([]byte(input.Password), 10)
+	// before hashing, its cost is what a flood would exhaust
+	if err := r.throttle(ctx, "create_user", r.RateLimit.CreateUser, input.Email); err != nil {
+		return false, err
+	}
+	hashPWD, err := r.Passwords.Hash(input.Password)
 	if err != nil {
-		r.Logger.Errorln(err)
+		r.log(ctx).Errorln(err)
 		return false, &gqlerror.Error{
 			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 			Extensions: map[string]interface{}{
@@ -33,13 +117,11 @@ func (r *mutationResolver) CreateUser(ctx context.Context, input model.NewUserIn
 			},
 		}
 	}
-	if _, err := r.DB.Exec(
-		"INSERT INTO users (username, email, encrypted_pwd, created_at) VALUES (?,?,?,?)",
-		input.Username, input.Email, string(hashPWD), time.Now(),
-	); err != nil {
-		if mysqlErr, ok := err.(*mysql.MySQLError); ok {
-			r.Logger.Errorln(err)
-			if strings.Contains(mysqlErr.Message, "email") {
+	user := model.User{Username: input.Username, Email: input.Email, EncryptedPWD: hashPWD}
+	if err := r.Users.Create(ctx, &user); err != nil {
+		if err == repository.ErrDuplicateEmail || err == repository.ErrDuplicateUsername {
+			r.log(ctx).Errorln(err)
+			if err == repository.ErrDuplicateEmail {
 				return false, &gqlerror.Error{
 					Message: "Cette email est déjà utilisée, Veuillez utiliser une autre !",
 					Extensions: map[string]interface{}{
@@ -56,7 +138,7 @@ func (r *mutationResolver) CreateUser(ctx context.Context, input model.NewUserIn
 				},
 			}
 		}
-		r.Logger.Errorln(err)
+		r.log(ctx).Errorln(err)
 		return false, &gqlerror.Error{
 			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 			Extensions: map[string]interface{}{
@@ -65,17 +147,20 @@ func (r *mutationResolver) CreateUser(ctx context.Context, input model.NewUserIn
 			},
 		}
 	}
+	r.sendVerificationEmail(ctx, &user)
 	return true, nil
 }
 
-func (r *mutationResolver) RefreshToken(ctx context.Context, refreshToken string) (*model.Token, error) {
-	userAuth := model.UserAuth{}
-	if err := r.DB.Get(&userAuth, `
-		SELECT ua.user_id, u.username, u.is_teacher FROM user_auths AS ua
-		JOIN users AS u ON u.id = ua.user_id
-		WHERE ua.is_revoked = 0 AND ua.revoked_at is NULL AND ua.refresh_token = ?
-  	`, refreshToken); err != nil {
-		r.Logger.Errorln(err)
+func (r *mutationResolver) RefreshToken(ctx context.Context, refreshToken *string) (*model.Token, error) {
+	var presented string
+	if refreshToken != nil {
+		presented = *refreshToken
+	} else if cookies := interceptors.ForSessionCookies(ctx); cookies != nil {
+		presented = cookies.RefreshToken()
+	}
+	userAuth, err := r.AuthTokens.ByRefreshTokenHash(ctx, utils.RefreshTokenHash(r.JWT.RefreshTokenKey, presented))
+	if err != nil {
+		r.log(ctx).Errorln(err)
 		return &model.Token{}, &gqlerror.Error{
 			Message: "Oops, une erreur est survenue avec votre session, veuillez vous réauthentifier",
 			Extensions: map[string]interface{}{
@@ -84,12 +169,12 @@ func (r *mutationResolver) RefreshToken(ctx context.Context, refreshToken string
 			},
 		}
 	}
-	// revoke token
-	if _, err := r.DB.Queryx(`
-	    UPDATE user_auths SET is_revoked=?, revoked_at=?
-	    WHERE is_revoked=0 AND revoked_at is NULL AND user_id=? AND refresh_token=?
-	`, 1, time.Now(), userAuth.UserID, refreshToken); err != nil {
-		r.Logger.Errorln(err)
+	if userAuth.IsRevoked {
+		// a rotated token is only presented again when it was stolen
+		if userAuth.ReplacedByID.Valid {
+			return &model.Token{}, r.revokeFamily(ctx, userAuth)
+		}
+		r.log(ctx).Errorln(fmt.Sprintf("User n°%d presented a revoked refresh token", userAuth.UserID))
 		return &model.Token{}, &gqlerror.Error{
 			Message: "Oops, une erreur est survenue avec votre session, veuillez vous réauthentifier",
 			Extensions: map[string]interface{}{
@@ -99,19 +184,20 @@ func (r *mutationResolver) RefreshToken(ctx context.Context, refreshToken string
 		}
 	}
 	// Create new jwt then new refresh token
-	pl := model.CustomPayload{
-		Payload: jwt.Payload{
-			Issuer:         "https://rf.ecrpe.fr",
-			ExpirationTime: jwt.NumericDate(time.Now().Add(1 * time.Minute)),
-			IssuedAt:       jwt.NumericDate(time.Now()),
-		},
-		Username: userAuth.Username,
-		UserID:   userAuth.UserID,
-		Teacher:  userAuth.IsTeacher,
+	jwtoken, err := r.signJWT(ctx, userAuth.Username, userAuth.UserID, userAuth.FamilyID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return &model.Token{}, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue avec votre session, veuillez vous réauthentifier",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusUnauthorized,
+				"statusText": http.StatusText(http.StatusUnauthorized),
+			},
+		}
 	}
-	jwtoken, err := jwt.Sign(pl, jwt.NewHS512([]byte(r.SecretKey)))
+	newRefreshToken, err := utils.RefreshTokenGenerator()
 	if err != nil {
-		r.Logger.Errorln(err)
+		r.log(ctx).Errorln(err)
 		return &model.Token{}, &gqlerror.Error{
 			Message: "Oops, une erreur est survenue avec votre session, veuillez vous réauthentifier",
 			Extensions: map[string]interface{}{
@@ -121,20 +207,25 @@ func (r *mutationResolver) RefreshToken(ctx context.Context, refreshToken string
 		}
 	}
 	tokens := model.Token{
-		Jwt:          string(jwtoken),
-		RefreshToken: utils.HexKeyGenerator(16),
+		Jwt:          jwtoken,
+		RefreshToken: newRefreshToken,
 	}
 
 	// Get IP Address from user
 	userIP := interceptors.ForIPAddress(ctx)
 	userAgent := interceptors.ForUserAgent(ctx)
-	// push refresh token
-	if _, err = r.DB.Exec(`
-	    INSERT INTO user_auths (user_agent, ip_address, refresh_token, delivered_at, on_refresh, user_id)
-	    VALUES (?,?,?,?,?,?)
-	  `, userAgent, userIP, tokens.RefreshToken, time.Now(), 1, userAuth.UserID,
-	); err != nil {
-		r.Logger.Errorln(err)
+	// revoke token and push its replacement in the same family
+	if err := r.AuthTokens.Rotate(ctx, userAuth, &model.UserAuth{
+		UserAgent:        userAgent,
+		IPAddress:        userIP,
+		RefreshTokenHash: utils.RefreshTokenHash(r.JWT.RefreshTokenKey, tokens.RefreshToken),
+		OnRefresh:        true,
+		UserID:           userAuth.UserID,
+	}); err != nil {
+		if err == repository.ErrTokenReused {
+			return &model.Token{}, r.revokeFamily(ctx, userAuth)
+		}
+		r.log(ctx).Errorln(err)
 		return &model.Token{}, &gqlerror.Error{
 			Message: "Oops, une erreur est survenue avec votre session, veuillez vous réauthentifier",
 			Extensions: map[string]interface{}{
@@ -143,11 +234,18 @@ func (r *mutationResolver) RefreshToken(ctx context.Context, refreshToken string
 			},
 		}
 	}
-	return &tokens, nil
+	return r.deliverTokens(ctx, &tokens)
 }
 
 func (r *mutationResolver) UpdateUser(ctx context.Context, input model.UpdateUserInput) (*model.User, error) {
-	if *input.Email == "" && *input.Username == "" {
+	var email, username string
+	if input.Email != nil {
+		email = *input.Email
+	}
+	if input.Username != nil {
+		username = *input.Username
+	}
+	if email == "" && username == "" {
 		return &model.User{}, &gqlerror.Error{
 			Message: "Rien à mettre à jour",
 			Extensions: map[string]interface{}{
@@ -156,21 +254,11 @@ func (r *mutationResolver) UpdateUser(ctx context.Context, input model.UpdateUse
 			},
 		}
 	}
-	userAuth := interceptors.ForUserContext(ctx)
-	if !userAuth.IsAuth {
-		r.Logger.Errorln(fmt.Sprintf("User n°%d authentication didn't succeed", userAuth.UserID), "HttpErrorStatus", userAuth.HttpErrorResponse.StatusText)
-		return &model.User{}, &gqlerror.Error{
-			Message: userAuth.HttpErrorResponse.Message,
-			Extensions: map[string]interface{}{
-				"statusCode": userAuth.HttpErrorResponse.StatusCode,
-				"statusText": userAuth.HttpErrorResponse.StatusText,
-			},
-		}
-	}
-	user := model.User{}
-	// fetch user password before bcrypt checking
-	if err := r.DB.Get(&user, "SELECT encrypted_pwd FROM users WHERE id = ?", userAuth.UserID); err != nil {
-		r.Logger.Errorln(err)
+	userAuth := authUser(ctx)
+	// fetch user password before checking it
+	user, err := r.Users.ByID(ctx, userAuth.UserID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
 		return &model.User{}, &gqlerror.Error{
 			Message: "Oops, nous n'avons pu procéder à la mise à jour de votre profil, veuillez contacter l'administrateur !",
 			Extensions: map[string]interface{}{
@@ -180,8 +268,8 @@ func (r *mutationResolver) UpdateUser(ctx context.Context, input model.UpdateUse
 		}
 	}
 	// checking if password is correct
-	if err := bcrypt.CompareHashAndPassword([]byte(user.EncryptedPWD), []byte(input.Password)); err != nil {
-		r.Logger.Errorln(err)
+	if _, err := r.Passwords.Verify(user.EncryptedPWD, input.Password); err != nil {
+		r.log(ctx).Errorln(err)
 		return &model.User{}, &gqlerror.Error{
 			Message: "Votre mot de passe est incorrect, nous n'avons pu procéder à la mise à jour de votre profil",
 			Extensions: map[string]interface{}{
@@ -191,175 +279,716 @@ func (r *mutationResolver) UpdateUser(ctx context.Context, input model.UpdateUse
 		}
 	}
 
-	query := strings.Builder{}
-	query.WriteString("UPDATE users SET ")
-	argsQuery := []string{}
-	userUpdated := model.User{}
-	if *input.Email != "" {
-		argsQuery = append(argsQuery, fmt.Sprintf("email = '%s'", *input.Email))
-		userUpdated.Email = *input.Email
+	userUpdated := model.User{ID: user.ID, Email: email, Username: username, RoleNames: user.RoleNames}
+	if err := r.Users.Update(ctx, userAuth.UserID, userUpdated.Email, userUpdated.Username); err != nil {
+		r.log(ctx).Errorln(err)
+		return &model.User{}, &gqlerror.Error{
+			Message: "Oops, nous n'avons pu procéder à la mise à jour de votre profil, veuillez contacter l'administrateur",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
 	}
-	if *input.Username != "" {
-		argsQuery = append(argsQuery, fmt.Sprintf("username = '%s'", *input.Username))
-		userUpdated.Username = *input.Username
+	if email == "" || email == user.Email {
+		userUpdated.EmailVerifiedAt = user.EmailVerifiedAt
+	} else {
+		// the new address is unverified until its link is opened
+		mailTo := *user
+		mailTo.Email = email
+		r.sendVerificationEmail(ctx, &mailTo)
 	}
-	query.WriteString(strings.Join(argsQuery, ","))
-	query.WriteString(fmt.Sprintf(" WHERE id = '%d'", userAuth.UserID))
+	return &userUpdated, nil
+}
 
-	if _, err := r.DB.Queryx(query.String()); err != nil {
-		r.Logger.Errorln(err)
-		return &model.User{}, &gqlerror.Error{
-			Message: "Oops, nous n'avons pu procéder à la mise à jour de votre profil, veuillez contacter l'administrateur",
+func (r *mutationResolver) PurchaseRefresherCourse(ctx context.Context, input model.PurchaseRefresherCourseInput) (bool, error) {
+	userAuth := authUser(ctx)
+	if r.Account.RequireVerifiedEmail {
+		user, err := r.Users.ByID(ctx, userAuth.UserID)
+		if err != nil {
+			r.log(ctx).Errorln(err)
+			return false, &gqlerror.Error{
+				Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+				Extensions: map[string]interface{}{
+					"statusCode": http.StatusInternalServerError,
+					"statusText": http.StatusText(http.StatusInternalServerError),
+				},
+			}
+		}
+		if !user.EmailVerifiedAt.Valid {
+			return false, &gqlerror.Error{
+				Message: "Veuillez confirmer votre adresse email avant tout achat",
+				Extensions: map[string]interface{}{
+					"statusCode": http.StatusForbidden,
+					"statusText": http.StatusText(http.StatusForbidden),
+				},
+			}
+		}
+	}
+	// payment and enrollment are saved together
+	if err := r.Enrollments.Purchase(ctx,
+		userAuth.UserID, input.RefresherCourseID, input.PaypalPayerID, input.PaypalOrderID,
+	); err != nil {
+		r.log(ctx).Errorln(err)
+		return false, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, veuillez réessayer puis contacter l'administrateur en cas de nouvelle erreur",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	return true, nil
+}
+
+func (r *mutationResolver) CreateRefresherCourse(ctx context.Context, input model.NewSessionInput) (bool, error) {
+	userAuth := authUser(ctx)
+	refCourse, err := r.Courses.ByID(ctx, input.RefresherCourseID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return false, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, veuillez réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	// Create new session
+	sessionID, err := r.Sessions.Create(ctx, userAuth.UserID, input)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return false, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, veuillez réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	// directory path
+	dirPath := fmt.Sprintf("/player/%s/%s/rc/session-%d", strings.ToLower(refCourse.Subject.String()), *refCourse.Year, sessionID)
+
+	if err := r.UploadFileManager.ProcessSession(ctx, dirPath, sessionID, input.VideoFile, input.DocFiles); err != nil {
+		r.log(ctx).Errorln(err)
+		// nothing was queued, the session would never be ready
+		if err := r.Sessions.Delete(ctx, sessionID); err != nil {
+			r.log(ctx).Errorln(err)
+		}
+		return false, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, veuillez réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	return true, nil
+}
+
+func (r *mutationResolver) RevokeDeviceSession(ctx context.Context, deviceSessionID int) (bool, error) {
+	userAuth := authUser(ctx)
+	n, err := r.AuthTokens.RevokeFamily(ctx, userAuth.UserID, deviceSessionID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return false, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	if n == 0 {
+		return false, &gqlerror.Error{
+			Message: "Cet appareil n'est plus connecté",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusNotFound,
+				"statusText": http.StatusText(http.StatusNotFound),
+			},
+		}
+	}
+	r.revokeSessions(ctx, deviceSessionID)
+	return true, nil
+}
+
+func (r *mutationResolver) LogoutEverywhere(ctx context.Context) (bool, error) {
+	userAuth := authUser(ctx)
+	families, err := r.AuthTokens.RevokeAll(ctx, userAuth.UserID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return false, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	// the current JWT may predate families
+	if userAuth.SessionID != 0 {
+		families = append(families, userAuth.SessionID)
+	}
+	r.revokeSessions(ctx, families...)
+	if cookies := interceptors.ForSessionCookies(ctx); cookies != nil {
+		cookies.Clear()
+	}
+	return true, nil
+}
+
+func (r *mutationResolver) Logout(ctx context.Context) (bool, error) {
+	userAuth := authUser(ctx)
+	// revoke the refresh token of this device
+	if userAuth.SessionID != 0 {
+		if _, err := r.AuthTokens.RevokeFamily(ctx, userAuth.UserID, userAuth.SessionID); err != nil {
+			r.log(ctx).Errorln(err)
+			return false, &gqlerror.Error{
+				Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+				Extensions: map[string]interface{}{
+					"statusCode": http.StatusInternalServerError,
+					"statusText": http.StatusText(http.StatusInternalServerError),
+				},
+			}
+		}
+	}
+	// then the JWT until it expires
+	if userAuth.TokenID != "" {
+		if err := r.RedisCache.DenyJWT(ctx, userAuth.TokenID, time.Until(userAuth.ExpiresAt)); err != nil {
+			r.log(ctx).Errorln(err)
+			return false, &gqlerror.Error{
+				Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+				Extensions: map[string]interface{}{
+					"statusCode": http.StatusInternalServerError,
+					"statusText": http.StatusText(http.StatusInternalServerError),
+				},
+			}
+		}
+	}
+	if cookies := interceptors.ForSessionCookies(ctx); cookies != nil {
+		cookies.Clear()
+	}
+	return true, nil
+}
+
+func (r *mutationResolver) RequestPasswordReset(ctx context.Context, email string) (bool, error) {
+	// before the lookup, unknown emails are counted alike
+	if err := r.throttle(ctx, "password_reset", r.RateLimit.PasswordReset, email); err != nil {
+		return false, err
+	}
+	user, err := r.Users.ByEmail(ctx, email)
+	if err != nil {
+		// same answer whether the email is known or not
+		if err == repository.ErrNotFound {
+			r.log(ctx).Infoln("password reset requested for an unknown email")
+			return true, nil
+		}
+		r.log(ctx).Errorln(err)
+		return false, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	token, err := utils.OneTimeTokenGenerator()
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return false, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	expiresAt := time.Now().Add(r.Account.PasswordResetTTL)
+	if err := r.PasswordResets.Create(ctx, user.ID, utils.OneTimeTokenHash(token), expiresAt); err != nil {
+		r.log(ctx).Errorln(err)
+		return false, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	// sent aside, the answer time must not tell whether the email is known
+	r.sendMail(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Réinitialisation de votre mot de passe",
+		Body: fmt.Sprintf("Bonjour %s,\n\n"+
+			"Pour choisir un nouveau mot de passe, ouvrez ce lien avant le %s :\n%s/reset-password?token=%s\n\n"+
+			"Si vous n'êtes pas à l'origine de cette demande, ignorez ce message, votre mot de passe reste inchangé.\n",
+			user.Username, expiresAt.Format("02/01/2006 à 15h04"), r.Account.URL, url.QueryEscape(token)),
+	})
+	return true, nil
+}
+
+func (r *mutationResolver) ResetPassword(ctx context.Context, input model.ResetPasswordInput) (bool, error) {
+	if len(input.Password) < 8 {
+		return false, &gqlerror.Error{
+			Message: "Votre mot de passe doit contenir au moins 8 caractères",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusBadRequest,
+				"statusText": http.StatusText(http.StatusBadRequest),
+			},
+		}
+	}
+	userID, err := r.PasswordResets.Consume(ctx, utils.OneTimeTokenHash(input.Token))
+	if err != nil {
+		if err == repository.ErrNotFound {
+			r.log(ctx).Errorln(err)
+			return false, &gqlerror.Error{
+				Message: "Ce lien de réinitialisation est invalide ou a expiré, veuillez refaire une demande",
+				Extensions: map[string]interface{}{
+					"statusCode": http.StatusBadRequest,
+					"statusText": http.StatusText(http.StatusBadRequest),
+				},
+			}
+		}
+		r.log(ctx).Errorln(err)
+		return false, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	hashPWD, err := r.Passwords.Hash(input.Password)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return false, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	if err := r.Users.UpdatePassword(ctx, userID, hashPWD); err != nil {
+		r.log(ctx).Errorln(err)
+		return false, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	// whoever knew the old password is logged out
+	families, err := r.AuthTokens.RevokeAll(ctx, userID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return false, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	r.revokeSessions(ctx, families...)
+	// the owner of the mailbox may log in again at once
+	user, err := r.Users.ByID(ctx, userID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return true, nil
+	}
+	r.authSucceeded(ctx, cache.LoginKey(user.Email))
+	return true, nil
+}
+
+func (r *mutationResolver) VerifyEmail(ctx context.Context, token string) (bool, error) {
+	invalid := &gqlerror.Error{
+		Message: "Ce lien de confirmation est invalide ou a expiré, veuillez en demander un nouveau",
+		Extensions: map[string]interface{}{
+			"statusCode": http.StatusBadRequest,
+			"statusText": http.StatusText(http.StatusBadRequest),
+		},
+	}
+	payload, err := utils.VerifyToken(r.Account.VerificationKey, token, time.Now())
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return false, invalid
+	}
+	// purpose|user id|email
+	parts := strings.SplitN(payload, "|", 3)
+	if len(parts) != 3 || parts[0] != emailVerificationPurpose {
+		r.log(ctx).Errorln("email verification token with an unexpected payload")
+		return false, invalid
+	}
+	userID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return false, invalid
+	}
+	if err := r.Users.VerifyEmail(ctx, userID, parts[2]); err != nil {
+		if err == repository.ErrNotFound {
+			// the email changed since the link was sent
+			r.log(ctx).Errorln(err)
+			return false, invalid
+		}
+		r.log(ctx).Errorln(err)
+		return false, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	return true, nil
+}
+
+func (r *mutationResolver) ResendVerificationEmail(ctx context.Context) (bool, error) {
+	userAuth := authUser(ctx)
+	user, err := r.Users.ByID(ctx, userAuth.UserID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return false, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	if user.EmailVerifiedAt.Valid {
+		return true, nil
+	}
+	// the email of the user is theirs alone, it caps the mails sent to each user
+	if err := r.throttle(ctx, "verification_email", r.RateLimit.VerificationEmail, user.Email); err != nil {
+		return false, err
+	}
+	r.sendVerificationEmail(ctx, user)
+	return true, nil
+}
+
+func (r *mutationResolver) EnableTwoFactor(ctx context.Context, input model.EnableTwoFactorInput) (*model.TwoFactorEnrollment, error) {
+	userAuth := authUser(ctx)
+	user, err := r.Users.ByID(ctx, userAuth.UserID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return &model.TwoFactorEnrollment{}, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	if err := r.reauthenticate(ctx, user, input.Password, "Votre mot de passe est incorrect, nous n'avons pu activer la double authentification"); err != nil {
+		return &model.TwoFactorEnrollment{}, err
+	}
+	secret, err := utils.TOTPSecretGenerator()
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return &model.TwoFactorEnrollment{}, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	sealedSecret, err := utils.Seal(r.TwoFactorConfig.EncryptionKey, secret)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return &model.TwoFactorEnrollment{}, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	// pending until confirmTwoFactor receives a first code
+	if err := r.TwoFactor.SetPendingSecret(ctx, user.ID, sealedSecret); err != nil {
+		if err == repository.ErrNotFound {
+			r.log(ctx).Errorln(err)
+			return &model.TwoFactorEnrollment{}, &gqlerror.Error{
+				Message: "La double authentification est déjà activée sur votre compte",
+				Extensions: map[string]interface{}{
+					"statusCode": http.StatusConflict,
+					"statusText": http.StatusText(http.StatusConflict),
+				},
+			}
+		}
+		r.log(ctx).Errorln(err)
+		return &model.TwoFactorEnrollment{}, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	return &model.TwoFactorEnrollment{
+		OtpauthURI: utils.TOTPURI(r.TwoFactorConfig.Issuer, user.Email, secret),
+		Secret:     secret,
+	}, nil
+}
+
+func (r *mutationResolver) ConfirmTwoFactor(ctx context.Context, input model.ConfirmTwoFactorInput) ([]string, error) {
+	userAuth := authUser(ctx)
+	user, err := r.Users.ByID(ctx, userAuth.UserID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return nil, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	if err := r.reauthenticate(ctx, user, input.Password, "Votre mot de passe est incorrect, nous n'avons pu activer la double authentification"); err != nil {
+		return nil, err
+	}
+	sealedSecret, enabled, err := r.TwoFactor.Secret(ctx, userAuth.UserID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			r.log(ctx).Errorln(err)
+			return nil, &gqlerror.Error{
+				Message: "Veuillez d'abord démarrer l'activation de la double authentification",
+				Extensions: map[string]interface{}{
+					"statusCode": http.StatusBadRequest,
+					"statusText": http.StatusText(http.StatusBadRequest),
+				},
+			}
+		}
+		r.log(ctx).Errorln(err)
+		return nil, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	if enabled {
+		return nil, &gqlerror.Error{
+			Message: "La double authentification est déjà activée sur votre compte",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusConflict,
+				"statusText": http.StatusText(http.StatusConflict),
+			},
+		}
+	}
+	secret, err := utils.Open(r.TwoFactorConfig.EncryptionKey, sealedSecret)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return nil, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	if _, ok := utils.TOTPValidate(secret, input.Code, time.Now()); !ok {
+		r.log(ctx).Errorln("wrong code confirming two-factor authentication")
+		return nil, &gqlerror.Error{
+			Message: "Ce code est incorrect, veuillez réessayer",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusUnauthorized,
+				"statusText": http.StatusText(http.StatusUnauthorized),
+			},
+		}
+	}
+	// shown once, only their hashes are stored
+	codes := make([]string, 10)
+	hashes := make([]string, len(codes))
+	for i := range codes {
+		if codes[i], err = utils.RecoveryCodeGenerator(); err != nil {
+			r.log(ctx).Errorln(err)
+			return nil, &gqlerror.Error{
+				Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+				Extensions: map[string]interface{}{
+					"statusCode": http.StatusInternalServerError,
+					"statusText": http.StatusText(http.StatusInternalServerError),
+				},
+			}
+		}
+		hashes[i] = utils.RecoveryCodeHash(codes[i])
+	}
+	if err := r.TwoFactor.Enable(ctx, userAuth.UserID, hashes); err != nil {
+		r.log(ctx).Errorln(err)
+		return nil, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	r.log(ctx).WithField("security_event", "two_factor_enabled").Infoln("two-factor authentication enabled")
+	return codes, nil
+}
+
+func (r *mutationResolver) DisableTwoFactor(ctx context.Context, code string) (bool, error) {
+	userAuth := authUser(ctx)
+	sealedSecret, enabled, err := r.TwoFactor.Secret(ctx, userAuth.UserID)
+	if err != nil && err != repository.ErrNotFound {
+		r.log(ctx).Errorln(err)
+		return false, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 			Extensions: map[string]interface{}{
 				"statusCode": http.StatusInternalServerError,
 				"statusText": http.StatusText(http.StatusInternalServerError),
 			},
 		}
 	}
-	return &userUpdated, nil
-}
-
-func (r *mutationResolver) PurchaseRefresherCourse(ctx context.Context, input model.PurchaseRefresherCourseInput) (bool, error) {
-	userAuth := interceptors.ForUserContext(ctx)
-	if !userAuth.IsAuth {
-		r.Logger.Errorln(fmt.Sprintf("User n°%d authentication didn't succeed", userAuth.UserID), "HttpErrorStatus", userAuth.HttpErrorResponse.StatusText)
+	if !enabled {
 		return false, &gqlerror.Error{
-			Message: userAuth.HttpErrorResponse.Message,
+			Message: "La double authentification n'est pas activée sur votre compte",
 			Extensions: map[string]interface{}{
-				"statusCode": userAuth.HttpErrorResponse.StatusCode,
-				"statusText": userAuth.HttpErrorResponse.StatusText,
+				"statusCode": http.StatusBadRequest,
+				"statusText": http.StatusText(http.StatusBadRequest),
 			},
 		}
 	}
-	// create payments before
-	paymentsIDRes, err := r.DB.Exec(
-		"INSERT INTO payments (paypal_payer_id, paypal_order_id, created_at) VALUES (?,?,?)",
-		input.PaypalPayerID, input.PaypalOrderID, time.Now())
+	lockKey := cache.TwoFactorKey(userAuth.UserID)
+	if locked := r.lockedFor(ctx, lockKey); locked > 0 {
+		r.log(ctx).Errorln("two-factor code attempt on a locked account")
+		return false, tooManyRequests("Trop de codes incorrects", locked)
+	}
+	ok, err := r.checkSecondFactor(ctx, userAuth.UserID, sealedSecret, code)
 	if err != nil {
-		r.Logger.Errorln(err)
+		r.log(ctx).Errorln(err)
 		return false, &gqlerror.Error{
-			Message: "Oops, une erreur est survenue, veuillez réessayer puis contacter l'administrateur en cas de nouvelle erreur",
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 			Extensions: map[string]interface{}{
 				"statusCode": http.StatusInternalServerError,
 				"statusText": http.StatusText(http.StatusInternalServerError),
 			},
 		}
 	}
-	paymentsID, err := paymentsIDRes.LastInsertId()
-	if err != nil {
-		r.Logger.Errorln(err)
+	if !ok {
+		r.log(ctx).Errorln("wrong code disabling two-factor authentication")
+		r.authFailed(ctx, lockKey)
 		return false, &gqlerror.Error{
-			Message: "Oops, une erreur est survenue, veuillez réessayer puis contacter l'administrateur en cas de nouvelle erreur",
+			Message: "Ce code est incorrect, veuillez réessayer",
 			Extensions: map[string]interface{}{
-				"statusCode": http.StatusInternalServerError,
-				"statusText": http.StatusText(http.StatusInternalServerError),
+				"statusCode": http.StatusUnauthorized,
+				"statusText": http.StatusText(http.StatusUnauthorized),
 			},
 		}
 	}
-	//
-	if _, err := r.DB.Exec(
-		"INSERT INTO users_refresher_courses (payment_id, user_id, refresher_course_id) VALUES (?,?,?)",
-		paymentsID, userAuth.UserID, input.RefresherCourseID,
-	); err != nil {
-		r.Logger.Errorln(err)
+	r.authSucceeded(ctx, lockKey)
+	if err := r.TwoFactor.Disable(ctx, userAuth.UserID); err != nil {
+		r.log(ctx).Errorln(err)
 		return false, &gqlerror.Error{
-			Message: "Oops, une erreur est survenue, veuillez réessayer puis contacter l'administrateur en cas de nouvelle erreur",
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 			Extensions: map[string]interface{}{
 				"statusCode": http.StatusInternalServerError,
 				"statusText": http.StatusText(http.StatusInternalServerError),
 			},
 		}
 	}
+	r.log(ctx).WithField("security_event", "two_factor_disabled").Warnln("two-factor authentication disabled")
 	return true, nil
 }
 
-func (r *mutationResolver) CreateRefresherCourse(ctx context.Context, input model.NewSessionInput) (bool, error) {
-	// check if teacher
-	userAuth := interceptors.ForUserContext(ctx)
-	if !userAuth.IsAuth {
-		r.Logger.Errorln(fmt.Sprintf("User n°%d authentication didn't succeed", userAuth.UserID), "HttpErrorStatus", userAuth.HttpErrorResponse.StatusText)
-		return false, &gqlerror.Error{
-			Message: userAuth.HttpErrorResponse.Message,
-			Extensions: map[string]interface{}{
-				"statusCode": userAuth.HttpErrorResponse.StatusCode,
-				"statusText": userAuth.HttpErrorResponse.StatusText,
-			},
-		}
+func (r *mutationResolver) VerifyTwoFactor(ctx context.Context, input model.VerifyTwoFactorInput) (*model.Token, error) {
+	expired := &gqlerror.Error{
+		Message: "Cette tentative de connexion a expiré, veuillez saisir à nouveau vos identifiants",
+		Extensions: map[string]interface{}{
+			"statusCode": http.StatusUnauthorized,
+			"statusText": http.StatusText(http.StatusUnauthorized),
+		},
 	}
-
-	if _, err := r.DB.Queryx("SELECT id FROM users WHERE id = ? AND is_teacher = 1", userAuth.UserID); err != nil {
-		r.Logger.Errorln(err)
-		return false, &gqlerror.Error{
-			Extensions: map[string]interface{}{
-				"statusCode": http.StatusForbidden,
-				"statusText": http.StatusText(http.StatusForbidden),
-			},
-		}
+	payload, err := utils.VerifyToken(r.TwoFactorConfig.ChallengeKey, input.Challenge, time.Now())
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return &model.Token{}, expired
 	}
-	refCourse := model.RefresherCourse{}
-	if err := r.DB.Get(&refCourse, `
-    	SELECT id, subject, year FROM refresher_courses WHERE id = ?
-  	`, input.RefresherCourseID); err != nil {
-		r.Logger.Errorln(err)
-		return false, &gqlerror.Error{
-			Message: "Oops, une erreur est survenue, veuillez réessayer ultérieurement",
+	// purpose|user id
+	parts := strings.SplitN(payload, "|", 2)
+	if len(parts) != 2 || parts[0] != twoFactorChallengePurpose {
+		r.log(ctx).Errorln("two-factor challenge with an unexpected payload")
+		return &model.Token{}, expired
+	}
+	userID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return &model.Token{}, expired
+	}
+	lockKey := cache.TwoFactorKey(userID)
+	if locked := r.lockedFor(ctx, lockKey); locked > 0 {
+		r.log(ctx).Errorln("two-factor code attempt on a locked account")
+		return &model.Token{}, tooManyRequests("Trop de codes incorrects", locked)
+	}
+	user, err := r.Users.ByID(ctx, userID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return &model.Token{}, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 			Extensions: map[string]interface{}{
 				"statusCode": http.StatusInternalServerError,
 				"statusText": http.StatusText(http.StatusInternalServerError),
 			},
 		}
 	}
-	// Create new session
-	sessionIDRes, err := r.DB.Exec(`
-    	INSERT INTO sessions (title, section, type, description, session_number, recorded_on, created_at, refresher_course_id, user_id) VALUES (?,?,?,?,?,?,?,?,?)
-  	`, input.Title, input.Section, input.Type, input.Description, input.SessionNumber, input.RecordedOn, time.Now(), input.RefresherCourseID, userAuth.UserID)
-	if err != nil {
-		r.Logger.Errorln(err)
-		return false, &gqlerror.Error{
-			Message: "Oops, une erreur est survenue, veuillez réessayer ultérieurement",
+	sealedSecret, enabled, err := r.TwoFactor.Secret(ctx, userID)
+	if err != nil && err != repository.ErrNotFound {
+		r.log(ctx).Errorln(err)
+		return &model.Token{}, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 			Extensions: map[string]interface{}{
 				"statusCode": http.StatusInternalServerError,
 				"statusText": http.StatusText(http.StatusInternalServerError),
 			},
 		}
 	}
-	sessionID, err := sessionIDRes.LastInsertId()
+	if !enabled {
+		// disabled since the password was checked
+		r.log(ctx).Errorln("two-factor challenge of an account without two-factor authentication")
+		return &model.Token{}, expired
+	}
+	ok, err := r.checkSecondFactor(ctx, userID, sealedSecret, input.Code)
 	if err != nil {
-		r.Logger.Errorln(err)
-		return false, &gqlerror.Error{
-			Message: "Oops, une erreur est survenue, veuillez réessayer ultérieurement",
+		r.log(ctx).Errorln(err)
+		return &model.Token{}, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 			Extensions: map[string]interface{}{
 				"statusCode": http.StatusInternalServerError,
 				"statusText": http.StatusText(http.StatusInternalServerError),
 			},
 		}
 	}
-	// directory path
-	dirPath := fmt.Sprintf("/player/%s/%s/rc/session-%d", strings.ToLower(refCourse.Subject.String()), *refCourse.Year, sessionID)
-
-	go r.UploadFileManager.ProcessVideo(dirPath, int(sessionID), input.VideoFile, refCourse)
-	if len(input.DocFiles) > 0 {
-		go r.UploadFileManager.ProcessDoc(dirPath, int(sessionID), input.DocFiles)
+	if !ok {
+		r.log(ctx).Errorln("wrong two-factor code")
+		r.authFailed(ctx, lockKey)
+		return &model.Token{}, &gqlerror.Error{
+			Message: "Ce code est incorrect, veuillez réessayer",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusUnauthorized,
+				"statusText": http.StatusText(http.StatusUnauthorized),
+			},
+		}
 	}
-
-	return true, nil
+	r.authSucceeded(ctx, lockKey)
+	return r.startSession(ctx, user)
 }
 
-func (r *queryResolver) Login(ctx context.Context, input model.LoginInput) (*model.Token, error) {
-	user := model.User{}
-	if err := r.DB.Get(&user, "SELECT id, username, is_teacher, encrypted_pwd FROM users WHERE email = ?", input.Email); err != nil {
-		if err == sql.ErrNoRows {
-			r.Logger.Errorln(err)
-			return &model.Token{}, &gqlerror.Error{
-				Message: "L'email et le Mot de Passe saisis ne correspondent pas à de nos archives, veuillez vérifier vos identifiants puis réessayez",
+func (r *mutationResolver) GrantRole(ctx context.Context, userID int, role model.Role) (bool, error) {
+	// the JWTs of the user carry the role from their next refresh
+	if err := r.Roles.Grant(ctx, userID, role.Name()); err != nil {
+		if err == repository.ErrNotFound {
+			r.log(ctx).Errorln(err)
+			return false, &gqlerror.Error{
+				Message: "Cet utilisateur n'existe pas",
 				Extensions: map[string]interface{}{
 					"statusCode": http.StatusNotFound,
 					"statusText": http.StatusText(http.StatusNotFound),
 				},
 			}
 		}
-		r.Logger.Errorln(err)
-		return &model.Token{}, &gqlerror.Error{
+		r.log(ctx).Errorln(err)
+		return false, &gqlerror.Error{
 			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 			Extensions: map[string]interface{}{
 				"statusCode": http.StatusInternalServerError,
@@ -367,26 +996,25 @@ func (r *queryResolver) Login(ctx context.Context, input model.LoginInput) (*mod
 			},
 		}
 	}
-	// check if password matches with the one in db
-	if err := bcrypt.CompareHashAndPassword([]byte(user.EncryptedPWD), []byte(input.Password)); err != nil {
-		r.Logger.Errorln(err)
-		return &model.Token{}, &gqlerror.Error{
-			Message: "L'email et le Mot de Passe saisis ne correspondent à aucunes de nos archives, veuillez vérifier vos identifiants puis réessayez !",
+	r.log(ctx).WithField("security_event", "role_granted").Infoln(fmt.Sprintf("Role %s granted to user n°%d", role, userID))
+	return true, nil
+}
+
+func (r *mutationResolver) RevokeRole(ctx context.Context, userID int, role model.Role) (bool, error) {
+	userAuth := authUser(ctx)
+	// someone must stay able to manage the roles
+	if userID == userAuth.UserID && role == model.RoleAdmin {
+		return false, &gqlerror.Error{
+			Message: "Vous ne pouvez pas retirer votre propre rôle administrateur",
 			Extensions: map[string]interface{}{
-				"statusCode": http.StatusNotFound,
-				"statusText": http.StatusText(http.StatusNotFound),
+				"statusCode": http.StatusBadRequest,
+				"statusText": http.StatusText(http.StatusBadRequest),
 			},
 		}
 	}
-	// revokes last refresh token from user
-	if _, err := r.DB.Queryx(`
-		UPDATE user_auths SET is_revoked=?, revoked_at=?
-		WHERE is_revoked = 0 AND revoked_at is NULL AND user_id = ?
-		ORDER BY delivered_at DESC
-		LIMIT 1
-  	`, 1, time.Now(), user.ID); err != nil {
-		r.Logger.Errorln(err)
-		return &model.Token{}, &gqlerror.Error{
+	if err := r.Roles.Revoke(ctx, userID, role.Name()); err != nil {
+		r.log(ctx).Errorln(err)
+		return false, &gqlerror.Error{
 			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 			Extensions: map[string]interface{}{
 				"statusCode": http.StatusInternalServerError,
@@ -394,22 +1022,16 @@ func (r *queryResolver) Login(ctx context.Context, input model.LoginInput) (*mod
 			},
 		}
 	}
+	r.log(ctx).WithField("security_event", "role_revoked").Infoln(fmt.Sprintf("Role %s revoked from user n°%d", role, userID))
+	return true, nil
+}
 
-	// generate new tokens
-	pl := model.CustomPayload{
-		Payload: jwt.Payload{
-			Issuer:         "https://rf.ecrpe.fr",
-			ExpirationTime: jwt.NumericDate(time.Now().Add(1 * time.Minute)), // 12 hours
-			IssuedAt:       jwt.NumericDate(time.Now()),
-		},
-		Username: user.Username,
-		UserID:   user.ID,
-		Teacher:  user.IsTeacher,
-	}
-	jwtoken, err := jwt.Sign(pl, jwt.NewHS512([]byte(r.SecretKey)))
+func (r *mutationResolver) ExportMyData(ctx context.Context) (*model.DataExport, error) {
+	userAuth := authUser(ctx)
+	data, err := r.PersonalData.Export(ctx, userAuth.UserID)
 	if err != nil {
-		r.Logger.Errorln(err)
-		return &model.Token{}, &gqlerror.Error{
+		r.log(ctx).Errorln(err)
+		return nil, &gqlerror.Error{
 			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 			Extensions: map[string]interface{}{
 				"statusCode": http.StatusInternalServerError,
@@ -417,18 +1039,10 @@ func (r *queryResolver) Login(ctx context.Context, input model.LoginInput) (*mod
 			},
 		}
 	}
-	tokens := model.Token{
-		Jwt:          string(jwtoken),
-		RefreshToken: utils.HexKeyGenerator(16),
-	}
-	// push tokens
-	userIP := interceptors.ForIPAddress(ctx)
-	userAgent := interceptors.ForUserAgent(ctx)
-	if _, err = r.DB.Exec(`
-    INSERT INTO user_auths (user_agent, ip_address, refresh_token, delivered_at, on_login, user_id) VALUES (?,?,?,?,?,?)
-  `, userAgent, userIP, tokens.RefreshToken, time.Now(), 1, user.ID); err != nil {
-		r.Logger.Errorln(err)
-		return &model.Token{}, &gqlerror.Error{
+	archive, err := data.Archive()
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return nil, &gqlerror.Error{
 			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 			Extensions: map[string]interface{}{
 				"statusCode": http.StatusInternalServerError,
@@ -436,32 +1050,68 @@ func (r *queryResolver) Login(ctx context.Context, input model.LoginInput) (*mod
 			},
 		}
 	}
-	return &tokens, nil
+	r.log(ctx).WithField("security_event", "data_exported").Infoln("personal data exported")
+	return &model.DataExport{
+		Filename:    fmt.Sprintf("ecrpe-donnees-%d-%s.zip", userAuth.UserID, time.Now().Format("2006-01-02")),
+		ContentType: "application/zip",
+		Content:     base64.StdEncoding.EncodeToString(archive),
+	}, nil
 }
 
-func (r *queryResolver) RefresherCourses(ctx context.Context, input model.RefresherCourseInput) ([]*model.RefresherCourse, error) {
-	rc := make([]*model.RefresherCourse, 0)
-	if input.ByUserID == nil && input.BySubject == nil {
-		if err := r.DB.Select(&rc, "SELECT * FROM refresher_courses"); err != nil {
-			r.Logger.Errorln(err)
-			return rc, &gqlerror.Error{
-				Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+func (r *mutationResolver) DeleteAccount(ctx context.Context, input model.DeleteAccountInput) (bool, error) {
+	userAuth := authUser(ctx)
+	user, err := r.Users.ByID(ctx, userAuth.UserID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return false, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	// the same lockouts as login, a stolen JWT cannot guess the password
+	lockKey := cache.LoginKey(user.Email)
+	if locked := r.lockedFor(ctx, lockKey); locked > 0 {
+		r.log(ctx).Errorln("account deletion attempt on a locked account")
+		return false, tooManyRequests("Trop de mots de passe incorrects", locked)
+	}
+	if _, err := r.Passwords.Verify(user.EncryptedPWD, input.Password); err != nil {
+		r.log(ctx).Errorln(err)
+		r.authFailed(ctx, lockKey)
+		return false, &gqlerror.Error{
+			Message: "Votre mot de passe est incorrect, nous n'avons pu supprimer votre compte",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusUnauthorized,
+				"statusText": http.StatusText(http.StatusUnauthorized),
+			},
+		}
+	}
+	r.authSucceeded(ctx, lockKey)
+	if user.TOTPEnabledAt.Valid {
+		if input.TwoFactorCode == nil {
+			return false, &gqlerror.Error{
+				Message: "Veuillez saisir le code de votre double authentification",
 				Extensions: map[string]interface{}{
-					"statusCode": http.StatusInternalServerError,
-					"statusText": http.StatusText(http.StatusInternalServerError),
+					"statusCode": http.StatusBadRequest,
+					"statusText": http.StatusText(http.StatusBadRequest),
 				},
 			}
 		}
-		return rc, nil
-	}
-	if input.ByUserID != nil {
-		if err := r.DB.Select(&rc, `
-			SELECT rc.* FROM refresher_courses AS rc
-			JOIN users_refresher_courses AS urc ON rc.id = urc.refresher_course_id
-			WHERE urc.user_id = ?
-		`, input.ByUserID); err != nil {
-			r.Logger.Errorln(err)
-			return rc, &gqlerror.Error{
+		twoFactorKey := cache.TwoFactorKey(user.ID)
+		if locked := r.lockedFor(ctx, twoFactorKey); locked > 0 {
+			r.log(ctx).Errorln("two-factor code attempt on a locked account")
+			return false, tooManyRequests("Trop de codes incorrects", locked)
+		}
+		sealedSecret, _, err := r.TwoFactor.Secret(ctx, user.ID)
+		ok := false
+		if err == nil {
+			ok, err = r.checkSecondFactor(ctx, user.ID, sealedSecret, *input.TwoFactorCode)
+		}
+		if err != nil {
+			r.log(ctx).Errorln(err)
+			return false, &gqlerror.Error{
 				Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 				Extensions: map[string]interface{}{
 					"statusCode": http.StatusInternalServerError,
@@ -469,26 +1119,118 @@ func (r *queryResolver) RefresherCourses(ctx context.Context, input model.Refres
 				},
 			}
 		}
-	} else {
-		if err := r.DB.Select(rc, "SELECT * FROM refresher_courses WHERE subject = ?", input.BySubject); err != nil {
-			r.Logger.Errorln(err)
-			return rc, &gqlerror.Error{
-				Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+		if !ok {
+			r.log(ctx).Errorln("wrong code deleting the account")
+			r.authFailed(ctx, twoFactorKey)
+			return false, &gqlerror.Error{
+				Message: "Ce code est incorrect, veuillez réessayer",
 				Extensions: map[string]interface{}{
-					"statusCode": http.StatusInternalServerError,
-					"statusText": http.StatusText(http.StatusInternalServerError),
+					"statusCode": http.StatusUnauthorized,
+					"statusText": http.StatusText(http.StatusUnauthorized),
 				},
 			}
 		}
+		r.authSucceeded(ctx, twoFactorKey)
+	}
+	// the families go with user_auths, the JWTs they issued are denied after
+	auths, err := r.AuthTokens.Active(ctx, user.ID)
+	if err == nil {
+		err = r.PersonalData.Erase(ctx, user.ID)
+	}
+	if err == repository.ErrAuthoredSessions {
+		r.log(ctx).Errorln(err)
+		return false, &gqlerror.Error{
+			Message: "Votre compte est lié à des sessions enregistrées, veuillez contacter le support pour le supprimer",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusConflict,
+				"statusText": http.StatusText(http.StatusConflict),
+			},
+		}
+	}
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return false, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	families := make([]int, 0, len(auths)+1)
+	for _, auth := range auths {
+		families = append(families, auth.FamilyID)
+	}
+	if userAuth.SessionID != 0 {
+		families = append(families, userAuth.SessionID)
+	}
+	r.revokeSessions(ctx, families...)
+	if cookies := interceptors.ForSessionCookies(ctx); cookies != nil {
+		cookies.Clear()
+	}
+	r.log(ctx).WithField("security_event", "account_deleted").Infoln(fmt.Sprintf("User n°%d deleted their account", user.ID))
+	return true, nil
+}
+
+func (r *queryResolver) Login(ctx context.Context, input model.LoginInput) (*model.Token, error) {
+	// before any attempt is counted, deliverTokens would refuse it anyway
+	if err := r.refuseCookieQuery(ctx); err != nil {
+		return &model.Token{}, err
+	}
+	return (&mutationResolver{r.Resolver}).Login(ctx, input)
+}
+
+func (r *queryResolver) Me(ctx context.Context) (*model.Me, error) {
+	userAuth := authUser(ctx)
+	user, err := r.Users.ByID(ctx, userAuth.UserID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return &model.Me{}, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
+	}
+	return &model.Me{User: user, SessionID: userAuth.SessionID}, nil
+}
+
+func (r *queryResolver) RefresherCourses(ctx context.Context, input model.RefresherCourseInput) ([]*model.RefresherCourse, error) {
+	var rc []*model.RefresherCourse
+	var err error
+	switch {
+	case input.ByUserID != nil:
+		// @auth on byUserId checked the JWT
+		var userID int
+		userID, err = r.targetUser(ctx, interceptors.ForUserContext(ctx), input.ByUserID)
+		if err != nil {
+			return rc, err
+		}
+		rc, err = r.Courses.ByUser(ctx, userID)
+	case input.BySubject != nil:
+		rc, err = r.Courses.BySubject(ctx, *input.BySubject)
+	default:
+		rc, err = r.Courses.All(ctx)
+	}
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return rc, &gqlerror.Error{
+			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusInternalServerError,
+				"statusText": http.StatusText(http.StatusInternalServerError),
+			},
+		}
 	}
 	return rc, nil
 }
 
 func (r *queryResolver) RefresherCourse(ctx context.Context, refresherCourseID int) (*model.RefresherCourseResponse, error) {
-	refCourse := model.RefresherCourse{}
-	if err := r.DB.Get(&refCourse, "SELECT * FROM refresher_courses WHERE id = ?", refresherCourseID); err != nil {
-		if err == sql.ErrNoRows {
-			r.Logger.Errorln(err)
+	refCourse, err := r.Courses.ByID(ctx, refresherCourseID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			r.log(ctx).Errorln(err)
 			return &model.RefresherCourseResponse{}, &gqlerror.Error{
 				Message: "Désolé, nous ne pouvons trouver ce cours",
 				Extensions: map[string]interface{}{
@@ -497,7 +1239,7 @@ func (r *queryResolver) RefresherCourse(ctx context.Context, refresherCourseID i
 				},
 			}
 		}
-		r.Logger.Errorln(err)
+		r.log(ctx).Errorln(err)
 		return &model.RefresherCourseResponse{}, &gqlerror.Error{
 			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 			Extensions: map[string]interface{}{
@@ -506,11 +1248,9 @@ func (r *queryResolver) RefresherCourse(ctx context.Context, refresherCourseID i
 			},
 		}
 	}
-	sessions := make([]*model.Session, 0)
-	if err := r.DB.Select(&sessions, `
-		SELECT id, title, section, type, description, session_number, recorded_on, created_at, updated_at FROM sessions WHERE refresher_course_id = ? AND is_ready = 1
-	`, refresherCourseID); err != nil {
-		r.Logger.Errorln(err)
+	sessions, err := r.Sessions.ReadyByRefresherCourse(ctx, refresherCourseID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
 		return &model.RefresherCourseResponse{}, &gqlerror.Error{
 			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 			Extensions: map[string]interface{}{
@@ -519,23 +1259,13 @@ func (r *queryResolver) RefresherCourse(ctx context.Context, refresherCourseID i
 			},
 		}
 	}
-	return &model.RefresherCourseResponse{RefresherCourse: &refCourse, Sessions: sessions}, nil
+	return &model.RefresherCourseResponse{RefresherCourse: refCourse, Sessions: sessions}, nil
 }
 
 func (r *queryResolver) PlayerCheckUser(ctx context.Context) (bool, error) {
-	userAuth := interceptors.ForUserContext(ctx)
-	if !userAuth.IsAuth {
-		r.Logger.Errorln(fmt.Sprintf("User n°%d authentication didn't succeed", userAuth.UserID), "HttpErrorStatus", userAuth.HttpErrorResponse.StatusText)
-		return false, &gqlerror.Error{
-			Message: userAuth.HttpErrorResponse.Message,
-			Extensions: map[string]interface{}{
-				"statusCode": userAuth.HttpErrorResponse.StatusCode,
-				"statusText": userAuth.HttpErrorResponse.StatusText,
-			},
-		}
-	}
+	userAuth := authUser(ctx)
 	userIPAddress := interceptors.ForIPAddress(ctx)
-	lastIPCached, ok := r.RedisCache.GetIP(string(userAuth.UserID))
+	lastIPCached, ok := r.RedisCache.GetIP(ctx, strconv.Itoa(userAuth.UserID))
 	if !ok {
 		return false, &gqlerror.Error{
 			Message: "Oops; une erreur est survenue, veuillez vous réauthentifier",
@@ -547,8 +1277,8 @@ func (r *queryResolver) PlayerCheckUser(ctx context.Context) (bool, error) {
 	}
 	err := utils.IPsChecker(userIPAddress, lastIPCached)
 	if err != nil {
-		r.Logger.Errorln(err)
-		r.RedisCache.DeleteIP(string(userAuth.UserID))
+		r.log(ctx).Errorln(err)
+		r.RedisCache.DeleteIP(ctx, strconv.Itoa(userAuth.UserID))
 		return false, &gqlerror.Error{
 			Message: err.Error(),
 			Extensions: map[string]interface{}{
@@ -558,28 +1288,19 @@ func (r *queryResolver) PlayerCheckUser(ctx context.Context) (bool, error) {
 		}
 	}
 	// overwrite default ttl from 24 hours to 10 minutes for checking IPs while user watching course
-	r.RedisCache.AddIP(string(userAuth.UserID), userIPAddress, 15*time.Minute)
+	r.RedisCache.AddIP(ctx, strconv.Itoa(userAuth.UserID), userIPAddress, 15*time.Minute)
 	return true, nil
 }
 
-func (r *queryResolver) Profile(ctx context.Context, userID int) (*model.User, error) {
-	userAuth := interceptors.ForUserContext(ctx)
-	if !userAuth.IsAuth {
-		r.Logger.Errorln(fmt.Sprintf("User n°%d authentication didn't succeed", userAuth.UserID), "HttpErrorStatus", userAuth.HttpErrorResponse.StatusText)
-		return &model.User{}, &gqlerror.Error{
-			Message: userAuth.HttpErrorResponse.Message,
-			Extensions: map[string]interface{}{
-				"statusCode": userAuth.HttpErrorResponse.StatusCode,
-				"statusText": userAuth.HttpErrorResponse.StatusText,
-			},
-		}
+func (r *queryResolver) Profile(ctx context.Context, userID *int) (*model.User, error) {
+	targetID, err := r.targetUser(ctx, authUser(ctx), userID)
+	if err != nil {
+		return &model.User{}, err
 	}
-	user := model.User{}
-	if err := r.DB.Get(&user, `
-		SELECT id, username, email, created_at, updated_at FROM users WHERE id = ?
-	`, userID); err != nil {
-		r.Logger.Errorln(err)
-		return &user, &gqlerror.Error{
+	user, err := r.Users.ByID(ctx, targetID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
+		return &model.User{}, &gqlerror.Error{
 			Message: "Oops, une erreur est survenue, veuillez vous réauthentifier",
 			Extensions: map[string]interface{}{
 				"statusCode": http.StatusInternalServerError,
@@ -587,25 +1308,27 @@ func (r *queryResolver) Profile(ctx context.Context, userID int) (*model.User, e
 			},
 		}
 	}
-	return &user, nil
+	return user, nil
 }
 
 func (r *queryResolver) SessionCourse(ctx context.Context, input model.SessionInput) (*model.SessionResponse, error) {
-	if _, err := r.DB.Queryx(`
-		SELECT user_id FROM users_refresher_courses
-		WHERE user_id = ? AND refresher_course_id = ?
-	`, input.UserID, input.RefresherCourseID); err != nil {
-		if err == sql.ErrNoRows {
-			r.Logger.Errorln(err)
-			return &model.SessionResponse{}, &gqlerror.Error{
-				Message: "Vous n'avez pas acheté ce cours",
-				Extensions: map[string]interface{}{
-					"statusCode": http.StatusForbidden,
-					"statusText": http.StatusText(http.StatusForbidden),
-				},
-			}
+	userAuth := authUser(ctx)
+	targetID, err := r.targetUser(ctx, userAuth, input.UserID)
+	if err != nil {
+		return &model.SessionResponse{}, err
+	}
+	enrolled, err := r.Enrollments.IsEnrolled(ctx, targetID, input.RefresherCourseID)
+	if err == nil && !enrolled {
+		return &model.SessionResponse{}, &gqlerror.Error{
+			Message: "Vous n'avez pas acheté ce cours",
+			Extensions: map[string]interface{}{
+				"statusCode": http.StatusForbidden,
+				"statusText": http.StatusText(http.StatusForbidden),
+			},
 		}
-		r.Logger.Errorln(err)
+	}
+	if err != nil {
+		r.log(ctx).Errorln(err)
 		return &model.SessionResponse{}, &gqlerror.Error{
 			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 			Extensions: map[string]interface{}{
@@ -614,12 +1337,20 @@ func (r *queryResolver) SessionCourse(ctx context.Context, input model.SessionIn
 			},
 		}
 	}
-	session := model.Session{}
-	if err := r.DB.Get(&session, `
-		SELECT id, title, section, type, description, session_number, recorded_on, created_at, updated_at FROM sessions
-		WHERE id = ? AND refresher_course_id = ? AND is_ready = 1
-	`, input.SessionID, input.RefresherCourseID); err != nil {
-		r.Logger.Errorln(err)
+	session, err := r.Sessions.Ready(ctx, input.RefresherCourseID, input.SessionID)
+	if err != nil {
+		// unknown, of another course or still processing
+		if err == repository.ErrNotFound {
+			r.log(ctx).Errorln(err)
+			return &model.SessionResponse{}, &gqlerror.Error{
+				Message: "Désolé, nous ne pouvons trouver cette session",
+				Extensions: map[string]interface{}{
+					"statusCode": http.StatusNotFound,
+					"statusText": http.StatusText(http.StatusNotFound),
+				},
+			}
+		}
+		r.log(ctx).Errorln(err)
 		return &model.SessionResponse{}, &gqlerror.Error{
 			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 			Extensions: map[string]interface{}{
@@ -628,11 +1359,9 @@ func (r *queryResolver) SessionCourse(ctx context.Context, input model.SessionIn
 			},
 		}
 	}
-	video := model.Video{}
-	if err := r.DB.Get(&video, `
-		SELECT id, path, created_at, updated_at FROM videos WHERE session_id = ?
-	`, input.SessionID); err != nil {
-		r.Logger.Errorln(err)
+	video, err := r.Videos.BySession(ctx, input.SessionID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
 		return &model.SessionResponse{}, &gqlerror.Error{
 			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 			Extensions: map[string]interface{}{
@@ -642,11 +1371,9 @@ func (r *queryResolver) SessionCourse(ctx context.Context, input model.SessionIn
 		}
 	}
 	video.Path = video.Path[20:]
-	classPapers := make([]*model.ClassPaper, 0)
-	if err := r.DB.Select(&classPapers, `
-		SELECT id, title, path, created_at, updated_at FROM class_papers WHERE session_id = ?
-	`, input.SessionID); err != nil {
-		r.Logger.Errorln(err)
+	classPapers, err := r.ClassPapers.BySession(ctx, input.SessionID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
 		return &model.SessionResponse{}, &gqlerror.Error{
 			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 			Extensions: map[string]interface{}{
@@ -656,13 +1383,9 @@ func (r *queryResolver) SessionCourse(ctx context.Context, input model.SessionIn
 		}
 	}
 	classPapers = utils.ClassPapersPathRewrite(classPapers)
-	teacher := model.User{}
-	if err := r.DB.Get(&teacher, `
-		SELECT u.id, u.username, u.fullname FROM users AS u
-		JOIN sessions AS s ON s.user_id = u.id
-		WHERE u.id = ? AND u.is_teacher = 1
-	`, input.UserID); err != nil {
-		r.Logger.Errorln(err)
+	teacher, err := r.Users.SessionTeacher(ctx, input.SessionID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
 		return &model.SessionResponse{}, &gqlerror.Error{
 			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 			Extensions: map[string]interface{}{
@@ -671,41 +1394,29 @@ func (r *queryResolver) SessionCourse(ctx context.Context, input model.SessionIn
 			},
 		}
 	}
-	return &model.SessionResponse{Session: &session, Video: &video, ClassPapers: classPapers, Teacher: &teacher}, nil
+	// progress is the one of the student, not of the staff looking at it
+	if targetID == userAuth.UserID {
+		if err := r.Enrollments.Viewed(ctx, targetID, input.SessionID); err != nil {
+			r.log(ctx).Errorln(err)
+		}
+	}
+	return &model.SessionResponse{Session: session, Video: video, ClassPapers: classPapers, Teacher: teacher}, nil
 }
 
 func (r *queryResolver) AuthTeacher(ctx context.Context, userID int) (bool, error) {
-	user := model.User{}
-	if err := r.DB.Get(&user, "SELECT is_teacher FROM users WHERE id = ?", userID); err != nil {
-		if err == sql.ErrNoRows {
-			r.Logger.Errorln(err)
-			return false, &gqlerror.Error{
-				Message: "Vous n'avez pas accès au portail des professeurs",
-				Extensions: map[string]interface{}{
-					"statusCode": http.StatusForbidden,
-					"statusText": http.StatusText(http.StatusForbidden),
-				},
-			}
-		}
-		r.Logger.Errorln(err)
+	// userId can only be the caller
+	userAuth := authUser(ctx)
+	if userID != userAuth.UserID {
+		r.log(ctx).Errorln(fmt.Sprintf("User n°%d asked the teacher portal of user n°%d", userAuth.UserID, userID))
 		return false, &gqlerror.Error{
-			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
+			Message: "Vous n'avez pas accès au portail des professeurs",
 			Extensions: map[string]interface{}{
-				"statusCode": http.StatusInternalServerError,
-				"statusText": http.StatusText(http.StatusInternalServerError),
+				"statusCode": http.StatusForbidden,
+				"statusText": http.StatusText(http.StatusForbidden),
 			},
 		}
 	}
-	if user.IsTeacher {
-		return true, nil
-	}
-	return false, &gqlerror.Error{
-		Message: "Vous n'avez pas accès au portail des professeurs",
-		Extensions: map[string]interface{}{
-			"statusCode": http.StatusForbidden,
-			"statusText": http.StatusText(http.StatusForbidden),
-		},
-	}
+	return true, nil
 }
 
 func (r *queryResolver) SubjectsEnum(ctx context.Context) ([]string, error) {
@@ -715,9 +1426,9 @@ func (r *queryResolver) SubjectsEnum(ctx context.Context) ([]string, error) {
 }
 
 func (r *queryResolver) TotalHoursCourses(ctx context.Context) (string, error) {
-	durations := []string{}
-	if err := r.DB.Select(&durations, "SELECT duration FROM videos"); err != nil {
-		r.Logger.Errorln(err)
+	durations, err := r.Videos.Durations(ctx)
+	if err != nil {
+		r.log(ctx).Errorln(err)
 		return "", &gqlerror.Error{
 			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 			Extensions: map[string]interface{}{
@@ -729,14 +1440,17 @@ func (r *queryResolver) TotalHoursCourses(ctx context.Context) (string, error) {
 	return utils.DurationCounter(durations), nil
 }
 
+func (r *queryResolver) DeviceSessions(ctx context.Context) ([]*model.DeviceSession, error) {
+	userAuth := authUser(ctx)
+	return r.deviceSessions(ctx, userAuth.UserID, userAuth.SessionID)
+}
+
 func (r *refresherCourseResolver) TotalDuration(ctx context.Context, obj *model.RefresherCourse) (*string, error) {
-	var totalDuration []string
 	var ttDur string
-	if err := r.DB.Select(&totalDuration, `
-		SELECT duration FROM videos AS v JOIN sessions AS s ON v.session_id = s.id
-		WHERE s.refresher_course_id = ?
-	`, obj.ID); err != nil {
-		r.Logger.Errorln(err)
+	refresherCourseID, _ := strconv.Atoi(obj.ID)
+	totalDuration, err := r.Videos.DurationsByRefresherCourse(ctx, refresherCourseID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
 		return &ttDur, &gqlerror.Error{
 			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 			Extensions: map[string]interface{}{
@@ -752,15 +1466,10 @@ func (r *refresherCourseResolver) TotalDuration(ctx context.Context, obj *model.
 func (r *refresherCourseResolver) IsPurchased(ctx context.Context, obj *model.RefresherCourse) (*bool, error) {
 	f := false
 	if user := interceptors.ForUserContext(ctx); user.IsAuth {
-		var pId int
-		if err := r.DB.Get(&pId, `
-      SELECT payment_id FROM users_refresher_courses AS urc
-      WHERE urc.refresher_course_id = ? AND urc.user_id = ?
-    `, obj.ID, user.UserID); err != nil {
-			if pId == 0 {
-				return &f, nil
-			}
-			r.Logger.Errorln(err)
+		refresherCourseID, _ := strconv.Atoi(obj.ID)
+		enrolled, err := r.Enrollments.IsEnrolled(ctx, user.UserID, refresherCourseID)
+		if err != nil {
+			r.log(ctx).Errorln(err)
 			return &f, &gqlerror.Error{
 				Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 				Extensions: map[string]interface{}{
@@ -769,20 +1478,16 @@ func (r *refresherCourseResolver) IsPurchased(ctx context.Context, obj *model.Re
 				},
 			}
 		}
-		t := true
-		return &t, nil
+		return &enrolled, nil
 	}
 	return &f, nil
 }
 
 func (r *refresherCourseResolver) Teachers(ctx context.Context, obj *model.RefresherCourse) ([]*model.User, error) {
-	teachers := make([]*model.User, 0)
-	if err := r.DB.Select(&teachers, `
-		SELECT id, username FROM users WHERE id IN(
-			SELECT DISTINCT user_id FROM sessions WHERE refresher_course_id = ?
-		)
-	`, obj.ID); err != nil {
-		r.Logger.Errorln(err)
+	refresherCourseID, _ := strconv.Atoi(obj.ID)
+	teachers, err := r.Users.TeachersByRefresherCourse(ctx, refresherCourseID)
+	if err != nil {
+		r.log(ctx).Errorln(err)
 		return teachers, &gqlerror.Error{
 			Message: "Oops, une erreur est survenue, merci de réessayer ultérieurement",
 			Extensions: map[string]interface{}{
@@ -794,6 +1499,56 @@ func (r *refresherCourseResolver) Teachers(ctx context.Context, obj *model.Refre
 	return teachers, nil
 }
 
+func (r *userResolver) Fullname(ctx context.Context, obj *model.User) (*string, error) {
+	if !obj.Fullname.Valid {
+		return nil, nil
+	}
+	return &obj.Fullname.String, nil
+}
+
+func (r *userResolver) EmailVerified(ctx context.Context, obj *model.User) (bool, error) {
+	return obj.EmailVerifiedAt.Valid, nil
+}
+
+func (r *userResolver) TwoFactorEnabled(ctx context.Context, obj *model.User) (*bool, error) {
+	// teachers are listed publicly, nobody else learns which accounts lack it
+	if !readsUser(ctx, obj.ID) {
+		return nil, nil
+	}
+	enabled := obj.TOTPEnabledAt.Valid
+	return &enabled, nil
+}
+
+func (r *userResolver) IsTeacher(ctx context.Context, obj *model.User) (*bool, error) {
+	isTeacher := false
+	for _, name := range obj.RoleNames {
+		isTeacher = isTeacher || name == model.RoleTeacher.Name()
+	}
+	return &isTeacher, nil
+}
+
+func (r *userResolver) Roles(ctx context.Context, obj *model.User) ([]model.Role, error) {
+	// the roles of teachers listed publicly stay private, admin or support included
+	if !readsUser(ctx, obj.ID) {
+		return nil, nil
+	}
+	roles := make([]model.Role, len(obj.RoleNames))
+	for i, name := range obj.RoleNames {
+		roles[i] = model.RoleFromName(name)
+	}
+	return roles, nil
+}
+
+func (r *userResolver) UpdatedAt(ctx context.Context, obj *model.User) (*time.Time, error) {
+	if !obj.UpdatedAt.Valid {
+		return nil, nil
+	}
+	return &obj.UpdatedAt.Time, nil
+}
+
+// Me returns generated.MeResolver implementation.
+func (r *Resolver) Me() generated.MeResolver { return &meResolver{r} }
+
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
@@ -805,6 +1560,11 @@ func (r *Resolver) RefresherCourse() generated.RefresherCourseResolver {
 	return &refresherCourseResolver{r}
 }
 
+// User returns generated.UserResolver implementation.
+func (r *Resolver) User() generated.UserResolver { return &userResolver{r} }
+
+type meResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
 type refresherCourseResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
@@ -0,0 +1,220 @@
+package health
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/juleur/becrpe/cache"
+	"github.com/juleur/becrpe/config"
+	"github.com/juleur/becrpe/graph/model"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+
+	checkTimeout = 2 * time.Second
+)
+
+// versionArgs are the arguments printing each packaging tool version,
+// mp4fragment prints it in its usage banner
+var versionArgs = map[string][]string{
+	"mp4fragment": {},
+	"mp4dash":     {"--version"},
+	"ffprobe":     {"-version"},
+}
+
+// Check is the state of one dependency
+type Check struct {
+	Status  string      `json:"status"`
+	Error   string      `json:"error,omitempty"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// Report is the body returned by /healthz and /readyz
+type Report struct {
+	Status string           `json:"status"`
+	Checks map[string]Check `json:"checks"`
+}
+
+// Checker probes every dependency the server relies on
+type Checker struct {
+	DB                *sqlx.DB
+	RedisCache        *cache.Cache
+	Storage           config.Storage
+	UploadFileManager *model.UploadFileManager
+	Logger            *logrus.Logger
+
+	mu       sync.Mutex
+	versions map[string]string
+}
+
+// NewChecker func
+func NewChecker(db *sqlx.DB, redisCache *cache.Cache, storage config.Storage, ufm *model.UploadFileManager, logger *logrus.Logger) *Checker {
+	return &Checker{
+		DB:                db,
+		RedisCache:        redisCache,
+		Storage:           storage,
+		UploadFileManager: ufm,
+		Logger:            logger,
+		versions:          make(map[string]string),
+	}
+}
+
+// Liveness only tells the process serves requests, a dependency down must
+// not get a healthy pod restarted, /readyz reports them
+func (c *Checker) Liveness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, http.StatusOK, Report{Status: StatusUp, Checks: map[string]Check{}})
+	}
+}
+
+// Readiness answers 503 when a dependency is down or the upload queue is saturated
+func (c *Checker) Readiness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := c.Run(r.Context())
+		statusCode := http.StatusOK
+		if report.Status != StatusUp {
+			c.Logger.Warnln("readiness check failed", report.Checks)
+			statusCode = http.StatusServiceUnavailable
+		}
+		writeReport(w, statusCode, report)
+	}
+}
+
+// Run executes every check concurrently
+func (c *Checker) Run(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	checks := map[string]func(context.Context) Check{
+		"mysql":         c.checkMySQL,
+		"redis":         c.checkRedis,
+		"storage_video": func(ctx context.Context) Check { return checkStorage(ctx, c.Storage.VideoURL) },
+		"storage_doc":   func(ctx context.Context) Check { return checkStorage(ctx, c.Storage.DocURL) },
+		"upload_queue":  c.checkUploadQueue,
+	}
+	for _, tool := range model.PackagingTools {
+		tool := tool
+		checks[tool] = func(ctx context.Context) Check { return c.checkTool(ctx, tool) }
+	}
+
+	report := Report{Status: StatusUp, Checks: make(map[string]Check, len(checks))}
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check func(context.Context) Check) {
+			defer wg.Done()
+			result := check(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status != StatusUp {
+				report.Status = StatusDown
+			}
+		}(name, check)
+	}
+	wg.Wait()
+	return report
+}
+
+func (c *Checker) checkMySQL(ctx context.Context) Check {
+	stats := c.DB.Stats()
+	details := map[string]int{
+		"maxOpenConnections": stats.MaxOpenConnections,
+		"openConnections":    stats.OpenConnections,
+		"inUse":              stats.InUse,
+		"idle":               stats.Idle,
+	}
+	if err := c.DB.PingContext(ctx); err != nil {
+		return Check{Status: StatusDown, Error: err.Error(), Details: details}
+	}
+	return Check{Status: StatusUp, Details: details}
+}
+
+func (c *Checker) checkRedis(ctx context.Context) Check {
+	if err := c.RedisCache.Ping(ctx); err != nil {
+		return Check{Status: StatusDown, Error: err.Error()}
+	}
+	return Check{Status: StatusUp}
+}
+
+func (c *Checker) checkUploadQueue(ctx context.Context) Check {
+	stats := c.UploadFileManager.QueueStats()
+	if stats.Saturated() {
+		return Check{Status: StatusDown, Error: "upload queue is saturated", Details: stats}
+	}
+	return Check{Status: StatusUp, Details: stats}
+}
+
+// checkTool looks for the binary in PATH, its version is read once
+func (c *Checker) checkTool(ctx context.Context, tool string) Check {
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		return Check{Status: StatusDown, Error: err.Error()}
+	}
+	c.mu.Lock()
+	version, ok := c.versions[path]
+	c.mu.Unlock()
+	if !ok {
+		// tools print their usage with a non zero exit code, only output matters
+		out, _ := exec.CommandContext(ctx, path, versionArgs[tool]...).CombinedOutput()
+		version = firstLine(out)
+		if version != "" {
+			c.mu.Lock()
+			c.versions[path] = version
+			c.mu.Unlock()
+		}
+	}
+	return Check{Status: StatusUp, Details: map[string]string{"path": path, "version": version}}
+}
+
+// checkStorage only dials the storage server, its endpoints accept POST uploads only
+func checkStorage(ctx context.Context, rawURL string) Check {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Check{Status: StatusDown, Error: err.Error()}
+	}
+	host := u.Host
+	if u.Port() == "" {
+		port := "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+		host = net.JoinHostPort(u.Hostname(), port)
+	}
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", host)
+	if err != nil {
+		return Check{Status: StatusDown, Error: err.Error(), Details: map[string]string{"url": rawURL}}
+	}
+	conn.Close()
+	return Check{Status: StatusUp, Details: map[string]string{"url": rawURL}}
+}
+
+func firstLine(out []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+			return string(line)
+		}
+	}
+	return ""
+}
+
+func writeReport(w http.ResponseWriter, statusCode int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -3,10 +3,14 @@ package interceptors
 import (
 	"context"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/juleur/becrpe/cache"
+	"github.com/juleur/becrpe/config"
 	"github.com/juleur/becrpe/customhttp"
+	"github.com/juleur/becrpe/jwtkeys"
 
 	"github.com/gbrlsnchs/jwt/v3"
 	"github.com/juleur/becrpe/graph/model"
@@ -28,17 +32,49 @@ type HttpErrorResponse struct {
 
 // User struct
 type User struct {
-	Username          string
-	UserID            int
+	Username string
+	UserID   int
+	// SessionID is the refresh token family of the JWT, see DeviceSession
+	SessionID int
+	// TokenID and ExpiresAt are the jti and exp claims, logout denies the JWT until then
+	TokenID   string
+	ExpiresAt time.Time
+	// Roles and Permissions are the names from the JWT claims
+	Roles             []string
+	Permissions       []string
 	IsAuth            bool
 	HttpErrorResponse HttpErrorResponse
 }
 
-// JWTCheck decodes the share session cookie and packs the session into context
-func JWTCheck(secretKey string) func(http.Handler) http.Handler {
+// HasRole answers whether the JWT grants the role named name
+func (u User) HasRole(name string) bool {
+	return contains(u.Roles, name)
+}
+
+// HasPermission answers whether the JWT grants the permission named name
+func (u User) HasPermission(name string) bool {
+	return contains(u.Permissions, name)
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// JWTCheck decodes the JWT of the Authorization header, or of the cookie in
+// cookie session mode, and packs the user into context
+func JWTCheck(jwtConfig config.JWT, keys *jwtkeys.KeySet, redisCache *cache.Cache) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			userJWT := r.Header.Get("Authorization")
+			// cookie sessions send it in the HttpOnly cookie instead
+			if cookies := ForSessionCookies(r.Context()); len(userJWT) == 0 && cookies != nil {
+				userJWT = cookies.JWT()
+			}
 			// Check if header has bearer jwt
 			if len(userJWT) == 0 {
 				user := User{HttpErrorResponse: HttpErrorResponse{
@@ -51,9 +87,8 @@ func JWTCheck(secretKey string) func(http.Handler) http.Handler {
 				return
 			}
 			pl := model.CustomPayload{}
-			signature := jwt.NewHS512([]byte(secretKey))
-			// Validating alg
-			if _, err := jwt.Verify([]byte(strings.TrimPrefix(userJWT, "Bearer ")), signature, &pl, jwt.ValidateHeader); err != nil {
+			// Validating kid and alg
+			if _, err := keys.Verify([]byte(strings.TrimPrefix(userJWT, "Bearer ")), &pl); err != nil {
 				user := User{HttpErrorResponse: HttpErrorResponse{
 					Message:    "Oops, une erreur est survenue, veuillez vous réauthentifier",
 					StatusCode: http.StatusUnauthorized,
@@ -64,16 +99,17 @@ func JWTCheck(secretKey string) func(http.Handler) http.Handler {
 				return
 			}
 			expValidator := jwt.ExpirationTimeValidator(time.Now())
-			issuerValidator := jwt.IssuerValidator("https://rf.ecrpe.fr")
+			issuerValidator := jwt.IssuerValidator(jwtConfig.Issuer)
 			validatePayload := jwt.ValidatePayload(&pl.Payload, issuerValidator, expValidator)
 			// Split "bearer" from JWT
 			// Validating claims
-			if _, err := jwt.Verify([]byte(strings.TrimPrefix(userJWT, "Bearer ")), signature, &pl, validatePayload); err != nil {
+			if _, err := keys.Verify([]byte(strings.TrimPrefix(userJWT, "Bearer ")), &pl, validatePayload); err != nil {
 				switch err {
 				case jwt.ErrExpValidation:
 					user := User{
 						UserID: pl.UserID,
 						HttpErrorResponse: HttpErrorResponse{
+							Message:    "Votre session a expiré, veuillez rafraîchir votre jeton",
 							StatusCode: customhttp.StatusTokenExpired,
 							StatusText: customhttp.StatusText(customhttp.StatusTokenExpired),
 						},
@@ -92,7 +128,30 @@ func JWTCheck(secretKey string) func(http.Handler) http.Handler {
 					return
 				}
 			}
-			user := User{Username: pl.Username, UserID: pl.UserID, IsAuth: true}
+			// the device was logged out, its refresh token is already revoked
+			if (pl.SessionID != 0 && redisCache.IsSessionRevoked(r.Context(), strconv.Itoa(pl.SessionID))) ||
+				(pl.JWTID != "" && redisCache.IsJWTDenied(r.Context(), pl.JWTID)) {
+				user := User{HttpErrorResponse: HttpErrorResponse{
+					Message:    "Votre session a été fermée, veuillez vous réauthentifier",
+					StatusCode: http.StatusUnauthorized,
+					StatusText: http.StatusText(http.StatusUnauthorized),
+				}}
+				ctx := context.WithValue(r.Context(), userJWTCtxKey, user)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+			user := User{
+				Username:    pl.Username,
+				UserID:      pl.UserID,
+				SessionID:   pl.SessionID,
+				TokenID:     pl.JWTID,
+				Roles:       pl.Roles,
+				Permissions: pl.Permissions,
+				IsAuth:      true,
+			}
+			if pl.ExpirationTime != nil {
+				user.ExpiresAt = pl.ExpirationTime.Time
+			}
 			ctx := context.WithValue(r.Context(), userJWTCtxKey, user)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
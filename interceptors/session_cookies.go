@@ -0,0 +1,159 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/juleur/becrpe/config"
+	"github.com/juleur/becrpe/utils"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// Cookies of the cookie session mode, only the CSRF token is readable by scripts
+const (
+	JWTCookie          = "becrpe_jwt"
+	RefreshTokenCookie = "becrpe_refresh_token"
+	CSRFCookie         = "becrpe_csrf"
+	// CSRFHeader repeats the CSRF cookie on the mutations of a cookie session
+	CSRFHeader = "X-CSRF-Token"
+)
+
+var sessionCookiesCtxKey = &JWTContextKey{"sessionCookies"}
+
+// SessionCookies reads the session cookies of a request and sets the ones of its response
+type SessionCookies struct {
+	cfg config.Session
+	w   http.ResponseWriter
+	r   *http.Request
+}
+
+// CookieSession packs the SessionCookies of the request into context in
+// cookie mode, it must run before JWTCheck which then reads the JWT cookie
+func CookieSession(cfg config.Session) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if cfg.Mode != config.SessionCookie {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cookies := &SessionCookies{cfg: cfg, w: w, r: r}
+			ctx := context.WithValue(r.Context(), sessionCookiesCtxKey, cookies)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ForSessionCookies returns the SessionCookies of the request, nil in bearer mode
+func ForSessionCookies(ctx context.Context) *SessionCookies {
+	cookies, _ := ctx.Value(sessionCookiesCtxKey).(*SessionCookies)
+	return cookies
+}
+
+// JWT returns the JWT cookie, empty without it
+func (c *SessionCookies) JWT() string {
+	return c.value(JWTCookie)
+}
+
+// RefreshToken returns the refresh token cookie, empty without it
+func (c *SessionCookies) RefreshToken() string {
+	return c.value(RefreshTokenCookie)
+}
+
+// SetTokens sets the token cookies and a new CSRF token, only mutations may
+// call it since queries skip the CSRF check, see deliverTokens
+func (c *SessionCookies) SetTokens(jwt, refreshToken string) error {
+	csrfToken, err := utils.CSRFTokenGenerator()
+	if err != nil {
+		return err
+	}
+	maxAge := int(c.cfg.CookieMaxAge.Seconds())
+	http.SetCookie(c.w, c.cookie(JWTCookie, jwt, maxAge, true))
+	http.SetCookie(c.w, c.cookie(RefreshTokenCookie, refreshToken, maxAge, true))
+	http.SetCookie(c.w, c.cookie(CSRFCookie, csrfToken, maxAge, false))
+	return nil
+}
+
+// Clear expires the session cookies
+func (c *SessionCookies) Clear() {
+	for _, name := range []string{JWTCookie, RefreshTokenCookie} {
+		http.SetCookie(c.w, c.cookie(name, "", -1, true))
+	}
+	http.SetCookie(c.w, c.cookie(CSRFCookie, "", -1, false))
+}
+
+// CSRFChecked answers whether the request carries no session cookie, so
+// nothing a forged request could use, or a CSRF header matching its cookie
+func (c *SessionCookies) CSRFChecked() bool {
+	if c.JWT() == "" && c.RefreshToken() == "" {
+		return true
+	}
+	csrfToken, header := c.value(CSRFCookie), c.r.Header.Get(CSRFHeader)
+	return csrfToken != "" && subtle.ConstantTimeCompare([]byte(csrfToken), []byte(header)) == 1
+}
+
+func (c *SessionCookies) value(name string) string {
+	cookie, err := c.r.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+func (c *SessionCookies) cookie(name, value string, maxAge int, httpOnly bool) *http.Cookie {
+	sameSite := http.SameSiteLaxMode
+	switch c.cfg.SameSite {
+	case "strict":
+		sameSite = http.SameSiteStrictMode
+	case "none":
+		sameSite = http.SameSiteNoneMode
+	}
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Domain:   c.cfg.CookieDomain,
+		MaxAge:   maxAge,
+		Secure:   !c.cfg.CookieInsecure,
+		HttpOnly: httpOnly,
+		SameSite: sameSite,
+	}
+}
+
+// CSRF refuses the mutations of cookie sessions without the CSRF header,
+// GET requests cannot carry mutations
+type CSRF struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+} = CSRF{}
+
+// ExtensionName func
+func (CSRF) ExtensionName() string {
+	return "CSRF"
+}
+
+// Validate func
+func (CSRF) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptOperation func
+func (CSRF) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	cookies := ForSessionCookies(ctx)
+	if cookies == nil || cookies.CSRFChecked() || !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+	if rc := graphql.GetOperationContext(ctx); rc.Operation == nil || rc.Operation.Operation != ast.Mutation {
+		return next(ctx)
+	}
+	return graphql.OneShot(&graphql.Response{Errors: gqlerror.List{{
+		Message: "Oops, une erreur est survenue, veuillez recharger la page",
+		Extensions: map[string]interface{}{
+			"statusCode": http.StatusForbidden,
+			"statusText": http.StatusText(http.StatusForbidden),
+		},
+	}}})
+}
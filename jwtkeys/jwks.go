@@ -0,0 +1,59 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+)
+
+// JWK is the public part of a key as described by RFC 7517 and RFC 8037
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKS returns every asymmetric key, the secret key is never published
+func (ks *KeySet) JWKS() []JWK {
+	jwks := make([]JWK, 0, len(ks.ordered))
+	for _, k := range ks.ordered {
+		jwk := JWK{Use: "sig", KeyID: k.ID, Algorithm: k.Algorithm}
+		switch pub := k.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		jwks = append(jwks, jwk)
+	}
+	return jwks
+}
+
+// Handler serves the JWKS, mounted on /.well-known/jwks.json
+func (ks *KeySet) Handler() http.Handler {
+	body, _ := json.Marshal(struct {
+		Keys []JWK `json:"keys"`
+	}{ks.JWKS()})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// verifiers refetch within minutes once a new key is published
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.Write(body)
+	})
+}
@@ -0,0 +1,168 @@
+// Package jwtkeys holds the keys signing and verifying access tokens.
+//
+// Tokens are signed by a single key and carry its id in the "kid" header,
+// every configured key verifies the tokens carrying its id, so a new key can
+// sign while the previous one still accepts the tokens it issued.
+// Tokens without kid are HS512 tokens verified with the shared secret.
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+
+	"github.com/gbrlsnchs/jwt/v3"
+	"github.com/gbrlsnchs/jwt/v3/jwtutil"
+	"github.com/juleur/becrpe/config"
+	"github.com/pkg/errors"
+)
+
+// ErrUnknownKey is returned by Verify when no key matches the token kid
+var ErrUnknownKey = errors.New("jwtkeys: unknown kid")
+
+// Key is a verification key, alg also signs when the private key is known
+type Key struct {
+	ID        string
+	Algorithm string
+	public    crypto.PublicKey
+	alg       jwt.Algorithm
+}
+
+// KeySet struct
+type KeySet struct {
+	signing *Key
+	// secret verifies tokens without kid, nil without secret key
+	secret jwt.Algorithm
+	keys   map[string]*Key
+	// ordered keeps the config order for the JWKS
+	ordered []*Key
+}
+
+// Load reads the keys described by cfg
+func Load(cfg config.JWT) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*Key)}
+	if cfg.SecretKey != "" {
+		ks.secret = jwt.NewHS512([]byte(cfg.SecretKey))
+	}
+	for _, kc := range cfg.Keys {
+		k, err := loadKey(kc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "jwtkeys: key %q", kc.ID)
+		}
+		ks.keys[k.ID] = k
+		ks.ordered = append(ks.ordered, k)
+	}
+	if cfg.SigningKeyID != "" {
+		ks.signing = ks.keys[cfg.SigningKeyID]
+		if ks.signing == nil {
+			return nil, errors.Errorf("jwtkeys: signing key %q is not configured", cfg.SigningKeyID)
+		}
+	} else if ks.secret == nil {
+		return nil, errors.New("jwtkeys: neither signing key nor secret key")
+	}
+	return ks, nil
+}
+
+func loadKey(kc config.JWTKey) (*Key, error) {
+	k := &Key{ID: kc.ID, Algorithm: kc.Algorithm}
+	var private crypto.PrivateKey
+	if kc.PrivateKeyFile != "" {
+		block, err := readPEM(kc.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if block.Type == "RSA PRIVATE KEY" {
+			private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		} else {
+			private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to parse %s", kc.PrivateKeyFile)
+		}
+	} else {
+		block, err := readPEM(kc.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if k.public, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			return nil, errors.Wrapf(err, "unable to parse %s", kc.PublicKeyFile)
+		}
+	}
+
+	switch kc.Algorithm {
+	case "RS256":
+		if priv, ok := private.(*rsa.PrivateKey); ok {
+			k.public = &priv.PublicKey
+			k.alg = jwt.NewRS256(jwt.RSAPrivateKey(priv))
+		} else if pub, ok := k.public.(*rsa.PublicKey); ok {
+			k.alg = jwt.NewRS256(jwt.RSAPublicKey(pub))
+		} else {
+			return nil, errors.New("RS256 needs an RSA key")
+		}
+	case "EdDSA":
+		if priv, ok := private.(ed25519.PrivateKey); ok {
+			k.public = priv.Public()
+			k.alg = eddsa{jwt.NewEd25519(jwt.Ed25519PrivateKey(priv))}
+		} else if pub, ok := k.public.(ed25519.PublicKey); ok {
+			k.alg = eddsa{jwt.NewEd25519(jwt.Ed25519PublicKey(pub))}
+		} else {
+			return nil, errors.New("EdDSA needs an Ed25519 key")
+		}
+	default:
+		return nil, errors.Errorf("unsupported algorithm %q", kc.Algorithm)
+	}
+	return k, nil
+}
+
+// eddsa names Ed25519 "EdDSA" in the header as RFC 8037 does,
+// the library writes "Ed25519" which other verifiers reject
+type eddsa struct {
+	*jwt.Ed25519
+}
+
+// Name func
+func (eddsa) Name() string {
+	return "EdDSA"
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read %s", path)
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.Errorf("%s is not PEM encoded", path)
+	}
+	return block, nil
+}
+
+// Sign signs payload with the signing key, or the secret key without signing key
+func (ks *KeySet) Sign(payload interface{}) ([]byte, error) {
+	if ks.signing == nil {
+		return jwt.Sign(payload, ks.secret)
+	}
+	return jwt.Sign(payload, ks.signing.alg, jwt.KeyID(ks.signing.ID))
+}
+
+// Verify checks token with the key named by its kid and the header alg,
+// opts run after, like with jwt.Verify
+func (ks *KeySet) Verify(token []byte, payload interface{}, opts ...jwt.VerifyOption) (jwt.Header, error) {
+	rv := &jwtutil.Resolver{New: func(hd jwt.Header) (jwt.Algorithm, error) {
+		if hd.KeyID == "" {
+			if ks.secret == nil {
+				return nil, ErrUnknownKey
+			}
+			return ks.secret, nil
+		}
+		k, ok := ks.keys[hd.KeyID]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		return k.alg, nil
+	}}
+	return jwt.Verify(token, rv, payload, append([]jwt.VerifyOption{jwt.ValidateHeader}, opts...)...)
+}
@@ -0,0 +1,53 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/sirupsen/logrus"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// GraphQL is a gqlgen handler extension adding the operation name to the request entry
+type GraphQL struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.OperationInterceptor
+} = GraphQL{}
+
+// ExtensionName func
+func (GraphQL) ExtensionName() string {
+	return "RequestLogger"
+}
+
+// Validate func
+func (GraphQL) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptOperation func
+func (GraphQL) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	entry, ok := ctx.Value(entryCtxKey).(*logrus.Entry)
+	if ok && graphql.HasOperationContext(ctx) {
+		if rc := graphql.GetOperationContext(ctx); rc.Operation != nil {
+			ctx = WithEntry(ctx, entry.WithField("operation", rc.Operation.Name))
+		}
+	}
+	return next(ctx)
+}
+
+// ErrorPresenter adds the request ID to the extensions of every error sent to the client,
+// support matches it with the request_id field of our log lines
+func ErrorPresenter(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	requestID := ForRequestID(ctx)
+	if requestID == "" {
+		return gqlErr
+	}
+	if gqlErr.Extensions == nil {
+		gqlErr.Extensions = map[string]interface{}{}
+	}
+	gqlErr.Extensions["requestId"] = requestID
+	return gqlErr
+}
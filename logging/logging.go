@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+
+	"github.com/juleur/becrpe/interceptors"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader is read from the client or the proxy and echoed in every response
+const RequestIDHeader = "X-Request-ID"
+
+// RequestContextKey struct
+type RequestContextKey struct {
+	name string
+}
+
+var (
+	requestIDCtxKey = &RequestContextKey{"requestID"}
+	entryCtxKey     = &RequestContextKey{"logEntry"}
+
+	// an inbound request ID is kept only when it cannot pollute the logs
+	validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{8,64}$`)
+)
+
+// Middleware assigns a request ID then packs a logrus entry carrying it into context,
+// it REQUIRES JWTCheck and GetIPAddress to have run
+func Middleware(logger *logrus.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(RequestIDHeader)
+			if !validRequestID.MatchString(requestID) {
+				requestID = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			fields := logrus.Fields{
+				"request_id": requestID,
+				"ip":         interceptors.ForIPAddress(r.Context()),
+			}
+			if user := interceptors.ForUserContext(r.Context()); user.UserID != 0 {
+				fields["user_id"] = user.UserID
+			}
+			if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.IsValid() {
+				fields["trace_id"] = spanContext.TraceID().String()
+			}
+
+			ctx := context.WithValue(r.Context(), requestIDCtxKey, requestID)
+			ctx = WithEntry(ctx, logger.WithFields(fields))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// WithEntry packs entry into context, later calls to ForContext return it
+func WithEntry(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, entryCtxKey, entry)
+}
+
+// ForContext returns the request-scoped entry, or one built on fallback outside a request
+func ForContext(ctx context.Context, fallback *logrus.Logger) *logrus.Entry {
+	if entry, ok := ctx.Value(entryCtxKey).(*logrus.Entry); ok {
+		return entry
+	}
+	return logrus.NewEntry(fallback)
+}
+
+// ForRequestID finds the request ID from the context, empty outside a request
+func ForRequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDCtxKey).(string)
+	return requestID
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand never fails on supported platforms
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
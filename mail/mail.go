@@ -0,0 +1,105 @@
+// Package mail sends the account emails (password reset, ...).
+//
+// The smtp driver talks to any SMTP server, MailHog (docker-compose service
+// "mail", web UI on :8025) catches them in development. The log driver only
+// logs the messages.
+package mail
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/juleur/becrpe/config"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers messages
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New returns the sender configured by cfg.Driver
+func New(cfg config.Mail, logger *logrus.Logger) (Sender, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return &SMTP{Address: cfg.Address, Username: cfg.Username, Password: cfg.Password, From: cfg.From}, nil
+	case "log":
+		return &Log{Logger: logger}, nil
+	default:
+		return nil, errors.Errorf("mail: unknown driver %q", cfg.Driver)
+	}
+}
+
+// SMTP sends through an SMTP server, with PLAIN auth when Username is set
+type SMTP struct {
+	Address  string
+	Username string
+	Password string
+	From     string
+}
+
+// Send func
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	var auth smtp.Auth
+	if s.Username != "" {
+		host, _, err := net.SplitHostPort(s.Address)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+	// the envelope wants the bare address of "Name <address>"
+	from, err := netmail.ParseAddress(s.From)
+	if err != nil {
+		return errors.Wrapf(err, "mail: from %q", s.From)
+	}
+	// net/smtp ignores contexts, the send runs aside so ctx still bounds the caller
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(s.Address, auth, from.Address, []string{msg.To}, s.format(msg))
+	}()
+	select {
+	case err := <-done:
+		return errors.Wrapf(err, "mail: sending %q to %s", msg.Subject, msg.To)
+	case <-ctx.Done():
+		return errors.WithStack(ctx.Err())
+	}
+}
+
+func (s *SMTP) format(msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.From)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// Log writes the messages in the log instead of sending them
+type Log struct {
+	Logger *logrus.Logger
+}
+
+// Send func
+func (l *Log) Send(ctx context.Context, msg Message) error {
+	l.Logger.WithFields(logrus.Fields{"to": msg.To, "subject": msg.Subject}).Infoln(msg.Body)
+	return nil
+}
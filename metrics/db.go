@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// dbStatsCollector exposes sql.DBStats on each scrape
+type dbStatsCollector struct {
+	db *sqlx.DB
+
+	maxOpen           *prometheus.Desc
+	open              *prometheus.Desc
+	inUse             *prometheus.Desc
+	idle              *prometheus.Desc
+	waitCount         *prometheus.Desc
+	waitDuration      *prometheus.Desc
+	maxIdleClosed     *prometheus.Desc
+	maxLifetimeClosed *prometheus.Desc
+}
+
+// RegisterDB exposes the connection pool stats of db
+func RegisterDB(db *sqlx.DB) {
+	fqName := func(name string) string {
+		return prometheus.BuildFQName(namespace, "db", name)
+	}
+	prometheus.MustRegister(&dbStatsCollector{
+		db:                db,
+		maxOpen:           prometheus.NewDesc(fqName("max_open_connections"), "Maximum number of open connections.", nil, nil),
+		open:              prometheus.NewDesc(fqName("open_connections"), "Established connections, in use and idle.", nil, nil),
+		inUse:             prometheus.NewDesc(fqName("in_use_connections"), "Connections currently in use.", nil, nil),
+		idle:              prometheus.NewDesc(fqName("idle_connections"), "Idle connections.", nil, nil),
+		waitCount:         prometheus.NewDesc(fqName("wait_count_total"), "Connections waited for.", nil, nil),
+		waitDuration:      prometheus.NewDesc(fqName("wait_duration_seconds_total"), "Time blocked waiting for a new connection.", nil, nil),
+		maxIdleClosed:     prometheus.NewDesc(fqName("max_idle_closed_total"), "Connections closed due to SetMaxIdleConns.", nil, nil),
+		maxLifetimeClosed: prometheus.NewDesc(fqName("max_lifetime_closed_total"), "Connections closed due to SetConnMaxLifetime.", nil, nil),
+	})
+}
+
+// Describe func
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxOpen
+	ch <- c.open
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitCount
+	ch <- c.waitDuration
+	ch <- c.maxIdleClosed
+	ch <- c.maxLifetimeClosed
+}
+
+// Collect func
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.db.Stats()
+	ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.maxIdleClosed, prometheus.CounterValue, float64(stats.MaxIdleClosed))
+	ch <- prometheus.MustNewConstMetric(c.maxLifetimeClosed, prometheus.CounterValue, float64(stats.MaxLifetimeClosed))
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+var (
+	graphqlOperationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "graphql",
+		Name:      "operation_duration_seconds",
+		Help:      "Duration of GraphQL operations.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"type", "field"})
+	graphqlOperationErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "graphql",
+		Name:      "operation_errors_total",
+		Help:      "GraphQL errors returned per operation.",
+	}, []string{"type", "field"})
+)
+
+// GraphQL is a gqlgen handler extension recording latency and error count per
+// operation, labelled by its root field: operation names and aliases are chosen
+// by the clients, the fields of the schema are bounded
+type GraphQL struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = GraphQL{}
+
+// ExtensionName func
+func (GraphQL) ExtensionName() string {
+	return "PrometheusMetrics"
+}
+
+// Validate func
+func (GraphQL) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse func
+func (GraphQL) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	start := time.Now()
+	resp := next(ctx)
+
+	opType, field := "unknown", "unknown"
+	if graphql.HasOperationContext(ctx) {
+		rc := graphql.GetOperationContext(ctx)
+		if rc.Operation != nil {
+			opType = string(rc.Operation.Operation)
+			field = rootField(rc)
+		}
+	}
+	graphqlOperationDuration.WithLabelValues(opType, field).Observe(time.Since(start).Seconds())
+	if resp != nil && len(resp.Errors) > 0 {
+		graphqlOperationErrors.WithLabelValues(opType, field).Add(float64(len(resp.Errors)))
+	}
+	return resp
+}
+
+// rootField is the schema field the operation selects, "multiple" when it
+// selects several so the label values stay the root fields of the schema
+func rootField(rc *graphql.OperationContext) string {
+	root := "Query"
+	switch rc.Operation.Operation {
+	case ast.Mutation:
+		root = "Mutation"
+	case ast.Subscription:
+		root = "Subscription"
+	}
+	field := "unknown"
+	for _, f := range graphql.CollectFields(rc, rc.Operation.SelectionSet, []string{root}) {
+		switch {
+		case f.Definition == nil:
+		case field == "unknown" || field == f.Name:
+			field = f.Name
+		default:
+			return "multiple"
+		}
+	}
+	return field
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/juleur/becrpe/graph/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "becrpe"
+
+var (
+	uploadStageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "upload",
+		Name:      "stage_duration_seconds",
+		Help:      "Duration of each upload pipeline stage (mp4fragment, mp4dash, ffprobe, upload).",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 2400},
+	}, []string{"stage", "status"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		graphqlOperationDuration,
+		graphqlOperationErrors,
+		redisCommandDuration,
+		uploadStageDuration,
+	)
+}
+
+// Handler serves every registered metric in the prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveUploadStage records how long a pipeline stage took,
+// it matches UploadFileManager.StageObserver
+func ObserveUploadStage(stage string, duration time.Duration, err error) {
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	uploadStageDuration.WithLabelValues(stage, status).Observe(duration.Seconds())
+}
+
+// RegisterUploadQueue exposes the pipeline load read from stats on each scrape,
+// /readyz fails once running_jobs plus queued_jobs reach both caps
+func RegisterUploadQueue(stats func() model.UploadQueueStats) {
+	prometheus.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "upload",
+			Name:      "running_jobs",
+			Help:      "Upload jobs currently packaged or sent to storage.",
+		}, func() float64 { return float64(stats().RunningJobs) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "upload",
+			Name:      "queued_jobs",
+			Help:      "Upload jobs waiting for a running slot.",
+		}, func() float64 { return float64(stats().QueuedJobs) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "upload",
+			Name:      "max_running_jobs",
+			Help:      "Upload jobs allowed to run at once.",
+		}, func() float64 { return float64(stats().MaxRunningJobs) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "upload",
+			Name:      "max_queued_jobs",
+			Help:      "Upload jobs allowed to wait before /readyz fails.",
+		}, func() float64 { return float64(stats().MaxQueuedJobs) }),
+	)
+}
@@ -0,0 +1,298 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+const (
+	// lockName is held with GET_LOCK while migrating so concurrent instances wait
+	lockName    = "becrpe_schema_migrations"
+	lockTimeout = 60
+)
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one embedded version, Up and Down hold its SQL
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is a migration and when it was applied, AppliedAt is zero when pending
+type Status struct {
+	Migration
+	AppliedAt time.Time
+	Dirty     bool
+}
+
+// Migrator applies the embedded migrations and records them in schema_migrations
+type Migrator struct {
+	db         *sqlx.DB
+	logger     *logrus.Logger
+	migrations []Migration
+}
+
+// New opens a dedicated connection pool, migration files contain several
+// statements which the server connections must not accept
+func New(dsn string, logger *logrus.Logger) (*Migrator, error) {
+	mysqlConfig, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	mysqlConfig.MultiStatements = true
+	mysqlConfig.ParseTime = true
+	db, err := sqlx.Connect("mysql", mysqlConfig.FormatDSN())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	migrations, err := load()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Migrator{db: db, logger: logger, migrations: migrations}, nil
+}
+
+// Close closes the migration connection pool
+func (m *Migrator) Close() error {
+	return m.db.Close()
+}
+
+// Up applies every pending migration and returns how many were applied
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		statuses, err := m.clean(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			if !s.AppliedAt.IsZero() {
+				continue
+			}
+			if err := m.apply(ctx, conn, s.Migration, true); err != nil {
+				return err
+			}
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		statuses, err := m.clean(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(statuses) - 1; i >= 0 && reverted < steps; i-- {
+			if statuses[i].AppliedAt.IsZero() {
+				continue
+			}
+			if err := m.apply(ctx, conn, statuses[i].Migration, false); err != nil {
+				return err
+			}
+			reverted++
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status lists every embedded migration in version order
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.locked(ctx, func(conn *sql.Conn) (err error) {
+		statuses, err = m.status(ctx, conn)
+		return err
+	})
+	return statuses, err
+}
+
+// locked runs fn on a single connection holding the migration lock,
+// GET_LOCK is bound to the connection which took it
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer conn.Close()
+
+	var got sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, lockTimeout).Scan(&got); err != nil {
+		return errors.WithStack(err)
+	}
+	if got.Int64 != 1 {
+		return errors.Errorf("migrations: lock %s held by another instance for more than %ds", lockName, lockTimeout)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName); err != nil {
+			m.logger.Errorln(err)
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT NOT NULL,
+			name VARCHAR(100) NOT NULL,
+			dirty TINYINT(1) NOT NULL DEFAULT 0,
+			applied_at DATETIME NOT NULL,
+			PRIMARY KEY (version))
+		ENGINE = InnoDB
+	`); err != nil {
+		return errors.WithStack(err)
+	}
+	return fn(conn)
+}
+
+func (m *Migrator) status(ctx context.Context, conn *sql.Conn) ([]Status, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, dirty, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+	type row struct {
+		dirty     bool
+		appliedAt time.Time
+	}
+	applied := map[int]row{}
+	for rows.Next() {
+		var version int
+		r := row{}
+		if err := rows.Scan(&version, &r.dirty, &r.appliedAt); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		applied[version] = r
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		r := applied[migration.Version]
+		statuses = append(statuses, Status{Migration: migration, AppliedAt: r.appliedAt, Dirty: r.dirty})
+		delete(applied, migration.Version)
+	}
+	for version := range applied {
+		return nil, errors.Errorf("migrations: version %d is applied but unknown to this binary", version)
+	}
+	return statuses, nil
+}
+
+// clean is status refusing to go on when a previous migration failed halfway
+func (m *Migrator) clean(ctx context.Context, conn *sql.Conn) ([]Status, error) {
+	statuses, err := m.status(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range statuses {
+		if s.Dirty {
+			// DDL is not transactional, only a human knows what was left behind
+			return nil, errors.Errorf(
+				"migrations: version %d is dirty, fix the schema then delete its schema_migrations row",
+				s.Version,
+			)
+		}
+	}
+	return statuses, nil
+}
+
+// apply marks the version dirty while its SQL runs so a failure is not retried blindly
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration, up bool) error {
+	direction, query := "up", migration.Up
+	if !up {
+		direction, query = "down", migration.Down
+	}
+	if up {
+		if _, err := conn.ExecContext(ctx,
+			"INSERT INTO schema_migrations (version, name, dirty, applied_at) VALUES (?,?,1,?)",
+			migration.Version, migration.Name, time.Now(),
+		); err != nil {
+			return errors.WithStack(err)
+		}
+	} else if _, err := conn.ExecContext(ctx,
+		"UPDATE schema_migrations SET dirty = 1 WHERE version = ?", migration.Version,
+	); err != nil {
+		return errors.WithStack(err)
+	}
+
+	start := time.Now()
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		return errors.Wrapf(err, "migrations: %04d_%s %s", migration.Version, migration.Name, direction)
+	}
+
+	var err error
+	if up {
+		_, err = conn.ExecContext(ctx, "UPDATE schema_migrations SET dirty = 0 WHERE version = ?", migration.Version)
+	} else {
+		_, err = conn.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", migration.Version)
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	m.logger.Infof("migration %04d_%s %s applied in %s", migration.Version, migration.Name, direction, time.Since(start))
+	return nil
+}
+
+// load reads the embedded files, every version needs both its up and down file
+func load() ([]Migration, error) {
+	entries, err := files.ReadDir("sql")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, errors.Errorf("migrations: unexpected file %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		b, err := files.ReadFile(path.Join("sql", entry.Name()))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, errors.Errorf("migrations: version %d has two names, %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(b)
+		} else {
+			migration.Down = string(b)
+		}
+	}
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, errors.Errorf("migrations: version %d needs both up and down files", migration.Version)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS `users_AFTER_DELETE`;
+DROP TABLE IF EXISTS `upload_jobs`;
+DROP TABLE IF EXISTS `class_papers`;
+DROP TABLE IF EXISTS `user_auths`;
+DROP TABLE IF EXISTS `videos`;
+DROP TABLE IF EXISTS `users_refresher_courses`;
+DROP TABLE IF EXISTS `payments`;
+DROP TABLE IF EXISTS `sessions`;
+DROP TABLE IF EXISTS `refresher_courses`;
+DROP TABLE IF EXISTS `users`;
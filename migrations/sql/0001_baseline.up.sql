@@ -1,29 +1,11 @@
--- MySQL Script generated by MySQL Workbench
--- Sat Feb 22 16:29:24 2020
--- Model: New Model    Version: 1.0
--- MySQL Workbench Forward Engineering
-
-SET @OLD_UNIQUE_CHECKS=@@UNIQUE_CHECKS, UNIQUE_CHECKS=0;
-SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0;
-SET @OLD_SQL_MODE=@@SQL_MODE, SQL_MODE='ONLY_FULL_GROUP_BY,STRICT_TRANS_TABLES,NO_ZERO_IN_DATE,NO_ZERO_DATE,ERROR_FOR_DIVISION_BY_ZERO,NO_ENGINE_SUBSTITUTION';
-
--- -----------------------------------------------------
--- Schema ecrpe
--- -----------------------------------------------------
-DROP SCHEMA IF EXISTS `ecrpe` ;
+-- Baseline converted from the MySQL Workbench script sql/ecrpe.sql,
+-- tables are created only when missing so existing databases adopt it as is.
+-- The schema itself comes from the DSN.
 
 -- -----------------------------------------------------
--- Schema ecrpe
+-- Table `users`
 -- -----------------------------------------------------
-CREATE SCHEMA IF NOT EXISTS `ecrpe` DEFAULT CHARACTER SET utf8 ;
-USE `ecrpe` ;
-
--- -----------------------------------------------------
--- Table `ecrpe`.`users`
--- -----------------------------------------------------
-DROP TABLE IF EXISTS `ecrpe`.`users` ;
-
-CREATE TABLE IF NOT EXISTS `ecrpe`.`users` (
+CREATE TABLE IF NOT EXISTS `users` (
   `id` SMALLINT NOT NULL AUTO_INCREMENT,
   `username` VARCHAR(20) NOT NULL,
   `fullname` VARCHAR(20) NULL,
@@ -38,15 +20,14 @@ CREATE TABLE IF NOT EXISTS `ecrpe`.`users` (
   UNIQUE INDEX `users_username_unique` (`username` ASC) VISIBLE,
   UNIQUE INDEX `users_fullname_unique` (`fullname` ASC) VISIBLE,
   INDEX `users_is_teacher_idx` (`is_teacher` ASC) VISIBLE)
-ENGINE = InnoDB;
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8;
 
 
 -- -----------------------------------------------------
--- Table `ecrpe`.`refresher_courses`
+-- Table `refresher_courses`
 -- -----------------------------------------------------
-DROP TABLE IF EXISTS `ecrpe`.`refresher_courses` ;
-
-CREATE TABLE IF NOT EXISTS `ecrpe`.`refresher_courses` (
+CREATE TABLE IF NOT EXISTS `refresher_courses` (
   `id` SMALLINT NOT NULL AUTO_INCREMENT,
   `subject` ENUM('ECONOMICS', 'FRENCH', 'MATHETIMATICS') NOT NULL,
   `year` VARCHAR(4) NOT NULL,
@@ -56,15 +37,14 @@ CREATE TABLE IF NOT EXISTS `ecrpe`.`refresher_courses` (
   `updated_at` DATETIME NULL DEFAULT NULL,
   PRIMARY KEY (`id`),
   INDEX `rc_subject` (`subject` ASC) VISIBLE)
-ENGINE = InnoDB;
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8;
 
 
 -- -----------------------------------------------------
--- Table `ecrpe`.`sessions`
+-- Table `sessions`
 -- -----------------------------------------------------
-DROP TABLE IF EXISTS `ecrpe`.`sessions` ;
-
-CREATE TABLE IF NOT EXISTS `ecrpe`.`sessions` (
+CREATE TABLE IF NOT EXISTS `sessions` (
   `id` MEDIUMINT NOT NULL AUTO_INCREMENT,
   `title` VARCHAR(50) NOT NULL,
   `section` ENUM('DIALECTICAL', 'SCIENTIFIC') NOT NULL,
@@ -83,38 +63,36 @@ CREATE TABLE IF NOT EXISTS `ecrpe`.`sessions` (
   INDEX `sessions_is_ready_idx` (`is_ready` ASC) VISIBLE,
   CONSTRAINT `fk_refresher_course_id_sessions`
     FOREIGN KEY (`refresher_course_id`)
-    REFERENCES `ecrpe`.`refresher_courses` (`id`)
+    REFERENCES `refresher_courses` (`id`)
     ON DELETE CASCADE
     ON UPDATE CASCADE,
   CONSTRAINT `fk_user_id_sessions`
     FOREIGN KEY (`user_id`)
-    REFERENCES `ecrpe`.`users` (`id`)
+    REFERENCES `users` (`id`)
     ON DELETE NO ACTION
     ON UPDATE NO ACTION)
-ENGINE = InnoDB;
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8;
 
 
 -- -----------------------------------------------------
--- Table `ecrpe`.`payments`
+-- Table `payments`
 -- -----------------------------------------------------
-DROP TABLE IF EXISTS `ecrpe`.`payments` ;
-
-CREATE TABLE IF NOT EXISTS `ecrpe`.`payments` (
+CREATE TABLE IF NOT EXISTS `payments` (
   `id` MEDIUMINT NOT NULL AUTO_INCREMENT,
   `paypal_payer_id` VARCHAR(25) NOT NULL,
   `paypal_order_id` VARCHAR(25) NOT NULL,
   `created_at` DATETIME NOT NULL,
   PRIMARY KEY (`id`),
   UNIQUE INDEX `payments_paypal_order_id_unique` (`paypal_order_id` ASC) VISIBLE)
-ENGINE = InnoDB;
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8;
 
 
 -- -----------------------------------------------------
--- Table `ecrpe`.`users_refresher_courses`
+-- Table `users_refresher_courses`
 -- -----------------------------------------------------
-DROP TABLE IF EXISTS `ecrpe`.`users_refresher_courses` ;
-
-CREATE TABLE IF NOT EXISTS `ecrpe`.`users_refresher_courses` (
+CREATE TABLE IF NOT EXISTS `users_refresher_courses` (
   `payment_id` MEDIUMINT NULL,
   `user_id` SMALLINT NULL,
   `refresher_course_id` SMALLINT NULL,
@@ -123,28 +101,27 @@ CREATE TABLE IF NOT EXISTS `ecrpe`.`users_refresher_courses` (
   INDEX `urc_user_id_idx` (`user_id` ASC) VISIBLE,
   CONSTRAINT `fk_user_id_urc`
     FOREIGN KEY (`user_id`)
-    REFERENCES `ecrpe`.`users` (`id`)
+    REFERENCES `users` (`id`)
     ON DELETE CASCADE
     ON UPDATE CASCADE,
   CONSTRAINT `fk_refresher_course_id_urc`
     FOREIGN KEY (`refresher_course_id`)
-    REFERENCES `ecrpe`.`refresher_courses` (`id`)
+    REFERENCES `refresher_courses` (`id`)
     ON DELETE NO ACTION
     ON UPDATE NO ACTION,
   CONSTRAINT `fk_payment_id_urc`
     FOREIGN KEY (`payment_id`)
-    REFERENCES `ecrpe`.`payments` (`id`)
+    REFERENCES `payments` (`id`)
     ON DELETE NO ACTION
     ON UPDATE NO ACTION)
-ENGINE = InnoDB;
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8;
 
 
 -- -----------------------------------------------------
--- Table `ecrpe`.`videos`
+-- Table `videos`
 -- -----------------------------------------------------
-DROP TABLE IF EXISTS `ecrpe`.`videos` ;
-
-CREATE TABLE IF NOT EXISTS `ecrpe`.`videos` (
+CREATE TABLE IF NOT EXISTS `videos` (
   `id` MEDIUMINT NOT NULL AUTO_INCREMENT,
   `path` VARCHAR(100) NULL DEFAULT NULL,
   `duration` VARCHAR(7) NULL DEFAULT NULL,
@@ -157,18 +134,17 @@ CREATE TABLE IF NOT EXISTS `ecrpe`.`videos` (
   INDEX `videos_session_id_idx` (`session_id` ASC) VISIBLE,
   CONSTRAINT `fk_session_id_videos`
     FOREIGN KEY (`session_id`)
-    REFERENCES `ecrpe`.`sessions` (`id`)
+    REFERENCES `sessions` (`id`)
     ON DELETE NO ACTION
     ON UPDATE NO ACTION)
-ENGINE = InnoDB;
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8;
 
 
 -- -----------------------------------------------------
--- Table `ecrpe`.`user_auths`
+-- Table `user_auths`
 -- -----------------------------------------------------
-DROP TABLE IF EXISTS `ecrpe`.`user_auths` ;
-
-CREATE TABLE IF NOT EXISTS `ecrpe`.`user_auths` (
+CREATE TABLE IF NOT EXISTS `user_auths` (
   `id` INT NOT NULL AUTO_INCREMENT,
   `user_agent` VARCHAR(150) NOT NULL,
   `ip_address` VARCHAR(40) NOT NULL,
@@ -185,18 +161,17 @@ CREATE TABLE IF NOT EXISTS `ecrpe`.`user_auths` (
   INDEX `ua_composite_idx` (`is_revoked` ASC, `revoked_at` ASC, `user_id` ASC) VISIBLE,
   CONSTRAINT `fk_user_id_user_auths`
     FOREIGN KEY (`user_id`)
-    REFERENCES `ecrpe`.`users` (`id`)
+    REFERENCES `users` (`id`)
     ON DELETE NO ACTION
     ON UPDATE NO ACTION)
-ENGINE = InnoDB;
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8;
 
 
 -- -----------------------------------------------------
--- Table `ecrpe`.`class_papers`
+-- Table `class_papers`
 -- -----------------------------------------------------
-DROP TABLE IF EXISTS `ecrpe`.`class_papers` ;
-
-CREATE TABLE IF NOT EXISTS `ecrpe`.`class_papers` (
+CREATE TABLE IF NOT EXISTS `class_papers` (
   `id` MEDIUMINT NOT NULL AUTO_INCREMENT,
   `title` VARCHAR(50) NULL,
   `path` VARCHAR(100) NULL,
@@ -208,27 +183,44 @@ CREATE TABLE IF NOT EXISTS `ecrpe`.`class_papers` (
   UNIQUE INDEX `class_papers_path_unique` (`path` ASC) VISIBLE,
   CONSTRAINT `fk_session_id_class_papers`
     FOREIGN KEY (`session_id`)
-    REFERENCES `ecrpe`.`sessions` (`id`)
+    REFERENCES `sessions` (`id`)
     ON DELETE NO ACTION
     ON UPDATE NO ACTION)
-ENGINE = InnoDB;
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8;
 
-USE `ecrpe`;
 
-DELIMITER $$
+-- -----------------------------------------------------
+-- Table `upload_jobs`
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `upload_jobs` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `kind` ENUM('VIDEO', 'DOC') NOT NULL,
+  `session_id` MEDIUMINT NOT NULL,
+  `dir_path` VARCHAR(100) NOT NULL,
+  `source_path` VARCHAR(255) NOT NULL,
+  `title` VARCHAR(50) NOT NULL DEFAULT '',
+  `created_at` DATETIME NOT NULL,
+  `failed_at` DATETIME NULL DEFAULT NULL,
+  `error` VARCHAR(255) NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  INDEX `upload_jobs_session_id_idx` (`session_id` ASC) VISIBLE,
+  CONSTRAINT `fk_session_id_upload_jobs`
+    FOREIGN KEY (`session_id`)
+    REFERENCES `sessions` (`id`)
+    ON DELETE CASCADE
+    ON UPDATE CASCADE)
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8;
 
-USE `ecrpe`$$
-DROP TRIGGER IF EXISTS `ecrpe`.`users_AFTER_DELETE` $$
-USE `ecrpe`$$
-CREATE DEFINER = CURRENT_USER TRIGGER `ecrpe`.`users_AFTER_DELETE` AFTER DELETE ON `users` FOR EACH ROW
+
+-- -----------------------------------------------------
+-- Trigger `users_AFTER_DELETE`
+-- -----------------------------------------------------
+DROP TRIGGER IF EXISTS `users_AFTER_DELETE`;
+
+CREATE DEFINER = CURRENT_USER TRIGGER `users_AFTER_DELETE` AFTER DELETE ON `users` FOR EACH ROW
 BEGIN
 	delete from user_auths AS ua where ua.user_id = OLD.id;
     delete from users_refresher_courses AS urc where urc.user_id = OLD.id;
-END$$
-
-
-DELIMITER ;
-
-SET SQL_MODE=@OLD_SQL_MODE;
-SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS;
-SET UNIQUE_CHECKS=@OLD_UNIQUE_CHECKS;
+END;
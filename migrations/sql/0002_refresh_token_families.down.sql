@@ -0,0 +1,4 @@
+ALTER TABLE `user_auths`
+  DROP INDEX `ua_family_id_idx`,
+  DROP COLUMN `replaced_by_id`,
+  DROP COLUMN `family_id`;
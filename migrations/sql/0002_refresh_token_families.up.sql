@@ -0,0 +1,13 @@
+-- Every login starts a family, the tokens issued by refreshing it share its
+-- family_id (the id of the login row). replaced_by_id is set when a token is
+-- rotated, presenting such a token again revokes the whole family.
+ALTER TABLE `user_auths`
+  ADD COLUMN `family_id` INT NULL AFTER `user_id`,
+  ADD COLUMN `replaced_by_id` INT NULL AFTER `family_id`;
+
+-- tokens issued before families each become their own family
+UPDATE `user_auths` SET `family_id` = `id`;
+
+ALTER TABLE `user_auths`
+  MODIFY `family_id` INT NOT NULL,
+  ADD INDEX `ua_family_id_idx` (`family_id` ASC) VISIBLE;
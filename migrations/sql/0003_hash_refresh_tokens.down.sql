@@ -0,0 +1,4 @@
+-- the tokens stay revoked, their plaintext is gone
+ALTER TABLE `user_auths`
+  RENAME INDEX `ua_refresh_token_hash_unique` TO `ua_refresh_token_unique`,
+  CHANGE `refresh_token_hash` `refresh_token` VARCHAR(64) NOT NULL;
@@ -0,0 +1,12 @@
+-- Refresh tokens are stored as HMAC-SHA256 keyed by jwt.refresh_token_key.
+-- The plaintext tokens cannot be hashed here without the key, they are revoked
+-- and overwritten by their unkeyed hash which no lookup ever matches: their
+-- holders get the usual "réauthentifiez-vous" answer on their next refresh.
+ALTER TABLE `user_auths`
+  CHANGE `refresh_token` `refresh_token_hash` CHAR(64) NOT NULL,
+  RENAME INDEX `ua_refresh_token_unique` TO `ua_refresh_token_hash_unique`;
+
+UPDATE `user_auths`
+SET `refresh_token_hash` = SHA2(`refresh_token_hash`, 256),
+    `revoked_at` = COALESCE(`revoked_at`, NOW()),
+    `is_revoked` = 1;
@@ -0,0 +1,19 @@
+-- One-time password reset tokens, only their SHA-256 is stored.
+-- A token is spent once used_at is set or after expires_at.
+CREATE TABLE IF NOT EXISTS `password_resets` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `user_id` SMALLINT NOT NULL,
+  `token_hash` CHAR(64) NOT NULL,
+  `created_at` DATETIME NOT NULL,
+  `expires_at` DATETIME NOT NULL,
+  `used_at` DATETIME NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `pr_token_hash_unique` (`token_hash` ASC) VISIBLE,
+  INDEX `pr_user_id_idx` (`user_id` ASC) VISIBLE,
+  CONSTRAINT `fk_user_id_password_resets`
+    FOREIGN KEY (`user_id`)
+    REFERENCES `users` (`id`)
+    ON DELETE CASCADE
+    ON UPDATE NO ACTION)
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8;
@@ -0,0 +1,4 @@
+-- NULL until the user opens the link emailed on signup or email change,
+-- existing accounts start unverified and can ask for a new link
+ALTER TABLE `users`
+  ADD COLUMN `email_verified_at` DATETIME NULL DEFAULT NULL AFTER `email`;
@@ -0,0 +1,21 @@
+-- TOTP two-factor authentication, totp_secret is encrypted with
+-- two_factor.encryption_key and only enabled once totp_enabled_at is set
+ALTER TABLE `users`
+  ADD COLUMN `totp_secret` VARCHAR(255) NULL DEFAULT NULL AFTER `encrypted_pwd`,
+  ADD COLUMN `totp_enabled_at` DATETIME NULL DEFAULT NULL AFTER `totp_secret`;
+
+-- Single use recovery codes, only their SHA-256 is stored
+CREATE TABLE IF NOT EXISTS `recovery_codes` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `user_id` SMALLINT NOT NULL,
+  `code_hash` CHAR(64) NOT NULL,
+  `used_at` DATETIME NULL,
+  PRIMARY KEY (`id`),
+  INDEX `rc_user_id_idx` (`user_id` ASC) VISIBLE,
+  CONSTRAINT `fk_user_id_recovery_codes`
+    FOREIGN KEY (`user_id`)
+    REFERENCES `users` (`id`)
+    ON DELETE CASCADE
+    ON UPDATE NO ACTION)
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8;
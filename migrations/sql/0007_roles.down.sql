@@ -0,0 +1,13 @@
+ALTER TABLE `users`
+  ADD COLUMN `is_teacher` TINYINT(1) NOT NULL DEFAULT 0 AFTER `totp_enabled_at`,
+  ADD INDEX `users_is_teacher_idx` (`is_teacher` ASC) VISIBLE;
+
+UPDATE `users` AS u
+JOIN `user_roles` AS ur ON ur.user_id = u.id
+JOIN `roles` AS r ON r.id = ur.role_id AND r.name = 'teacher'
+SET u.is_teacher = 1;
+
+DROP TABLE IF EXISTS `user_roles`;
+DROP TABLE IF EXISTS `role_permissions`;
+DROP TABLE IF EXISTS `permissions`;
+DROP TABLE IF EXISTS `roles`;
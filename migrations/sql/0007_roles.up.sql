@@ -0,0 +1,74 @@
+-- Roles and the permissions they grant replace users.is_teacher, the
+-- permission names are the Permission enum of the GraphQL schema in lower case
+-- and the memory repository mirrors this seed.
+CREATE TABLE IF NOT EXISTS `roles` (
+  `id` TINYINT NOT NULL AUTO_INCREMENT,
+  `name` VARCHAR(32) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `roles_name_unique` (`name` ASC) VISIBLE)
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8;
+
+CREATE TABLE IF NOT EXISTS `permissions` (
+  `id` TINYINT NOT NULL AUTO_INCREMENT,
+  `name` VARCHAR(32) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `permissions_name_unique` (`name` ASC) VISIBLE)
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8;
+
+CREATE TABLE IF NOT EXISTS `role_permissions` (
+  `role_id` TINYINT NOT NULL,
+  `permission_id` TINYINT NOT NULL,
+  PRIMARY KEY (`role_id`, `permission_id`),
+  CONSTRAINT `fk_role_id_role_permissions`
+    FOREIGN KEY (`role_id`)
+    REFERENCES `roles` (`id`)
+    ON DELETE CASCADE
+    ON UPDATE NO ACTION,
+  CONSTRAINT `fk_permission_id_role_permissions`
+    FOREIGN KEY (`permission_id`)
+    REFERENCES `permissions` (`id`)
+    ON DELETE CASCADE
+    ON UPDATE NO ACTION)
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8;
+
+CREATE TABLE IF NOT EXISTS `user_roles` (
+  `user_id` SMALLINT NOT NULL,
+  `role_id` TINYINT NOT NULL,
+  PRIMARY KEY (`user_id`, `role_id`),
+  INDEX `ur_role_id_idx` (`role_id` ASC) VISIBLE,
+  CONSTRAINT `fk_user_id_user_roles`
+    FOREIGN KEY (`user_id`)
+    REFERENCES `users` (`id`)
+    ON DELETE CASCADE
+    ON UPDATE NO ACTION,
+  CONSTRAINT `fk_role_id_user_roles`
+    FOREIGN KEY (`role_id`)
+    REFERENCES `roles` (`id`)
+    ON DELETE CASCADE
+    ON UPDATE NO ACTION)
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8;
+
+INSERT INTO `roles` (`name`) VALUES ('student'), ('teacher'), ('admin'), ('support');
+INSERT INTO `permissions` (`name`) VALUES ('course_publish'), ('user_read'), ('role_manage');
+INSERT INTO `role_permissions` (`role_id`, `permission_id`)
+SELECT r.id, p.id FROM `roles` AS r JOIN `permissions` AS p ON (r.name, p.name) IN (
+  ('teacher', 'course_publish'),
+  ('admin', 'course_publish'),
+  ('admin', 'user_read'),
+  ('admin', 'role_manage'),
+  ('support', 'user_read')
+);
+
+-- every account is a student, the flagged ones are teachers too
+INSERT INTO `user_roles` (`user_id`, `role_id`)
+SELECT u.id, r.id FROM `users` AS u JOIN `roles` AS r ON r.name = 'student';
+INSERT INTO `user_roles` (`user_id`, `role_id`)
+SELECT u.id, r.id FROM `users` AS u JOIN `roles` AS r ON r.name = 'teacher' WHERE u.is_teacher = 1;
+
+ALTER TABLE `users`
+  DROP INDEX `users_is_teacher_idx`,
+  DROP COLUMN `is_teacher`;
@@ -0,0 +1,21 @@
+-- Ready sessions each user opened through sessionCourse, the progress
+-- shown by `me` in their refresher courses
+CREATE TABLE IF NOT EXISTS `session_views` (
+  `user_id` SMALLINT NOT NULL,
+  `session_id` MEDIUMINT NOT NULL,
+  `first_viewed_at` DATETIME NOT NULL,
+  `last_viewed_at` DATETIME NOT NULL,
+  PRIMARY KEY (`user_id`, `session_id`),
+  INDEX `sv_session_id_idx` (`session_id` ASC) VISIBLE,
+  CONSTRAINT `fk_user_id_session_views`
+    FOREIGN KEY (`user_id`)
+    REFERENCES `users` (`id`)
+    ON DELETE CASCADE
+    ON UPDATE NO ACTION,
+  CONSTRAINT `fk_session_id_session_views`
+    FOREIGN KEY (`session_id`)
+    REFERENCES `sessions` (`id`)
+    ON DELETE CASCADE
+    ON UPDATE NO ACTION)
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8;
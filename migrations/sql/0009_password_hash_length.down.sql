@@ -0,0 +1,2 @@
+-- fails while a hash is longer than 100 characters, those users must reset their password first
+ALTER TABLE `users` MODIFY COLUMN `encrypted_pwd` VARCHAR(100) NOT NULL;
@@ -0,0 +1,2 @@
+-- argon2id hashes carry their parameters, salt and key, see package password
+ALTER TABLE `users` MODIFY COLUMN `encrypted_pwd` VARCHAR(255) NOT NULL;
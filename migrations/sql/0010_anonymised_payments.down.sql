@@ -0,0 +1,3 @@
+ALTER TABLE `payments`
+  DROP COLUMN `refresher_course_id`,
+  DROP COLUMN `anonymised_at`;
@@ -0,0 +1,10 @@
+-- Payments outlive the accounts for accounting, they keep the course sold
+-- once users_refresher_courses loses the user, and deleteAccount blanks the
+-- PayPal payer id (anonymised_at) while keeping the order id
+ALTER TABLE `payments`
+  ADD COLUMN `refresher_course_id` SMALLINT NULL AFTER `paypal_order_id`,
+  ADD COLUMN `anonymised_at` DATETIME NULL DEFAULT NULL AFTER `created_at`;
+
+UPDATE `payments` AS p
+JOIN `users_refresher_courses` AS urc ON urc.payment_id = p.id
+SET p.refresher_course_id = urc.refresher_course_id;
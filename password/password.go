@@ -0,0 +1,106 @@
+// Package password hashes the passwords of the users.
+//
+// New hashes use the configured algorithm, argon2id in the PHC string format
+// ($argon2id$v=19$m=65536,t=3,p=2$salt$key) or bcrypt, so every hash carries
+// its algorithm and parameters. Verify accepts both and tells when a hash
+// should be replaced, letting logins upgrade the stored hashes.
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/juleur/becrpe/config"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrMismatch is returned by Verify when the password does not match the hash
+	ErrMismatch = errors.New("password: hash does not match the password")
+	// ErrUnknownHash is returned by Verify for a hash of no supported algorithm
+	ErrUnknownHash = errors.New("password: unknown hash format")
+)
+
+// Hasher hashes with the configured algorithm and verifies any supported hash
+type Hasher struct {
+	cfg config.Password
+}
+
+// New returns a Hasher using cfg, validated by config.Validate
+func New(cfg config.Password) *Hasher {
+	return &Hasher{cfg: cfg}
+}
+
+// Hash returns the encoded hash of password
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.cfg.Algorithm == config.PasswordBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cfg.BcryptCost)
+		return string(hash), errors.WithStack(err)
+	}
+	salt := make([]byte, h.cfg.Argon2.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", errors.WithStack(err)
+	}
+	p := h.cfg.Argon2
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify checks password against hash, ErrMismatch when it does not match,
+// rehash tells whether hash uses another algorithm or weaker parameters than new hashes
+func (h *Hasher) Verify(hash, password string) (rehash bool, err error) {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		return h.verifyArgon2(hash, password)
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return false, ErrUnknownHash
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			return false, ErrMismatch
+		}
+		return false, errors.WithStack(err)
+	}
+	return h.cfg.Algorithm != config.PasswordBcrypt || cost < h.cfg.BcryptCost, nil
+}
+
+func (h *Hasher) verifyArgon2(hash, password string) (bool, error) {
+	// "", "argon2id", "v=19", "m=65536,t=3,p=2", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false, ErrUnknownHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, ErrUnknownHash
+	}
+	var p config.Argon2
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil ||
+		p.Iterations == 0 || p.Parallelism == 0 {
+		return false, ErrUnknownHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, ErrUnknownHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return false, ErrUnknownHash
+	}
+	p.SaltLength, p.KeyLength = uint32(len(salt)), uint32(len(key))
+	computed := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	if subtle.ConstantTimeCompare(key, computed) != 1 {
+		return false, ErrMismatch
+	}
+	cfg := h.cfg.Argon2
+	weaker := p.Memory < cfg.Memory || p.Iterations < cfg.Iterations || p.Parallelism < cfg.Parallelism ||
+		p.SaltLength < cfg.SaltLength || p.KeyLength < cfg.KeyLength
+	return h.cfg.Algorithm != config.PasswordArgon2id || weaker, nil
+}
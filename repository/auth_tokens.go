@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/juleur/becrpe/graph/model"
+)
+
+type mysqlAuthTokens struct {
+	db *sqlx.DB
+}
+
+func (a *mysqlAuthTokens) Create(ctx context.Context, auth *model.UserAuth) error {
+	tx, err := a.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return mysqlErr(err)
+	}
+	// no-op once committed
+	defer tx.Rollback()
+	if err := insertAuth(ctx, tx, auth); err != nil {
+		return err
+	}
+	return mysqlErr(tx.Commit())
+}
+
+// insertAuth inserts auth, a login token is the first of its family and names it
+func insertAuth(ctx context.Context, tx *sqlx.Tx, auth *model.UserAuth) error {
+	if auth.DeliveredAt.IsZero() {
+		auth.DeliveredAt = time.Now()
+	}
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO user_auths (user_agent, ip_address, refresh_token_hash, delivered_at, on_login, on_refresh, user_id, family_id)
+		VALUES (?,?,?,?,?,?,?,?)
+	`, auth.UserAgent, auth.IPAddress, auth.RefreshTokenHash, auth.DeliveredAt, auth.OnLogin, auth.OnRefresh, auth.UserID, auth.FamilyID)
+	if err != nil {
+		return mysqlErr(err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return mysqlErr(err)
+	}
+	auth.ID = int(id)
+	if auth.FamilyID == 0 {
+		auth.FamilyID = auth.ID
+		if _, err := tx.ExecContext(ctx, "UPDATE user_auths SET family_id = id WHERE id = ?", auth.ID); err != nil {
+			return mysqlErr(err)
+		}
+	}
+	return nil
+}
+
+func (a *mysqlAuthTokens) ByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (*model.UserAuth, error) {
+	auth := model.UserAuth{}
+	if err := a.db.GetContext(ctx, &auth, `
+		SELECT ua.id, ua.user_id, ua.refresh_token_hash, ua.delivered_at, ua.is_revoked, ua.family_id, ua.replaced_by_id,
+		u.username FROM user_auths AS ua
+		JOIN users AS u ON u.id = ua.user_id
+		WHERE ua.refresh_token_hash = ?
+	`, refreshTokenHash); err != nil {
+		return nil, mysqlErr(err)
+	}
+	return &auth, nil
+}
+
+func (a *mysqlAuthTokens) Rotate(ctx context.Context, old, next *model.UserAuth) error {
+	tx, err := a.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return mysqlErr(err)
+	}
+	// no-op once committed
+	defer tx.Rollback()
+	next.FamilyID = old.FamilyID
+	if err := insertAuth(ctx, tx, next); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `
+		UPDATE user_auths SET is_revoked=?, revoked_at=?, replaced_by_id=?
+		WHERE is_revoked=0 AND revoked_at is NULL AND id=?
+	`, 1, time.Now(), next.ID, old.ID)
+	if err != nil {
+		return mysqlErr(err)
+	}
+	// a concurrent request presented the same token first
+	if n, err := res.RowsAffected(); err != nil {
+		return mysqlErr(err)
+	} else if n == 0 {
+		return ErrTokenReused
+	}
+	return mysqlErr(tx.Commit())
+}
+
+func (a *mysqlAuthTokens) Revoke(ctx context.Context, userID int, refreshTokenHash string) error {
+	_, err := a.db.ExecContext(ctx, `
+		UPDATE user_auths SET is_revoked=?, revoked_at=?
+		WHERE is_revoked=0 AND revoked_at is NULL AND user_id=? AND refresh_token_hash=?
+	`, 1, time.Now(), userID, refreshTokenHash)
+	return mysqlErr(err)
+}
+
+func (a *mysqlAuthTokens) Active(ctx context.Context, userID int) ([]*model.UserAuth, error) {
+	auths := []*model.UserAuth{}
+	if err := a.db.SelectContext(ctx, &auths, `
+		SELECT id, user_agent, ip_address, delivered_at, on_login, on_refresh, user_id, family_id FROM user_auths
+		WHERE is_revoked = 0 AND revoked_at is NULL AND user_id = ?
+		ORDER BY delivered_at DESC
+	`, userID); err != nil {
+		return nil, mysqlErr(err)
+	}
+	return auths, nil
+}
+
+func (a *mysqlAuthTokens) RevokeFamily(ctx context.Context, userID, familyID int) (int, error) {
+	res, err := a.db.ExecContext(ctx, `
+		UPDATE user_auths SET is_revoked=?, revoked_at=?
+		WHERE is_revoked = 0 AND revoked_at is NULL AND user_id = ? AND family_id = ?
+	`, 1, time.Now(), userID, familyID)
+	if err != nil {
+		return 0, mysqlErr(err)
+	}
+	n, err := res.RowsAffected()
+	return int(n), mysqlErr(err)
+}
+
+func (a *mysqlAuthTokens) RevokeAll(ctx context.Context, userID int) ([]int, error) {
+	tx, err := a.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, mysqlErr(err)
+	}
+	// no-op once committed
+	defer tx.Rollback()
+	families := []int{}
+	if err := tx.SelectContext(ctx, &families, `
+		SELECT DISTINCT family_id FROM user_auths
+		WHERE is_revoked = 0 AND revoked_at is NULL AND user_id = ?
+		FOR UPDATE
+	`, userID); err != nil {
+		return nil, mysqlErr(err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE user_auths SET is_revoked=?, revoked_at=?
+		WHERE is_revoked = 0 AND revoked_at is NULL AND user_id = ?
+	`, 1, time.Now(), userID); err != nil {
+		return nil, mysqlErr(err)
+	}
+	return families, mysqlErr(tx.Commit())
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/juleur/becrpe/graph/model"
+)
+
+type mysqlEnrollments struct {
+	db *sqlx.DB
+}
+
+func (e *mysqlEnrollments) Purchase(ctx context.Context, userID, refresherCourseID int, paypalPayerID, paypalOrderID string) error {
+	tx, err := e.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return mysqlErr(err)
+	}
+	// no-op once committed
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx,
+		"INSERT INTO payments (paypal_payer_id, paypal_order_id, refresher_course_id, created_at) VALUES (?,?,?,?)",
+		paypalPayerID, paypalOrderID, refresherCourseID, time.Now(),
+	)
+	if err != nil {
+		return mysqlErr(err)
+	}
+	paymentID, err := res.LastInsertId()
+	if err != nil {
+		return mysqlErr(err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO users_refresher_courses (payment_id, user_id, refresher_course_id) VALUES (?,?,?)",
+		paymentID, userID, refresherCourseID,
+	); err != nil {
+		return mysqlErr(err)
+	}
+	return mysqlErr(tx.Commit())
+}
+
+func (e *mysqlEnrollments) IsEnrolled(ctx context.Context, userID, refresherCourseID int) (bool, error) {
+	var count int
+	if err := e.db.GetContext(ctx, &count, `
+		SELECT COUNT(*) FROM users_refresher_courses WHERE user_id = ? AND refresher_course_id = ?
+	`, userID, refresherCourseID); err != nil {
+		return false, mysqlErr(err)
+	}
+	return count > 0, nil
+}
+
+func (e *mysqlEnrollments) Purchased(ctx context.Context, userID int) ([]*model.PurchasedCourse, error) {
+	courses := make([]*model.PurchasedCourse, 0)
+	if err := e.db.SelectContext(ctx, &courses, `
+		SELECT
+			rc.id AS `+"`refresher_course.id`"+`, rc.subject AS `+"`refresher_course.subject`"+`,
+			rc.year AS `+"`refresher_course.year`"+`, rc.is_finished AS `+"`refresher_course.is_finished`"+`,
+			rc.price AS `+"`refresher_course.price`"+`, rc.created_at AS `+"`refresher_course.created_at`"+`,
+			rc.updated_at AS `+"`refresher_course.updated_at`"+`,
+			p.created_at AS purchased_at,
+			(SELECT COUNT(*) FROM sessions AS s WHERE s.refresher_course_id = rc.id AND s.is_ready = 1) AS total_sessions,
+			(SELECT COUNT(*) FROM session_views AS sv JOIN sessions AS s ON s.id = sv.session_id
+				WHERE sv.user_id = urc.user_id AND s.refresher_course_id = rc.id AND s.is_ready = 1) AS viewed_sessions
+		FROM users_refresher_courses AS urc
+		JOIN refresher_courses AS rc ON rc.id = urc.refresher_course_id
+		LEFT JOIN payments AS p ON p.id = urc.payment_id
+		WHERE urc.user_id = ?
+		ORDER BY p.created_at DESC
+	`, userID); err != nil {
+		return courses, mysqlErr(err)
+	}
+	return courses, nil
+}
+
+func (e *mysqlEnrollments) Viewed(ctx context.Context, userID, sessionID int) error {
+	now := time.Now()
+	_, err := e.db.ExecContext(ctx, `
+		INSERT INTO session_views (user_id, session_id, first_viewed_at, last_viewed_at) VALUES (?,?,?,?)
+		ON DUPLICATE KEY UPDATE last_viewed_at = VALUES(last_viewed_at)
+	`, userID, sessionID, now, now)
+	return mysqlErr(err)
+}
@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/juleur/becrpe/graph/model"
+	"github.com/juleur/becrpe/repository"
+)
+
+type authTokens struct {
+	s *Store
+}
+
+func (a *authTokens) Create(ctx context.Context, auth *model.UserAuth) error {
+	a.s.mu.Lock()
+	defer a.s.mu.Unlock()
+	return a.insert(auth)
+}
+
+// insert requires a.s.mu
+func (a *authTokens) insert(auth *model.UserAuth) error {
+	for _, existing := range a.s.userAuths {
+		if existing.RefreshTokenHash == auth.RefreshTokenHash {
+			return errDuplicateRefreshToken
+		}
+	}
+	if auth.DeliveredAt.IsZero() {
+		auth.DeliveredAt = time.Now()
+	}
+	a.s.lastUserAuthID++
+	auth.ID = a.s.lastUserAuthID
+	if auth.FamilyID == 0 {
+		auth.FamilyID = auth.ID
+	}
+	stored := *auth
+	a.s.userAuths = append(a.s.userAuths, &stored)
+	return nil
+}
+
+func (a *authTokens) ByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (*model.UserAuth, error) {
+	a.s.mu.Lock()
+	defer a.s.mu.Unlock()
+	for _, auth := range a.s.userAuths {
+		if auth.RefreshTokenHash != refreshTokenHash {
+			continue
+		}
+		user := a.s.userByID(auth.UserID)
+		if user == nil {
+			break
+		}
+		found := *auth
+		found.Username = user.Username
+		return &found, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (a *authTokens) Rotate(ctx context.Context, old, next *model.UserAuth) error {
+	a.s.mu.Lock()
+	defer a.s.mu.Unlock()
+	var stored *model.UserAuth
+	for _, auth := range a.s.userAuths {
+		if auth.ID == old.ID {
+			stored = auth
+		}
+	}
+	if stored == nil || stored.IsRevoked {
+		return repository.ErrTokenReused
+	}
+	next.FamilyID = stored.FamilyID
+	if err := a.insert(next); err != nil {
+		return err
+	}
+	stored.IsRevoked, stored.RevokedAt = true, time.Now()
+	stored.ReplacedByID = sql.NullInt64{Int64: int64(next.ID), Valid: true}
+	return nil
+}
+
+func (a *authTokens) Revoke(ctx context.Context, userID int, refreshTokenHash string) error {
+	a.s.mu.Lock()
+	defer a.s.mu.Unlock()
+	for _, auth := range a.s.userAuths {
+		if auth.UserID == userID && auth.RefreshTokenHash == refreshTokenHash && !auth.IsRevoked {
+			auth.IsRevoked, auth.RevokedAt = true, time.Now()
+		}
+	}
+	return nil
+}
+
+func (a *authTokens) Active(ctx context.Context, userID int) ([]*model.UserAuth, error) {
+	a.s.mu.Lock()
+	defer a.s.mu.Unlock()
+	auths := []*model.UserAuth{}
+	for _, auth := range a.s.userAuths {
+		if auth.UserID == userID && !auth.IsRevoked {
+			found := *auth
+			auths = append(auths, &found)
+		}
+	}
+	sort.SliceStable(auths, func(i, j int) bool {
+		return auths[i].DeliveredAt.After(auths[j].DeliveredAt)
+	})
+	return auths, nil
+}
+
+func (a *authTokens) RevokeFamily(ctx context.Context, userID, familyID int) (int, error) {
+	a.s.mu.Lock()
+	defer a.s.mu.Unlock()
+	n := 0
+	for _, auth := range a.s.userAuths {
+		if auth.UserID == userID && auth.FamilyID == familyID && !auth.IsRevoked {
+			auth.IsRevoked, auth.RevokedAt = true, time.Now()
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (a *authTokens) RevokeAll(ctx context.Context, userID int) ([]int, error) {
+	a.s.mu.Lock()
+	defer a.s.mu.Unlock()
+	families := []int{}
+	seen := make(map[int]bool)
+	for _, auth := range a.s.userAuths {
+		if auth.UserID == userID && !auth.IsRevoked {
+			auth.IsRevoked, auth.RevokedAt = true, time.Now()
+			if !seen[auth.FamilyID] {
+				seen[auth.FamilyID] = true
+				families = append(families, auth.FamilyID)
+			}
+		}
+	}
+	return families, nil
+}
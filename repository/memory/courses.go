@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/juleur/becrpe/graph/model"
+	"github.com/juleur/becrpe/repository"
+	"github.com/pkg/errors"
+)
+
+var (
+	errDuplicateRefreshToken = errors.New("memory: duplicate refresh token")
+	errDuplicatePayment      = errors.New("memory: duplicate paypal order")
+	errUnknownReference      = errors.New("memory: foreign key constraint fails")
+)
+
+type refresherCourses struct {
+	s *Store
+}
+
+func (rc *refresherCourses) All(ctx context.Context) ([]*model.RefresherCourse, error) {
+	return rc.filter(func(*model.RefresherCourse) bool { return true }), nil
+}
+
+func (rc *refresherCourses) ByID(ctx context.Context, id int) (*model.RefresherCourse, error) {
+	courses := rc.filter(func(course *model.RefresherCourse) bool { return course.ID == strconv.Itoa(id) })
+	if len(courses) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return courses[0], nil
+}
+
+func (rc *refresherCourses) BySubject(ctx context.Context, subject model.SubjectEnum) ([]*model.RefresherCourse, error) {
+	return rc.filter(func(course *model.RefresherCourse) bool {
+		return course.Subject != nil && *course.Subject == subject
+	}), nil
+}
+
+func (rc *refresherCourses) ByUser(ctx context.Context, userID int) ([]*model.RefresherCourse, error) {
+	rc.s.mu.Lock()
+	enrolled := map[string]bool{}
+	for _, e := range rc.s.enrollments {
+		if e.userID == userID {
+			enrolled[strconv.Itoa(e.refresherCourseID)] = true
+		}
+	}
+	rc.s.mu.Unlock()
+	return rc.filter(func(course *model.RefresherCourse) bool { return enrolled[course.ID] }), nil
+}
+
+// filter returns copies so callers cannot alter the store
+func (rc *refresherCourses) filter(keep func(*model.RefresherCourse) bool) []*model.RefresherCourse {
+	rc.s.mu.Lock()
+	defer rc.s.mu.Unlock()
+	courses := make([]*model.RefresherCourse, 0)
+	for _, course := range rc.s.refresherCourses {
+		if keep(course) {
+			found := *course
+			courses = append(courses, &found)
+		}
+	}
+	return courses
+}
+
+type sessions struct {
+	s *Store
+}
+
+func (ss *sessions) Create(ctx context.Context, teacherID int, input model.NewSessionInput) (int, error) {
+	ss.s.mu.Lock()
+	defer ss.s.mu.Unlock()
+	if ss.s.userByID(teacherID) == nil {
+		return 0, errUnknownReference
+	}
+	found := false
+	for _, course := range ss.s.refresherCourses {
+		found = found || course.ID == strconv.Itoa(input.RefresherCourseID)
+	}
+	if !found {
+		return 0, errUnknownReference
+	}
+	ss.s.lastSessionID++
+	id := ss.s.lastSessionID
+	now := time.Now()
+	title, section, typ, recordedOn := input.Title, input.Section, input.Type, input.RecordedOn
+	ss.s.sessions = append(ss.s.sessions, &session{
+		Session: model.Session{
+			ID:            strconv.Itoa(id),
+			Title:         &title,
+			Section:       &section,
+			Type:          &typ,
+			Description:   input.Description,
+			SessionNumber: input.SessionNumber,
+			RecordedOn:    &recordedOn,
+			CreatedAt:     &now,
+		},
+		refresherCourseID: input.RefresherCourseID,
+		teacherID:         teacherID,
+	})
+	return id, nil
+}
+
+func (ss *sessions) Delete(ctx context.Context, sessionID int) error {
+	ss.s.mu.Lock()
+	defer ss.s.mu.Unlock()
+	sessions := ss.s.sessions[:0]
+	for _, sess := range ss.s.sessions {
+		if sess.ID != strconv.Itoa(sessionID) || sess.isReady {
+			sessions = append(sessions, sess)
+		}
+	}
+	ss.s.sessions = sessions
+	return nil
+}
+
+func (ss *sessions) Ready(ctx context.Context, refresherCourseID, sessionID int) (*model.Session, error) {
+	ss.s.mu.Lock()
+	defer ss.s.mu.Unlock()
+	sess := ss.s.sessionByID(sessionID)
+	if sess == nil || sess.refresherCourseID != refresherCourseID || !sess.isReady {
+		return nil, repository.ErrNotFound
+	}
+	found := sess.Session
+	return &found, nil
+}
+
+func (ss *sessions) ReadyByRefresherCourse(ctx context.Context, refresherCourseID int) ([]*model.Session, error) {
+	ss.s.mu.Lock()
+	defer ss.s.mu.Unlock()
+	found := make([]*model.Session, 0)
+	for _, sess := range ss.s.sessions {
+		if sess.refresherCourseID == refresherCourseID && sess.isReady {
+			session := sess.Session
+			found = append(found, &session)
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/juleur/becrpe/graph/model"
+	"github.com/juleur/becrpe/repository"
+)
+
+type videos struct {
+	s *Store
+}
+
+func (v *videos) BySession(ctx context.Context, sessionID int) (*model.Video, error) {
+	v.s.mu.Lock()
+	defer v.s.mu.Unlock()
+	for _, video := range v.s.videos {
+		if video.SessionID == sessionID {
+			found := *video
+			return &found, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (v *videos) Durations(ctx context.Context) ([]string, error) {
+	v.s.mu.Lock()
+	defer v.s.mu.Unlock()
+	durations := []string{}
+	for _, video := range v.s.videos {
+		durations = append(durations, video.Duration)
+	}
+	return durations, nil
+}
+
+func (v *videos) DurationsByRefresherCourse(ctx context.Context, refresherCourseID int) ([]string, error) {
+	v.s.mu.Lock()
+	defer v.s.mu.Unlock()
+	durations := []string{}
+	for _, video := range v.s.videos {
+		if sess := v.s.sessionByID(video.SessionID); sess != nil && sess.refresherCourseID == refresherCourseID {
+			durations = append(durations, video.Duration)
+		}
+	}
+	return durations, nil
+}
+
+type classPapers struct {
+	s *Store
+}
+
+func (cp *classPapers) BySession(ctx context.Context, sessionID int) ([]*model.ClassPaper, error) {
+	cp.s.mu.Lock()
+	defer cp.s.mu.Unlock()
+	found := make([]*model.ClassPaper, 0)
+	for _, classPaper := range cp.s.classPapers {
+		if classPaper.SessionID == sessionID {
+			paper := *classPaper
+			found = append(found, &paper)
+		}
+	}
+	return found, nil
+}
+
+type enrollments struct {
+	s *Store
+}
+
+func (e *enrollments) Purchase(ctx context.Context, userID, refresherCourseID int, paypalPayerID, paypalOrderID string) error {
+	e.s.mu.Lock()
+	defer e.s.mu.Unlock()
+	for _, p := range e.s.payments {
+		if p.paypalOrderID == paypalOrderID {
+			return errDuplicatePayment
+		}
+	}
+	found := false
+	for _, course := range e.s.refresherCourses {
+		found = found || course.ID == strconv.Itoa(refresherCourseID)
+	}
+	if !found || e.s.userByID(userID) == nil {
+		return errUnknownReference
+	}
+	p := &payment{
+		id:                len(e.s.payments) + 1,
+		paypalPayerID:     paypalPayerID,
+		paypalOrderID:     paypalOrderID,
+		refresherCourseID: refresherCourseID,
+		createdAt:         time.Now(),
+	}
+	e.s.payments = append(e.s.payments, p)
+	e.s.enrollments = append(e.s.enrollments, enrollment{paymentID: p.id, userID: userID, refresherCourseID: refresherCourseID})
+	return nil
+}
+
+func (e *enrollments) IsEnrolled(ctx context.Context, userID, refresherCourseID int) (bool, error) {
+	e.s.mu.Lock()
+	defer e.s.mu.Unlock()
+	for _, en := range e.s.enrollments {
+		if en.userID == userID && en.refresherCourseID == refresherCourseID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (e *enrollments) Purchased(ctx context.Context, userID int) ([]*model.PurchasedCourse, error) {
+	e.s.mu.Lock()
+	defer e.s.mu.Unlock()
+	courses := make([]*model.PurchasedCourse, 0)
+	// appended in purchase order
+	for i := len(e.s.enrollments) - 1; i >= 0; i-- {
+		en := e.s.enrollments[i]
+		if en.userID != userID {
+			continue
+		}
+		purchased := &model.PurchasedCourse{}
+		for _, course := range e.s.refresherCourses {
+			if course.ID == strconv.Itoa(en.refresherCourseID) {
+				found := *course
+				purchased.RefresherCourse = &found
+			}
+		}
+		for _, p := range e.s.payments {
+			if p.id == en.paymentID {
+				createdAt := p.createdAt
+				purchased.PurchasedAt = &createdAt
+			}
+		}
+		for _, sess := range e.s.sessions {
+			if sess.refresherCourseID == en.refresherCourseID && sess.isReady {
+				purchased.TotalSessions++
+				sessionID, _ := strconv.Atoi(sess.ID)
+				if e.s.sessionViews[userID][sessionID] != nil {
+					purchased.ViewedSessions++
+				}
+			}
+		}
+		courses = append(courses, purchased)
+	}
+	return courses, nil
+}
+
+func (e *enrollments) Viewed(ctx context.Context, userID, sessionID int) error {
+	e.s.mu.Lock()
+	defer e.s.mu.Unlock()
+	if e.s.userByID(userID) == nil || e.s.sessionByID(sessionID) == nil {
+		return errUnknownReference
+	}
+	if e.s.sessionViews[userID] == nil {
+		e.s.sessionViews[userID] = map[int]*sessionView{}
+	}
+	now := time.Now()
+	if view := e.s.sessionViews[userID][sessionID]; view != nil {
+		view.lastViewedAt = now
+	} else {
+		e.s.sessionViews[userID][sessionID] = &sessionView{firstViewedAt: now, lastViewedAt: now}
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+// Package memory implements the repositories in memory, it enforces the same
+// constraints as the MySQL schema so resolvers can be exercised without a database
+package memory
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/juleur/becrpe/graph/model"
+	"github.com/juleur/becrpe/repository"
+)
+
+type session struct {
+	model.Session
+	refresherCourseID int
+	teacherID         int
+	isReady           bool
+}
+
+type enrollment struct {
+	paymentID         int
+	userID            int
+	refresherCourseID int
+}
+
+type passwordReset struct {
+	userID    int
+	tokenHash string
+	createdAt time.Time
+	expiresAt time.Time
+	usedAt    time.Time
+}
+
+type recoveryCode struct {
+	userID   int
+	codeHash string
+	used     bool
+}
+
+type payment struct {
+	id                int
+	paypalPayerID     string
+	paypalOrderID     string
+	refresherCourseID int
+	createdAt         time.Time
+	anonymisedAt      time.Time
+}
+
+type sessionView struct {
+	firstViewedAt time.Time
+	lastViewedAt  time.Time
+}
+
+// Store holds every table, the repositories returned by Repositories share it
+type Store struct {
+	mu sync.Mutex
+
+	users            []*model.User
+	userAuths        []*model.UserAuth
+	refresherCourses []*model.RefresherCourse
+	sessions         []*session
+	videos           []*model.Video
+	classPapers      []*model.ClassPaper
+	payments         []*payment
+	enrollments      []enrollment
+	passwordResets   []*passwordReset
+	totpSecrets      map[int]string
+	recoveryCodes    []*recoveryCode
+	userRoles        map[int][]string
+	// sessionViews holds the sessions opened by each user
+	sessionViews map[int]map[int]*sessionView
+	// the last ids given, like AUTO_INCREMENT they are not reused once erased
+	lastUserID     int
+	lastUserAuthID int
+	lastSessionID  int
+}
+
+// New returns an empty store
+func New() *Store {
+	return &Store{totpSecrets: map[int]string{}, userRoles: map[int][]string{}, sessionViews: map[int]map[int]*sessionView{}}
+}
+
+// Repositories returns the repositories reading and writing s
+func (s *Store) Repositories() repository.Repositories {
+	return repository.Repositories{
+		Users:          &users{s},
+		AuthTokens:     &authTokens{s},
+		Courses:        &refresherCourses{s},
+		Sessions:       &sessions{s},
+		Videos:         &videos{s},
+		ClassPapers:    &classPapers{s},
+		Enrollments:    &enrollments{s},
+		PasswordResets: &passwordResets{s},
+		TwoFactor:      &twoFactor{s},
+		Roles:          &roles{s},
+		PersonalData:   &personalData{s},
+	}
+}
+
+// AddRefresherCourse seeds a course, they are not created through the API
+func (s *Store) AddRefresherCourse(course model.RefresherCourse) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := len(s.refresherCourses) + 1
+	course.ID = strconv.Itoa(id)
+	if course.CreatedAt == nil {
+		now := time.Now()
+		course.CreatedAt = &now
+	}
+	s.refresherCourses = append(s.refresherCourses, &course)
+	return id
+}
+
+// AddVideo saves a processed video and marks its session ready like the upload pipeline
+func (s *Store) AddVideo(video model.Video) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	video.ID = len(s.videos) + 1
+	if video.CreatedAt.IsZero() {
+		video.CreatedAt = time.Now()
+		video.UpdatedAt = video.CreatedAt
+	}
+	s.videos = append(s.videos, &video)
+	for _, sess := range s.sessions {
+		if sess.ID == strconv.Itoa(video.SessionID) {
+			sess.isReady = true
+		}
+	}
+	return video.ID
+}
+
+// AddClassPaper saves a processed document like the upload pipeline
+func (s *Store) AddClassPaper(classPaper model.ClassPaper) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	classPaper.ID = len(s.classPapers) + 1
+	if classPaper.CreatedAt.IsZero() {
+		classPaper.CreatedAt = time.Now()
+		classPaper.UpdatedAt = classPaper.CreatedAt
+	}
+	s.classPapers = append(s.classPapers, &classPaper)
+	return classPaper.ID
+}
+
+// UserAuths returns a copy of the delivered refresh tokens, revoked ones included
+func (s *Store) UserAuths() []model.UserAuth {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	auths := make([]model.UserAuth, 0, len(s.userAuths))
+	for _, auth := range s.userAuths {
+		auths = append(auths, *auth)
+	}
+	return auths
+}
+
+func (s *Store) userByID(id int) *model.User {
+	for _, user := range s.users {
+		if user.ID == id {
+			return user
+		}
+	}
+	return nil
+}
+
+// roleNames copies the roles of a user, loaded with the user like MySQL
+func (s *Store) roleNames(userID int) model.RoleNames {
+	return append(model.RoleNames{}, s.userRoles[userID]...)
+}
+
+func (s *Store) deleteRecoveryCodes(userID int) {
+	kept := s.recoveryCodes[:0]
+	for _, code := range s.recoveryCodes {
+		if code.userID != userID {
+			kept = append(kept, code)
+		}
+	}
+	s.recoveryCodes = kept
+}
+
+func (s *Store) sessionByID(id int) *session {
+	for _, sess := range s.sessions {
+		if sess.ID == strconv.Itoa(id) {
+			return sess
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/juleur/becrpe/repository"
+)
+
+type passwordResets struct {
+	s *Store
+}
+
+func (p *passwordResets) Create(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error {
+	p.s.mu.Lock()
+	defer p.s.mu.Unlock()
+	if p.s.userByID(userID) == nil {
+		return errUnknownReference
+	}
+	p.s.passwordResets = append(p.s.passwordResets, &passwordReset{
+		userID: userID, tokenHash: tokenHash, createdAt: time.Now(), expiresAt: expiresAt,
+	})
+	return nil
+}
+
+func (p *passwordResets) Consume(ctx context.Context, tokenHash string) (int, error) {
+	p.s.mu.Lock()
+	defer p.s.mu.Unlock()
+	for _, reset := range p.s.passwordResets {
+		if reset.tokenHash == tokenHash && reset.usedAt.IsZero() && time.Now().Before(reset.expiresAt) {
+			for _, other := range p.s.passwordResets {
+				if other.userID == reset.userID && other.usedAt.IsZero() {
+					other.usedAt = time.Now()
+				}
+			}
+			return reset.userID, nil
+		}
+	}
+	return 0, repository.ErrNotFound
+}
@@ -0,0 +1,197 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/juleur/becrpe/graph/model"
+	"github.com/juleur/becrpe/repository"
+)
+
+type personalData struct {
+	s *Store
+}
+
+func (p *personalData) Export(ctx context.Context, userID int) (*model.PersonalData, error) {
+	p.s.mu.Lock()
+	defer p.s.mu.Unlock()
+	user := p.s.userByID(userID)
+	if user == nil {
+		return nil, repository.ErrNotFound
+	}
+	data := &model.PersonalData{
+		Account: &model.PersonalAccount{
+			ID:              user.ID,
+			Username:        user.Username,
+			Fullname:        nullString(user.Fullname.String, user.Fullname.Valid),
+			Email:           user.Email,
+			EmailVerifiedAt: nullTime(user.EmailVerifiedAt.Time, user.EmailVerifiedAt.Valid),
+			CreatedAt:       user.CreatedAt,
+			UpdatedAt:       nullTime(user.UpdatedAt.Time, user.UpdatedAt.Valid),
+		},
+		Roles:            append([]string{}, p.s.userRoles[userID]...),
+		DeviceSessions:   make([]*model.PersonalDeviceSession, 0),
+		Purchases:        make([]*model.PersonalPurchase, 0),
+		SessionViews:     make([]*model.PersonalSessionView, 0),
+		PasswordResets:   make([]*model.PersonalPasswordReset, 0),
+		TwoFactor:        &model.PersonalTwoFactor{EnabledAt: nullTime(user.TOTPEnabledAt.Time, user.TOTPEnabledAt.Valid)},
+		AuthoredSessions: make([]*model.PersonalSession, 0),
+	}
+	sort.Strings(data.Roles)
+	// every slice is appended oldest first, exported latest first like MySQL
+	for i := len(p.s.userAuths) - 1; i >= 0; i-- {
+		auth := p.s.userAuths[i]
+		if auth.UserID == userID {
+			data.DeviceSessions = append(data.DeviceSessions, &model.PersonalDeviceSession{
+				UserAgent:   auth.UserAgent,
+				IPAddress:   auth.IPAddress,
+				DeliveredAt: auth.DeliveredAt,
+				OnLogin:     auth.OnLogin,
+				RevokedAt:   nullTime(auth.RevokedAt, !auth.RevokedAt.IsZero()),
+			})
+		}
+	}
+	for i := len(p.s.enrollments) - 1; i >= 0; i-- {
+		en := p.s.enrollments[i]
+		if en.userID != userID {
+			continue
+		}
+		purchase := &model.PersonalPurchase{RefresherCourseID: en.refresherCourseID}
+		for _, course := range p.s.refresherCourses {
+			if course.ID == strconv.Itoa(en.refresherCourseID) {
+				if course.Subject != nil {
+					purchase.Subject = course.Subject.String()
+				}
+				if course.Year != nil {
+					purchase.Year = *course.Year
+				}
+				purchase.Price = course.Price
+			}
+		}
+		for _, pay := range p.s.payments {
+			if pay.id == en.paymentID {
+				payerID, orderID, paidAt := pay.paypalPayerID, pay.paypalOrderID, pay.createdAt
+				purchase.PaypalPayerID, purchase.PaypalOrderID, purchase.PaidAt = &payerID, &orderID, &paidAt
+			}
+		}
+		data.Purchases = append(data.Purchases, purchase)
+	}
+	for sessionID, view := range p.s.sessionViews[userID] {
+		sessionView := &model.PersonalSessionView{
+			SessionID:     sessionID,
+			FirstViewedAt: view.firstViewedAt,
+			LastViewedAt:  view.lastViewedAt,
+		}
+		if sess := p.s.sessionByID(sessionID); sess != nil && sess.Title != nil {
+			sessionView.Title = *sess.Title
+		}
+		data.SessionViews = append(data.SessionViews, sessionView)
+	}
+	sort.Slice(data.SessionViews, func(i, j int) bool {
+		return data.SessionViews[i].LastViewedAt.After(data.SessionViews[j].LastViewedAt)
+	})
+	for i := len(p.s.passwordResets) - 1; i >= 0; i-- {
+		reset := p.s.passwordResets[i]
+		if reset.userID == userID {
+			data.PasswordResets = append(data.PasswordResets, &model.PersonalPasswordReset{
+				CreatedAt: reset.createdAt,
+				ExpiresAt: reset.expiresAt,
+				UsedAt:    nullTime(reset.usedAt, !reset.usedAt.IsZero()),
+			})
+		}
+	}
+	for _, code := range p.s.recoveryCodes {
+		if code.userID == userID {
+			data.TwoFactor.RecoveryCodes++
+			if !code.used {
+				data.TwoFactor.RecoveryCodesRemaining++
+			}
+		}
+	}
+	for i := len(p.s.sessions) - 1; i >= 0; i-- {
+		sess := p.s.sessions[i]
+		if sess.teacherID != userID {
+			continue
+		}
+		authored := &model.PersonalSession{RefresherCourseID: sess.refresherCourseID}
+		authored.ID, _ = strconv.Atoi(sess.ID)
+		if sess.Title != nil {
+			authored.Title = *sess.Title
+		}
+		if sess.CreatedAt != nil {
+			authored.CreatedAt = *sess.CreatedAt
+		}
+		data.AuthoredSessions = append(data.AuthoredSessions, authored)
+	}
+	return data, nil
+}
+
+func (p *personalData) Erase(ctx context.Context, userID int) error {
+	p.s.mu.Lock()
+	defer p.s.mu.Unlock()
+	if p.s.userByID(userID) == nil {
+		return repository.ErrNotFound
+	}
+	for _, sess := range p.s.sessions {
+		if sess.teacherID == userID {
+			return repository.ErrAuthoredSessions
+		}
+	}
+	now := time.Now()
+	enrollments := p.s.enrollments[:0]
+	for _, en := range p.s.enrollments {
+		if en.userID != userID {
+			enrollments = append(enrollments, en)
+			continue
+		}
+		for _, pay := range p.s.payments {
+			if pay.id == en.paymentID {
+				pay.paypalPayerID = ""
+				pay.anonymisedAt = now
+			}
+		}
+	}
+	p.s.enrollments = enrollments
+	userAuths := p.s.userAuths[:0]
+	for _, auth := range p.s.userAuths {
+		if auth.UserID != userID {
+			userAuths = append(userAuths, auth)
+		}
+	}
+	p.s.userAuths = userAuths
+	resets := p.s.passwordResets[:0]
+	for _, reset := range p.s.passwordResets {
+		if reset.userID != userID {
+			resets = append(resets, reset)
+		}
+	}
+	p.s.passwordResets = resets
+	p.s.deleteRecoveryCodes(userID)
+	delete(p.s.totpSecrets, userID)
+	delete(p.s.userRoles, userID)
+	delete(p.s.sessionViews, userID)
+	users := p.s.users[:0]
+	for _, user := range p.s.users {
+		if user.ID != userID {
+			users = append(users, user)
+		}
+	}
+	p.s.users = users
+	return nil
+}
+
+func nullString(s string, valid bool) *string {
+	if !valid {
+		return nil
+	}
+	return &s
+}
+
+func nullTime(t time.Time, valid bool) *time.Time {
+	if !valid {
+		return nil
+	}
+	return &t
+}
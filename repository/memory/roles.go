@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/juleur/becrpe/repository"
+)
+
+// rolePermissions mirrors the seed of migrations/sql/0007_roles.up.sql
+var rolePermissions = map[string][]string{
+	"student": {},
+	"teacher": {"course_publish"},
+	"admin":   {"course_publish", "user_read", "role_manage"},
+	"support": {"user_read"},
+}
+
+type roles struct {
+	s *Store
+}
+
+func (r *roles) ByUser(ctx context.Context, userID int) ([]string, []string, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	names := append([]string{}, r.s.userRoles[userID]...)
+	seen := map[string]bool{}
+	permissions := make([]string, 0)
+	for _, role := range names {
+		for _, permission := range rolePermissions[role] {
+			if !seen[permission] {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	sort.Strings(permissions)
+	return names, permissions, nil
+}
+
+func (r *roles) Grant(ctx context.Context, userID int, role string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if r.s.userByID(userID) == nil {
+		return repository.ErrNotFound
+	}
+	if _, ok := rolePermissions[role]; !ok {
+		// INSERT ... SELECT of an unknown role inserts nothing
+		return nil
+	}
+	for _, existing := range r.s.userRoles[userID] {
+		if existing == role {
+			return nil
+		}
+	}
+	r.s.userRoles[userID] = append(r.s.userRoles[userID], role)
+	return nil
+}
+
+func (r *roles) Revoke(ctx context.Context, userID int, role string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	kept := make([]string, 0, len(r.s.userRoles[userID]))
+	for _, existing := range r.s.userRoles[userID] {
+		if existing != role {
+			kept = append(kept, existing)
+		}
+	}
+	r.s.userRoles[userID] = kept
+	return nil
+}
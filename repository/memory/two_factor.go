@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/juleur/becrpe/repository"
+)
+
+type twoFactor struct {
+	s *Store
+}
+
+func (t *twoFactor) SetPendingSecret(ctx context.Context, userID int, sealedSecret string) error {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+	user := t.s.userByID(userID)
+	if user == nil || user.TOTPEnabledAt.Valid {
+		return repository.ErrNotFound
+	}
+	t.s.totpSecrets[userID] = sealedSecret
+	return nil
+}
+
+func (t *twoFactor) Secret(ctx context.Context, userID int) (string, bool, error) {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+	user := t.s.userByID(userID)
+	if user == nil {
+		return "", false, repository.ErrNotFound
+	}
+	secret, ok := t.s.totpSecrets[userID]
+	if !ok {
+		return "", false, repository.ErrNotFound
+	}
+	return secret, user.TOTPEnabledAt.Valid, nil
+}
+
+func (t *twoFactor) Enable(ctx context.Context, userID int, codeHashes []string) error {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+	user := t.s.userByID(userID)
+	if _, ok := t.s.totpSecrets[userID]; user == nil || !ok || user.TOTPEnabledAt.Valid {
+		return repository.ErrNotFound
+	}
+	user.TOTPEnabledAt = sql.NullTime{Time: time.Now(), Valid: true}
+	t.s.deleteRecoveryCodes(userID)
+	for _, codeHash := range codeHashes {
+		t.s.recoveryCodes = append(t.s.recoveryCodes, &recoveryCode{userID: userID, codeHash: codeHash})
+	}
+	return nil
+}
+
+func (t *twoFactor) UseRecoveryCode(ctx context.Context, userID int, codeHash string) error {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+	for _, code := range t.s.recoveryCodes {
+		if code.userID == userID && code.codeHash == codeHash && !code.used {
+			code.used = true
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (t *twoFactor) Disable(ctx context.Context, userID int) error {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+	if user := t.s.userByID(userID); user != nil {
+		user.TOTPEnabledAt = sql.NullTime{}
+	}
+	delete(t.s.totpSecrets, userID)
+	t.s.deleteRecoveryCodes(userID)
+	return nil
+}
@@ -0,0 +1,147 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/juleur/becrpe/graph/model"
+	"github.com/juleur/becrpe/repository"
+)
+
+type users struct {
+	s *Store
+}
+
+func (u *users) Create(ctx context.Context, user *model.User) error {
+	u.s.mu.Lock()
+	defer u.s.mu.Unlock()
+	for _, existing := range u.s.users {
+		if existing.Email == user.Email {
+			return repository.ErrDuplicateEmail
+		}
+		if existing.Username == user.Username {
+			return repository.ErrDuplicateUsername
+		}
+	}
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = time.Now()
+	}
+	u.s.lastUserID++
+	user.ID = u.s.lastUserID
+	stored := *user
+	u.s.users = append(u.s.users, &stored)
+	u.s.userRoles[user.ID] = []string{model.RoleStudent.Name()}
+	return nil
+}
+
+func (u *users) ByID(ctx context.Context, id int) (*model.User, error) {
+	u.s.mu.Lock()
+	defer u.s.mu.Unlock()
+	if user := u.s.userByID(id); user != nil {
+		found := *user
+		found.RoleNames = u.s.roleNames(user.ID)
+		return &found, nil
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (u *users) ByEmail(ctx context.Context, email string) (*model.User, error) {
+	u.s.mu.Lock()
+	defer u.s.mu.Unlock()
+	for _, user := range u.s.users {
+		if user.Email == email {
+			found := *user
+			found.RoleNames = u.s.roleNames(user.ID)
+			return &found, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (u *users) Update(ctx context.Context, id int, email, username string) error {
+	u.s.mu.Lock()
+	defer u.s.mu.Unlock()
+	user := u.s.userByID(id)
+	if user == nil {
+		// UPDATE matching no row is not an error
+		return nil
+	}
+	for _, existing := range u.s.users {
+		if existing.ID == id {
+			continue
+		}
+		if email != "" && existing.Email == email {
+			return repository.ErrDuplicateEmail
+		}
+		if username != "" && existing.Username == username {
+			return repository.ErrDuplicateUsername
+		}
+	}
+	if email != "" && email != user.Email {
+		user.Email = email
+		user.EmailVerifiedAt = sql.NullTime{}
+	}
+	if username != "" {
+		user.Username = username
+	}
+	user.UpdatedAt.Time, user.UpdatedAt.Valid = time.Now(), true
+	return nil
+}
+
+func (u *users) UpdatePassword(ctx context.Context, id int, encryptedPWD string) error {
+	u.s.mu.Lock()
+	defer u.s.mu.Unlock()
+	if user := u.s.userByID(id); user != nil {
+		user.EncryptedPWD = encryptedPWD
+		user.UpdatedAt.Time, user.UpdatedAt.Valid = time.Now(), true
+	}
+	return nil
+}
+
+func (u *users) VerifyEmail(ctx context.Context, id int, email string) error {
+	u.s.mu.Lock()
+	defer u.s.mu.Unlock()
+	user := u.s.userByID(id)
+	if user == nil || user.Email != email {
+		return repository.ErrNotFound
+	}
+	if !user.EmailVerifiedAt.Valid {
+		user.EmailVerifiedAt.Time, user.EmailVerifiedAt.Valid = time.Now(), true
+	}
+	return nil
+}
+
+func (u *users) TeachersByRefresherCourse(ctx context.Context, refresherCourseID int) ([]*model.User, error) {
+	u.s.mu.Lock()
+	defer u.s.mu.Unlock()
+	teachers := make([]*model.User, 0)
+	seen := map[int]bool{}
+	for _, sess := range u.s.sessions {
+		if sess.refresherCourseID != refresherCourseID || seen[sess.teacherID] {
+			continue
+		}
+		seen[sess.teacherID] = true
+		if user := u.s.userByID(sess.teacherID); user != nil {
+			teachers = append(teachers, &model.User{ID: user.ID, Username: user.Username, RoleNames: u.s.roleNames(user.ID)})
+		}
+	}
+	return teachers, nil
+}
+
+func (u *users) SessionTeacher(ctx context.Context, sessionID int) (*model.User, error) {
+	u.s.mu.Lock()
+	defer u.s.mu.Unlock()
+	for _, sess := range u.s.sessions {
+		if sess.ID != strconv.Itoa(sessionID) {
+			continue
+		}
+		if user := u.s.userByID(sess.teacherID); user != nil {
+			return &model.User{
+				ID: user.ID, Username: user.Username, Fullname: user.Fullname, RoleNames: u.s.roleNames(user.ID),
+			}, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
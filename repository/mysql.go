@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// NewMySQL returns the repositories backed by db
+func NewMySQL(db *sqlx.DB) Repositories {
+	return Repositories{
+		Users:          &mysqlUsers{db: db},
+		AuthTokens:     &mysqlAuthTokens{db: db},
+		Courses:        &mysqlRefresherCourses{db: db},
+		Sessions:       &mysqlSessions{db: db},
+		Videos:         &mysqlVideos{db: db},
+		ClassPapers:    &mysqlClassPapers{db: db},
+		Enrollments:    &mysqlEnrollments{db: db},
+		PasswordResets: &mysqlPasswordResets{db: db},
+		TwoFactor:      &mysqlTwoFactor{db: db},
+		Roles:          &mysqlRoles{db: db},
+		PersonalData:   &mysqlPersonalData{db: db},
+	}
+}
+
+// mysqlErr maps driver errors to the repository ones
+func mysqlErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == 1062 {
+		// Duplicate entry 'x' for key 'users.users_email_unique'
+		switch {
+		case strings.Contains(mysqlErr.Message, "email"):
+			return ErrDuplicateEmail
+		case strings.Contains(mysqlErr.Message, "username"):
+			return ErrDuplicateUsername
+		}
+	}
+	return errors.WithStack(err)
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type mysqlPasswordResets struct {
+	db *sqlx.DB
+}
+
+func (p *mysqlPasswordResets) Create(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error {
+	_, err := p.db.ExecContext(ctx, `
+		INSERT INTO password_resets (user_id, token_hash, created_at, expires_at) VALUES (?,?,?,?)
+	`, userID, tokenHash, time.Now(), expiresAt)
+	return mysqlErr(err)
+}
+
+func (p *mysqlPasswordResets) Consume(ctx context.Context, tokenHash string) (int, error) {
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, mysqlErr(err)
+	}
+	// no-op once committed
+	defer tx.Rollback()
+	var userID int
+	if err := tx.GetContext(ctx, &userID, `
+		SELECT user_id FROM password_resets
+		WHERE token_hash = ? AND used_at IS NULL AND expires_at > ?
+		FOR UPDATE
+	`, tokenHash, time.Now()); err != nil {
+		return 0, mysqlErr(err)
+	}
+	// the links mailed before this one die with it
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE password_resets SET used_at = ? WHERE user_id = ? AND used_at IS NULL
+	`, time.Now(), userID); err != nil {
+		return 0, mysqlErr(err)
+	}
+	return userID, mysqlErr(tx.Commit())
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/juleur/becrpe/graph/model"
+)
+
+type mysqlPersonalData struct {
+	db *sqlx.DB
+}
+
+func (p *mysqlPersonalData) Export(ctx context.Context, userID int) (*model.PersonalData, error) {
+	// one snapshot for every table
+	tx, err := p.db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true, Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return nil, mysqlErr(err)
+	}
+	defer tx.Rollback()
+	data := model.PersonalData{
+		Account:          &model.PersonalAccount{},
+		Roles:            make([]string, 0),
+		DeviceSessions:   make([]*model.PersonalDeviceSession, 0),
+		Purchases:        make([]*model.PersonalPurchase, 0),
+		SessionViews:     make([]*model.PersonalSessionView, 0),
+		PasswordResets:   make([]*model.PersonalPasswordReset, 0),
+		TwoFactor:        &model.PersonalTwoFactor{},
+		AuthoredSessions: make([]*model.PersonalSession, 0),
+	}
+	if err := tx.GetContext(ctx, data.Account, `
+		SELECT id, username, fullname, email, email_verified_at, created_at, updated_at FROM users WHERE id = ?
+	`, userID); err != nil {
+		return nil, mysqlErr(err)
+	}
+	if err := tx.SelectContext(ctx, &data.Roles, `
+		SELECT r.name FROM user_roles AS ur JOIN roles AS r ON r.id = ur.role_id WHERE ur.user_id = ? ORDER BY r.name
+	`, userID); err != nil {
+		return nil, mysqlErr(err)
+	}
+	if err := tx.SelectContext(ctx, &data.DeviceSessions, `
+		SELECT user_agent, ip_address, delivered_at, on_login, revoked_at FROM user_auths
+		WHERE user_id = ? ORDER BY delivered_at DESC
+	`, userID); err != nil {
+		return nil, mysqlErr(err)
+	}
+	if err := tx.SelectContext(ctx, &data.Purchases, `
+		SELECT rc.id AS refresher_course_id, rc.subject, rc.year, rc.price,
+			p.paypal_payer_id, p.paypal_order_id, p.created_at AS paid_at
+		FROM users_refresher_courses AS urc
+		JOIN refresher_courses AS rc ON rc.id = urc.refresher_course_id
+		LEFT JOIN payments AS p ON p.id = urc.payment_id
+		WHERE urc.user_id = ?
+		ORDER BY p.created_at DESC
+	`, userID); err != nil {
+		return nil, mysqlErr(err)
+	}
+	if err := tx.SelectContext(ctx, &data.SessionViews, `
+		SELECT sv.session_id, s.title, sv.first_viewed_at, sv.last_viewed_at
+		FROM session_views AS sv JOIN sessions AS s ON s.id = sv.session_id
+		WHERE sv.user_id = ? ORDER BY sv.last_viewed_at DESC
+	`, userID); err != nil {
+		return nil, mysqlErr(err)
+	}
+	if err := tx.SelectContext(ctx, &data.PasswordResets, `
+		SELECT created_at, expires_at, used_at FROM password_resets WHERE user_id = ? ORDER BY created_at DESC
+	`, userID); err != nil {
+		return nil, mysqlErr(err)
+	}
+	if err := tx.GetContext(ctx, data.TwoFactor, `
+		SELECT u.totp_enabled_at,
+			(SELECT COUNT(*) FROM recovery_codes WHERE user_id = u.id) AS recovery_codes,
+			(SELECT COUNT(*) FROM recovery_codes WHERE user_id = u.id AND used_at IS NULL) AS recovery_codes_remaining
+		FROM users AS u WHERE u.id = ?
+	`, userID); err != nil {
+		return nil, mysqlErr(err)
+	}
+	if err := tx.SelectContext(ctx, &data.AuthoredSessions, `
+		SELECT id, title, refresher_course_id, created_at FROM sessions WHERE user_id = ? ORDER BY created_at DESC
+	`, userID); err != nil {
+		return nil, mysqlErr(err)
+	}
+	return &data, nil
+}
+
+func (p *mysqlPersonalData) Erase(ctx context.Context, userID int) error {
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return mysqlErr(err)
+	}
+	// no-op once committed
+	defer tx.Rollback()
+	var id int
+	if err := tx.GetContext(ctx, &id, "SELECT id FROM users WHERE id = ? FOR UPDATE", userID); err != nil {
+		return mysqlErr(err)
+	}
+	// sessions.user_id is NO ACTION, the recordings of a teacher are reassigned by hand
+	var authored int
+	if err := tx.GetContext(ctx, &authored, "SELECT COUNT(*) FROM sessions WHERE user_id = ?", userID); err != nil {
+		return mysqlErr(err)
+	}
+	if authored > 0 {
+		return ErrAuthoredSessions
+	}
+	// the order id and the course sold stay for accounting, the payer id goes
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE payments AS p JOIN users_refresher_courses AS urc ON urc.payment_id = p.id
+		SET p.paypal_payer_id = '', p.anonymised_at = ?,
+			p.refresher_course_id = COALESCE(p.refresher_course_id, urc.refresher_course_id)
+		WHERE urc.user_id = ?
+	`, time.Now(), userID); err != nil {
+		return mysqlErr(err)
+	}
+	// fk_user_id_user_auths is NO ACTION and checked before users_AFTER_DELETE
+	// runs, the trigger then only drops the enrollments
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_auths WHERE user_id = ?", userID); err != nil {
+		return mysqlErr(err)
+	}
+	// password resets, recovery codes, roles and session views cascade
+	if _, err := tx.ExecContext(ctx, "DELETE FROM users WHERE id = ?", userID); err != nil {
+		return mysqlErr(err)
+	}
+	return mysqlErr(tx.Commit())
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/juleur/becrpe/graph/model"
+)
+
+type mysqlRefresherCourses struct {
+	db *sqlx.DB
+}
+
+const refresherCourseColumns = "id, subject, year, is_finished, price, created_at, updated_at"
+
+func (rc *mysqlRefresherCourses) All(ctx context.Context) ([]*model.RefresherCourse, error) {
+	courses := make([]*model.RefresherCourse, 0)
+	if err := rc.db.SelectContext(ctx, &courses, "SELECT "+refresherCourseColumns+" FROM refresher_courses"); err != nil {
+		return courses, mysqlErr(err)
+	}
+	return courses, nil
+}
+
+func (rc *mysqlRefresherCourses) ByID(ctx context.Context, id int) (*model.RefresherCourse, error) {
+	course := model.RefresherCourse{}
+	if err := rc.db.GetContext(ctx, &course, "SELECT "+refresherCourseColumns+" FROM refresher_courses WHERE id = ?", id); err != nil {
+		return nil, mysqlErr(err)
+	}
+	return &course, nil
+}
+
+func (rc *mysqlRefresherCourses) BySubject(ctx context.Context, subject model.SubjectEnum) ([]*model.RefresherCourse, error) {
+	courses := make([]*model.RefresherCourse, 0)
+	if err := rc.db.SelectContext(ctx, &courses, "SELECT "+refresherCourseColumns+" FROM refresher_courses WHERE subject = ?", subject); err != nil {
+		return courses, mysqlErr(err)
+	}
+	return courses, nil
+}
+
+func (rc *mysqlRefresherCourses) ByUser(ctx context.Context, userID int) ([]*model.RefresherCourse, error) {
+	courses := make([]*model.RefresherCourse, 0)
+	if err := rc.db.SelectContext(ctx, &courses, `
+		SELECT rc.id, rc.subject, rc.year, rc.is_finished, rc.price, rc.created_at, rc.updated_at FROM refresher_courses AS rc
+		JOIN users_refresher_courses AS urc ON rc.id = urc.refresher_course_id
+		WHERE urc.user_id = ?
+	`, userID); err != nil {
+		return courses, mysqlErr(err)
+	}
+	return courses, nil
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/juleur/becrpe/graph/model"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrNotFound is returned unwrapped when no row matches
+	ErrNotFound = errors.New("repository: not found")
+	// ErrDuplicateEmail is returned by Users when the email is already used
+	ErrDuplicateEmail = errors.New("repository: email already used")
+	// ErrDuplicateUsername is returned by Users when the username is already used
+	ErrDuplicateUsername = errors.New("repository: username already used")
+	// ErrTokenReused is returned by AuthTokens.Rotate when the token was revoked meanwhile
+	ErrTokenReused = errors.New("repository: refresh token already revoked")
+	// ErrAuthoredSessions is returned by PersonalData.Erase for the teachers who
+	// recorded sessions, the sessions belong to the courses sold
+	ErrAuthoredSessions = errors.New("repository: user authored sessions")
+)
+
+// Repositories gathers every data access the resolvers need
+type Repositories struct {
+	Users          Users
+	AuthTokens     AuthTokens
+	Courses        RefresherCourses
+	Sessions       Sessions
+	Videos         Videos
+	ClassPapers    ClassPapers
+	Enrollments    Enrollments
+	PasswordResets PasswordResets
+	TwoFactor      TwoFactor
+	Roles          Roles
+	PersonalData   PersonalData
+}
+
+// Users stores accounts, students and teachers alike
+type Users interface {
+	// Create sets user.ID, the user gets the student role
+	Create(ctx context.Context, user *model.User) error
+	ByID(ctx context.Context, id int) (*model.User, error)
+	ByEmail(ctx context.Context, email string) (*model.User, error)
+	// Update changes the non empty fields only, a new email is unverified
+	Update(ctx context.Context, id int, email, username string) error
+	UpdatePassword(ctx context.Context, id int, encryptedPWD string) error
+	// VerifyEmail marks email verified if it is still the email of the user,
+	// ErrNotFound otherwise
+	VerifyEmail(ctx context.Context, id int, email string) error
+	TeachersByRefresherCourse(ctx context.Context, refresherCourseID int) ([]*model.User, error)
+	SessionTeacher(ctx context.Context, sessionID int) (*model.User, error)
+}
+
+// AuthTokens stores the refresh tokens delivered on login and refresh,
+// the tokens descending from one login form a family
+type AuthTokens interface {
+	// Create sets auth.ID, it starts a family unless auth.FamilyID is set
+	Create(ctx context.Context, auth *model.UserAuth) error
+	// ByRefreshTokenHash returns the token with its user, revoked or not
+	ByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (*model.UserAuth, error)
+	// Rotate revokes old and creates next in the family of old,
+	// it returns ErrTokenReused when old is already revoked
+	Rotate(ctx context.Context, old, next *model.UserAuth) error
+	Revoke(ctx context.Context, userID int, refreshTokenHash string) error
+	// Active returns the valid token of every family of the user, latest first
+	Active(ctx context.Context, userID int) ([]*model.UserAuth, error)
+	// RevokeFamily revokes the tokens of the family still valid and returns their count
+	RevokeFamily(ctx context.Context, userID, familyID int) (int, error)
+	// RevokeAll revokes every valid token of the user and returns their families
+	RevokeAll(ctx context.Context, userID int) ([]int, error)
+}
+
+// RefresherCourses stores the courses sold
+type RefresherCourses interface {
+	All(ctx context.Context) ([]*model.RefresherCourse, error)
+	ByID(ctx context.Context, id int) (*model.RefresherCourse, error)
+	BySubject(ctx context.Context, subject model.SubjectEnum) ([]*model.RefresherCourse, error)
+	ByUser(ctx context.Context, userID int) ([]*model.RefresherCourse, error)
+}
+
+// Sessions stores the lessons of a refresher course
+type Sessions interface {
+	// Create returns the id of a session not ready until its video is processed
+	Create(ctx context.Context, teacherID int, input model.NewSessionInput) (int, error)
+	Ready(ctx context.Context, refresherCourseID, sessionID int) (*model.Session, error)
+	ReadyByRefresherCourse(ctx context.Context, refresherCourseID int) ([]*model.Session, error)
+	// Delete drops a session not ready yet, with its upload jobs
+	Delete(ctx context.Context, sessionID int) error
+}
+
+// Videos reads the videos saved by the upload pipeline
+type Videos interface {
+	BySession(ctx context.Context, sessionID int) (*model.Video, error)
+	Durations(ctx context.Context) ([]string, error)
+	DurationsByRefresherCourse(ctx context.Context, refresherCourseID int) ([]string, error)
+}
+
+// ClassPapers reads the documents saved by the upload pipeline
+type ClassPapers interface {
+	BySession(ctx context.Context, sessionID int) ([]*model.ClassPaper, error)
+}
+
+// Enrollments stores payments, the courses they give access to and the
+// sessions watched in them
+type Enrollments interface {
+	// Purchase records the PayPal payment then enrolls the user
+	Purchase(ctx context.Context, userID, refresherCourseID int, paypalPayerID, paypalOrderID string) error
+	IsEnrolled(ctx context.Context, userID, refresherCourseID int) (bool, error)
+	// Purchased returns the courses of the user with their progress, latest purchase first
+	Purchased(ctx context.Context, userID int) ([]*model.PurchasedCourse, error)
+	// Viewed records that the user opened the session, the progress of Purchased
+	Viewed(ctx context.Context, userID, sessionID int) error
+}
+
+// Roles stores the roles of the users, the permissions each role grants
+// are seeded by the migrations
+type Roles interface {
+	// ByUser returns the role names of a user and the permission names they grant
+	ByUser(ctx context.Context, userID int) (roles, permissions []string, err error)
+	// Grant gives role to a user, ErrNotFound when the user does not exist
+	Grant(ctx context.Context, userID int, role string) error
+	Revoke(ctx context.Context, userID int, role string) error
+}
+
+// TwoFactor stores the sealed TOTP secrets and the hashes of the recovery codes
+type TwoFactor interface {
+	// SetPendingSecret stores the secret of an enrollment to confirm,
+	// ErrNotFound when two-factor authentication is already enabled
+	SetPendingSecret(ctx context.Context, userID int, sealedSecret string) error
+	// Secret returns the sealed secret and whether it is enabled, ErrNotFound without secret
+	Secret(ctx context.Context, userID int) (string, bool, error)
+	// Enable confirms the pending secret and replaces the recovery codes
+	Enable(ctx context.Context, userID int, codeHashes []string) error
+	// UseRecoveryCode spends a code, ErrNotFound when it is unknown or used
+	UseRecoveryCode(ctx context.Context, userID int, codeHash string) error
+	// Disable forgets the secret and the recovery codes
+	Disable(ctx context.Context, userID int) error
+}
+
+// PasswordResets stores the hashes of the one-time password reset tokens
+type PasswordResets interface {
+	Create(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error
+	// Consume spends the token along with every other outstanding token of
+	// its user and returns the user, ErrNotFound when the token is unknown,
+	// expired or already used
+	Consume(ctx context.Context, tokenHash string) (int, error)
+}
+
+// PersonalData gathers and erases what is stored about a user across the tables
+type PersonalData interface {
+	// Export returns everything stored about the user, ErrNotFound without user
+	Export(ctx context.Context, userID int) (*model.PersonalData, error)
+	// Erase anonymises the payments of the user, kept for accounting, and deletes
+	// the account with everything else, ErrNotFound without user
+	Erase(ctx context.Context, userID int) error
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type mysqlRoles struct {
+	db *sqlx.DB
+}
+
+func (r *mysqlRoles) ByUser(ctx context.Context, userID int) ([]string, []string, error) {
+	roles := make([]string, 0)
+	if err := r.db.SelectContext(ctx, &roles, `
+		SELECT r.name FROM user_roles AS ur
+		JOIN roles AS r ON r.id = ur.role_id
+		WHERE ur.user_id = ? ORDER BY r.id
+	`, userID); err != nil {
+		return nil, nil, mysqlErr(err)
+	}
+	permissions := make([]string, 0)
+	if err := r.db.SelectContext(ctx, &permissions, `
+		SELECT DISTINCT p.name FROM user_roles AS ur
+		JOIN role_permissions AS rp ON rp.role_id = ur.role_id
+		JOIN permissions AS p ON p.id = rp.permission_id
+		WHERE ur.user_id = ? ORDER BY p.name
+	`, userID); err != nil {
+		return nil, nil, mysqlErr(err)
+	}
+	return roles, permissions, nil
+}
+
+func (r *mysqlRoles) Grant(ctx context.Context, userID int, role string) error {
+	// users.id is checked first, a foreign key error would not say which row is missing
+	var count int
+	if err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM users WHERE id = ?", userID); err != nil {
+		return mysqlErr(err)
+	}
+	if count == 0 {
+		return ErrNotFound
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT IGNORE INTO user_roles (user_id, role_id) SELECT ?, id FROM roles WHERE name = ?
+	`, userID, role)
+	return mysqlErr(err)
+}
+
+func (r *mysqlRoles) Revoke(ctx context.Context, userID int, role string) error {
+	_, err := r.db.ExecContext(ctx, `
+		DELETE ur FROM user_roles AS ur
+		JOIN roles AS r ON r.id = ur.role_id
+		WHERE ur.user_id = ? AND r.name = ?
+	`, userID, role)
+	return mysqlErr(err)
+}
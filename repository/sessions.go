@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/juleur/becrpe/graph/model"
+)
+
+type mysqlSessions struct {
+	db *sqlx.DB
+}
+
+const sessionColumns = "id, title, section, type, description, session_number, recorded_on, created_at, updated_at"
+
+func (s *mysqlSessions) Create(ctx context.Context, teacherID int, input model.NewSessionInput) (int, error) {
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO sessions (title, section, type, description, session_number, recorded_on, created_at, refresher_course_id, user_id) VALUES (?,?,?,?,?,?,?,?,?)
+	`, input.Title, input.Section, input.Type, input.Description, input.SessionNumber, input.RecordedOn, time.Now(), input.RefresherCourseID, teacherID)
+	if err != nil {
+		return 0, mysqlErr(err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, mysqlErr(err)
+	}
+	return int(id), nil
+}
+
+func (s *mysqlSessions) Delete(ctx context.Context, sessionID int) error {
+	// upload_jobs cascade
+	_, err := s.db.ExecContext(ctx, "DELETE FROM sessions WHERE id = ? AND is_ready = 0", sessionID)
+	return mysqlErr(err)
+}
+
+func (s *mysqlSessions) Ready(ctx context.Context, refresherCourseID, sessionID int) (*model.Session, error) {
+	session := model.Session{}
+	if err := s.db.GetContext(ctx, &session, `
+		SELECT `+sessionColumns+` FROM sessions
+		WHERE id = ? AND refresher_course_id = ? AND is_ready = 1
+	`, sessionID, refresherCourseID); err != nil {
+		return nil, mysqlErr(err)
+	}
+	return &session, nil
+}
+
+func (s *mysqlSessions) ReadyByRefresherCourse(ctx context.Context, refresherCourseID int) ([]*model.Session, error) {
+	sessions := make([]*model.Session, 0)
+	if err := s.db.SelectContext(ctx, &sessions, `
+		SELECT `+sessionColumns+` FROM sessions WHERE refresher_course_id = ? AND is_ready = 1
+	`, refresherCourseID); err != nil {
+		return sessions, mysqlErr(err)
+	}
+	return sessions, nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type mysqlTwoFactor struct {
+	db *sqlx.DB
+}
+
+func (t *mysqlTwoFactor) SetPendingSecret(ctx context.Context, userID int, sealedSecret string) error {
+	res, err := t.db.ExecContext(ctx, `
+		UPDATE users SET totp_secret = ? WHERE id = ? AND totp_enabled_at IS NULL
+	`, sealedSecret, userID)
+	if err != nil {
+		return mysqlErr(err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return mysqlErr(err)
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (t *mysqlTwoFactor) Secret(ctx context.Context, userID int) (string, bool, error) {
+	var row struct {
+		Secret    sql.NullString `db:"totp_secret"`
+		EnabledAt sql.NullTime   `db:"totp_enabled_at"`
+	}
+	if err := t.db.GetContext(ctx, &row, "SELECT totp_secret, totp_enabled_at FROM users WHERE id = ?", userID); err != nil {
+		return "", false, mysqlErr(err)
+	}
+	if !row.Secret.Valid {
+		return "", false, ErrNotFound
+	}
+	return row.Secret.String, row.EnabledAt.Valid, nil
+}
+
+func (t *mysqlTwoFactor) Enable(ctx context.Context, userID int, codeHashes []string) error {
+	tx, err := t.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return mysqlErr(err)
+	}
+	// no-op once committed
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx, `
+		UPDATE users SET totp_enabled_at = ? WHERE id = ? AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL
+	`, time.Now(), userID)
+	if err != nil {
+		return mysqlErr(err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return mysqlErr(err)
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM recovery_codes WHERE user_id = ?", userID); err != nil {
+		return mysqlErr(err)
+	}
+	for _, codeHash := range codeHashes {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO recovery_codes (user_id, code_hash) VALUES (?,?)", userID, codeHash); err != nil {
+			return mysqlErr(err)
+		}
+	}
+	return mysqlErr(tx.Commit())
+}
+
+func (t *mysqlTwoFactor) UseRecoveryCode(ctx context.Context, userID int, codeHash string) error {
+	res, err := t.db.ExecContext(ctx, `
+		UPDATE recovery_codes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL LIMIT 1
+	`, time.Now(), userID, codeHash)
+	if err != nil {
+		return mysqlErr(err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return mysqlErr(err)
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (t *mysqlTwoFactor) Disable(ctx context.Context, userID int) error {
+	tx, err := t.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return mysqlErr(err)
+	}
+	// no-op once committed
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, "UPDATE users SET totp_secret = NULL, totp_enabled_at = NULL WHERE id = ?", userID); err != nil {
+		return mysqlErr(err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM recovery_codes WHERE user_id = ?", userID); err != nil {
+		return mysqlErr(err)
+	}
+	return mysqlErr(tx.Commit())
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/juleur/becrpe/graph/model"
+)
+
+type mysqlUsers struct {
+	db *sqlx.DB
+}
+
+const userColumns = "id, username, fullname, email, email_verified_at, encrypted_pwd, totp_enabled_at, created_at, updated_at, " +
+	roleNamesColumn
+
+// roleNamesColumn loads model.User.RoleNames of users with their row
+const roleNamesColumn = `(
+	SELECT GROUP_CONCAT(r.name ORDER BY r.id) FROM user_roles AS ur
+	JOIN roles AS r ON r.id = ur.role_id WHERE ur.user_id = users.id
+) AS role_names`
+
+func (u *mysqlUsers) Create(ctx context.Context, user *model.User) error {
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = time.Now()
+	}
+	tx, err := u.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return mysqlErr(err)
+	}
+	// no-op once committed
+	defer tx.Rollback()
+	res, err := tx.ExecContext(ctx,
+		"INSERT INTO users (username, fullname, email, encrypted_pwd, created_at) VALUES (?,?,?,?,?)",
+		user.Username, user.Fullname, user.Email, user.EncryptedPWD, user.CreatedAt,
+	)
+	if err != nil {
+		return mysqlErr(err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return mysqlErr(err)
+	}
+	// every account starts as a student
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO user_roles (user_id, role_id) SELECT ?, id FROM roles WHERE name = ?",
+		id, model.RoleStudent.Name(),
+	); err != nil {
+		return mysqlErr(err)
+	}
+	if err := tx.Commit(); err != nil {
+		return mysqlErr(err)
+	}
+	user.ID = int(id)
+	return nil
+}
+
+func (u *mysqlUsers) ByID(ctx context.Context, id int) (*model.User, error) {
+	user := model.User{}
+	if err := u.db.GetContext(ctx, &user, "SELECT "+userColumns+" FROM users WHERE id = ?", id); err != nil {
+		return nil, mysqlErr(err)
+	}
+	return &user, nil
+}
+
+func (u *mysqlUsers) ByEmail(ctx context.Context, email string) (*model.User, error) {
+	user := model.User{}
+	if err := u.db.GetContext(ctx, &user, "SELECT "+userColumns+" FROM users WHERE email = ?", email); err != nil {
+		return nil, mysqlErr(err)
+	}
+	return &user, nil
+}
+
+func (u *mysqlUsers) Update(ctx context.Context, id int, email, username string) error {
+	sets := []string{"updated_at = ?"}
+	args := []interface{}{time.Now()}
+	if email != "" {
+		// assignments run in order, email_verified_at still sees the old email
+		sets = append(sets, "email_verified_at = IF(email = ?, email_verified_at, NULL)", "email = ?")
+		args = append(args, email, email)
+	}
+	if username != "" {
+		sets = append(sets, "username = ?")
+		args = append(args, username)
+	}
+	args = append(args, id)
+	_, err := u.db.ExecContext(ctx, "UPDATE users SET "+strings.Join(sets, ", ")+" WHERE id = ?", args...)
+	return mysqlErr(err)
+}
+
+func (u *mysqlUsers) UpdatePassword(ctx context.Context, id int, encryptedPWD string) error {
+	_, err := u.db.ExecContext(ctx, "UPDATE users SET encrypted_pwd = ?, updated_at = ? WHERE id = ?", encryptedPWD, time.Now(), id)
+	return mysqlErr(err)
+}
+
+func (u *mysqlUsers) VerifyEmail(ctx context.Context, id int, email string) error {
+	res, err := u.db.ExecContext(ctx, `
+		UPDATE users SET email_verified_at = ? WHERE id = ? AND email = ? AND email_verified_at IS NULL
+	`, time.Now(), id, email)
+	if err != nil {
+		return mysqlErr(err)
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return mysqlErr(err)
+	}
+	// the link may be opened twice
+	var count int
+	if err := u.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM users WHERE id = ? AND email = ?", id, email); err != nil {
+		return mysqlErr(err)
+	}
+	if count == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (u *mysqlUsers) TeachersByRefresherCourse(ctx context.Context, refresherCourseID int) ([]*model.User, error) {
+	teachers := make([]*model.User, 0)
+	if err := u.db.SelectContext(ctx, &teachers, `
+		SELECT id, username, `+roleNamesColumn+` FROM users WHERE id IN(
+			SELECT DISTINCT user_id FROM sessions WHERE refresher_course_id = ?
+		)
+	`, refresherCourseID); err != nil {
+		return teachers, mysqlErr(err)
+	}
+	return teachers, nil
+}
+
+func (u *mysqlUsers) SessionTeacher(ctx context.Context, sessionID int) (*model.User, error) {
+	teacher := model.User{}
+	if err := u.db.GetContext(ctx, &teacher, `
+		SELECT users.id, users.username, users.fullname, `+roleNamesColumn+` FROM users
+		JOIN sessions AS s ON s.user_id = users.id
+		WHERE s.id = ?
+	`, sessionID); err != nil {
+		return nil, mysqlErr(err)
+	}
+	return &teacher, nil
+}
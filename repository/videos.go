@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/juleur/becrpe/graph/model"
+)
+
+type mysqlVideos struct {
+	db *sqlx.DB
+}
+
+func (v *mysqlVideos) BySession(ctx context.Context, sessionID int) (*model.Video, error) {
+	video := model.Video{}
+	if err := v.db.GetContext(ctx, &video, `
+		SELECT id, path, duration, created_at, COALESCE(updated_at, created_at) AS updated_at FROM videos WHERE session_id = ?
+	`, sessionID); err != nil {
+		return nil, mysqlErr(err)
+	}
+	return &video, nil
+}
+
+func (v *mysqlVideos) Durations(ctx context.Context) ([]string, error) {
+	durations := []string{}
+	if err := v.db.SelectContext(ctx, &durations, "SELECT duration FROM videos"); err != nil {
+		return durations, mysqlErr(err)
+	}
+	return durations, nil
+}
+
+func (v *mysqlVideos) DurationsByRefresherCourse(ctx context.Context, refresherCourseID int) ([]string, error) {
+	durations := []string{}
+	if err := v.db.SelectContext(ctx, &durations, `
+		SELECT duration FROM videos AS v JOIN sessions AS s ON v.session_id = s.id
+		WHERE s.refresher_course_id = ?
+	`, refresherCourseID); err != nil {
+		return durations, mysqlErr(err)
+	}
+	return durations, nil
+}
+
+type mysqlClassPapers struct {
+	db *sqlx.DB
+}
+
+func (cp *mysqlClassPapers) BySession(ctx context.Context, sessionID int) ([]*model.ClassPaper, error) {
+	classPapers := make([]*model.ClassPaper, 0)
+	if err := cp.db.SelectContext(ctx, &classPapers, `
+		SELECT id, title, path, created_at, COALESCE(updated_at, created_at) AS updated_at FROM class_papers WHERE session_id = ?
+	`, sessionID); err != nil {
+		return classPapers, mysqlErr(err)
+	}
+	return classPapers, nil
+}
@@ -4,30 +4,39 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"time"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/handler/extension"
 	"github.com/99designs/gqlgen/graphql/handler/lru"
 	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/go-chi/chi"
-	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
+	"github.com/juleur/becrpe/admin"
 	"github.com/juleur/becrpe/cache"
+	"github.com/juleur/becrpe/config"
 	"github.com/juleur/becrpe/graph"
 	"github.com/juleur/becrpe/graph/generated"
 	"github.com/juleur/becrpe/graph/model"
+	"github.com/juleur/becrpe/health"
 	"github.com/juleur/becrpe/interceptors"
+	"github.com/juleur/becrpe/jwtkeys"
+	"github.com/juleur/becrpe/logging"
+	"github.com/juleur/becrpe/mail"
+	"github.com/juleur/becrpe/metrics"
+	"github.com/juleur/becrpe/migrations"
+	"github.com/juleur/becrpe/password"
+	"github.com/juleur/becrpe/repository"
+	"github.com/juleur/becrpe/tracing"
 	"github.com/rs/cors"
 	"github.com/sirupsen/logrus"
-	"github.com/vektah/gqlparser/gqlerror"
+	"github.com/vektah/gqlparser/v2/gqlerror"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-const defaultPort = "6677"
-
 var (
-	secretKey  string
 	db         *sqlx.DB
 	redisCache *cache.Cache
 	logger     *logrus.Logger
@@ -40,59 +49,111 @@ func init() {
 		TimestampFormat: "2006-01-02T15:04:05-0700",
 		PrettyPrint:     true,
 	})
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "admin" {
+		os.Exit(admin.Run(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	cfg, err := config.Load(os.Args[0], os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 	logger.SetOutput(&lumberjack.Logger{
-		Filename: fmt.Sprintf("./log/%s.log", time.RFC822),
-		MaxAge:   2,
-		MaxSize:  30,
+		Filename: cfg.Log.Filename,
+		MaxAge:   cfg.Log.MaxAge,
+		MaxSize:  cfg.Log.MaxSize,
 	})
-}
 
-func init() {
-	var err error
-	secretKey = "secretKey"
-	db, err = sqlx.Connect("mysql", "chermak:pwd@tcp(127.0.0.1:7359)/ecrpe?parseTime=true&time_zone=%27Europe%2FParis%27")
+	jwtKeys, err := jwtkeys.Load(cfg.JWT)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+
+	shutdownTracing, err := tracing.Init(cfg.Tracing)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+
+	db, err = tracing.OpenDB(cfg.Database.DSN)
 	if err != nil {
 		logger.Fatalln(err)
 	}
 	if err := db.Ping(); err != nil {
 		logger.Fatalln(err)
 	}
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(30 * time.Minute)
-	if redisCache, err = cache.NewCache("localhost:8989", "", 24*time.Hour); err != nil {
+	if cfg.Database.MigrateOnStart {
+		migrator, err := migrations.New(cfg.Database.DSN, logger)
+		if err != nil {
+			logger.Fatalln(err)
+		}
+		if _, err := migrator.Up(context.Background()); err != nil {
+			logger.Fatalln(err)
+		}
+		migrator.Close()
+	}
+	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+	if redisCache, err = cache.NewCache(cfg.Redis.Address, cfg.Redis.Password, cfg.Redis.TTL); err != nil {
 		logger.Fatalln(err)
 	}
-}
+	redisCache.AddHook(metrics.RedisHook{})
+	redisCache.AddHook(tracing.RedisHook{})
+	metrics.RegisterDB(db)
 
-func main() {
-	defer db.Close()
+	uploadFileManager, err := model.NewUploadFileManager(db, logger, cfg.Storage)
+	if err != nil {
+		logger.Fatalln(err)
+	}
+	uploadFileManager.StageObserver = metrics.ObserveUploadStage
+	if err := uploadFileManager.ResumeJobs(); err != nil {
+		logger.Errorln(err)
+	}
+	metrics.RegisterUploadQueue(uploadFileManager.QueueStats)
 
-	uploadFileManager := model.NewUploadFileManager(db, logger)
-	go uploadFileManager.DoneProcesses()
+	mailer, err := mail.New(cfg.Mail, logger)
+	if err != nil {
+		logger.Fatalln(err)
+	}
 
 	router := chi.NewRouter()
-	router.Use(interceptors.JWTCheck(secretKey))
+	router.Use(tracing.Middleware())
+	router.Use(interceptors.CookieSession(cfg.Session))
+	router.Use(interceptors.JWTCheck(cfg.JWT, jwtKeys, redisCache))
 	router.Use(interceptors.GetIPAddress())
 	router.Use(interceptors.GetUserAgent())
+	router.Use(logging.Middleware(logger))
 
 	router.Use(cors.New(cors.Options{
-		AllowedOrigins:   []string{"*"},
+		AllowedOrigins:   cfg.Server.AllowedOrigins,
 		AllowedHeaders:   []string{"*"},
 		AllowedMethods:   []string{"OPTIONS", "GET", "POST"},
-		AllowCredentials: true,
+		ExposedHeaders:   []string{logging.RequestIDHeader},
+		AllowCredentials: cfg.Session.Mode == config.SessionCookie,
 		Debug:            false,
 	}).Handler)
+	resolver := &graph.Resolver{
+		Repositories:      repository.NewMySQL(db),
+		JWT:               cfg.JWT,
+		JWTKeys:           jwtKeys,
+		Account:           cfg.Account,
+		RateLimit:         cfg.RateLimit,
+		TwoFactorConfig:   cfg.TwoFactor,
+		Passwords:         password.New(cfg.Password),
+		Mailer:            mailer,
+		RedisCache:        redisCache,
+		UploadFileManager: uploadFileManager,
+		Logger:            logger,
+	}
 	srv := handler.New(generated.NewExecutableSchema(generated.Config{
-		Resolvers: &graph.Resolver{
-			DB:                db,
-			SecretKey:         secretKey,
-			RedisCache:        redisCache,
-			UploadFileManager: uploadFileManager,
-			Logger:            logger,
-		},
+		Resolvers:  resolver,
+		Directives: resolver.Directives(),
 	}))
+	srv.SetErrorPresenter(logging.ErrorPresenter)
 	srv.SetRecoverFunc(func(ctx context.Context, err interface{}) error {
-		logger.Error(err)
+		logging.ForContext(ctx, logger).Error(err)
 		return &gqlerror.Error{
 			Message: "Oops, une erreur est survenue",
 			Extensions: map[string]interface{}{
@@ -105,13 +166,67 @@ func main() {
 	srv.AddTransport(transport.GET{})
 	srv.AddTransport(transport.POST{})
 	srv.AddTransport(transport.MultipartForm{
-		MaxUploadSize: 300000000,
+		MaxUploadSize: cfg.Server.MaxUploadSize,
 	})
 	srv.SetQueryCache(lru.New(1000))
 	srv.Use(extension.FixedComplexityLimit(30))
+	srv.Use(metrics.GraphQL{})
+	srv.Use(tracing.GraphQL{})
+	srv.Use(logging.GraphQL{})
+	srv.Use(interceptors.CSRF{})
 
 	router.Handle("/query", srv)
-	if err := http.ListenAndServe(":"+defaultPort, router); err != nil {
-		logger.Fatalln(err)
+
+	healthChecker := health.NewChecker(db, redisCache, cfg.Storage, uploadFileManager, logger)
+	router.Get("/healthz", healthChecker.Liveness())
+	router.Get("/readyz", healthChecker.Readiness())
+	router.Handle("/.well-known/jwks.json", jwtKeys.Handler())
+
+	httpServer := &http.Server{Addr: ":" + cfg.Server.Port, Handler: router}
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Fatalln(err)
+		}
+	}()
+	// scraped on its own listener, the router is public
+	var metricsServer *http.Server
+	if cfg.Server.MetricsAddress != "" {
+		metricsRouter := chi.NewRouter()
+		metricsRouter.Handle("/metrics", metrics.Handler())
+		metricsServer = &http.Server{Addr: cfg.Server.MetricsAddress, Handler: metricsRouter}
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Fatalln(err)
+			}
+		}()
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-quit
+	logger.Infof("%s received, shutting down", sig)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	// stops accepting requests first so no upload job can be started afterwards
+	if err := httpServer.Shutdown(ctx); err != nil {
+		logger.Errorln(err)
+	}
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(ctx); err != nil {
+			logger.Errorln(err)
+		}
+	}
+	if err := uploadFileManager.Shutdown(ctx); err != nil {
+		logger.Errorln(err)
+	}
+	if err := redisCache.Close(); err != nil {
+		logger.Errorln(err)
+	}
+	if err := db.Close(); err != nil {
+		logger.Errorln(err)
+	}
+	if err := shutdownTracing(context.Background()); err != nil {
+		logger.Errorln(err)
 	}
 }
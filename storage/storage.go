@@ -0,0 +1,234 @@
+// Package storage is the reference implementation of the storage server
+// UploadFileManager sends packaged videos and class papers to.
+//
+// The contract, shared with the hosted storage, is:
+//
+//	POST /storage_video  multipart/form-data
+//	  dir_path  field, e.g. /player/french/2020/rc/session-12, comes first
+//	  vfile     DASH video stream, e.g. video.123-video-avc1.mp4
+//	  afile     DASH audio stream, e.g. video.123-audio-fr-mp4a.mp4
+//	  mpdfile   DASH manifest referencing both streams, e.g. video.123.mpd
+//
+//	POST /storage_doc  multipart/form-data
+//	  dir_path  field, comes first
+//	  docfile   class paper, only the base of its filename is kept
+//
+// Files keep their names under dir_path, the manifest references the streams
+// by name. Both endpoints answer 200 with {"dir_path": "<public prefix><dir_path>/<file>"}
+// naming the manifest or the class paper, the API strips the 20 characters
+// of the public prefix before sending paths to the player. Errors answer
+// 4xx or 5xx with {"error": "..."}.
+package storage
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// PublicPrefixLen is the length of the prefix stripped by the API from returned paths
+const PublicPrefixLen = 20
+
+// DefaultPublicPrefix is a PublicPrefixLen long prefix mimicking the hosted storage
+const DefaultPublicPrefix = "/storage/public_html"
+
+var (
+	videoFields = []string{"vfile", "afile", "mpdfile"}
+	docFields   = []string{"docfile"}
+)
+
+// Server writes uploaded files under Root
+type Server struct {
+	Root         string
+	PublicPrefix string
+	Logger       *logrus.Logger
+}
+
+// NewServer func
+func NewServer(root, publicPrefix string, logger *logrus.Logger) (*Server, error) {
+	if len(publicPrefix) != PublicPrefixLen {
+		return nil, errors.Errorf("storage: public prefix %q must be %d characters long", publicPrefix, PublicPrefixLen)
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &Server{Root: root, PublicPrefix: publicPrefix, Logger: logger}, nil
+}
+
+// Handler routes both endpoints of the contract
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	// the manifest is returned for videos, the player starts from it
+	mux.HandleFunc("/storage_video", s.upload(videoFields, "mpdfile"))
+	mux.HandleFunc("/storage_doc", s.upload(docFields, "docfile"))
+	return mux
+}
+
+type uploadError struct {
+	status int
+	err    error
+}
+
+func (e *uploadError) Error() string {
+	return e.err.Error()
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return &uploadError{status: http.StatusBadRequest, err: errors.Errorf(format, args...)}
+}
+
+func (s *Server) upload(fields []string, returned string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "only POST is allowed"})
+			return
+		}
+		publicPath, err := s.store(r, fields, returned)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if uploadErr, ok := err.(*uploadError); ok {
+				status = uploadErr.status
+			}
+			s.Logger.Errorln(err)
+			writeJSON(w, status, map[string]string{"error": err.Error()})
+			return
+		}
+		s.Logger.Infof("stored %s", publicPath)
+		writeJSON(w, http.StatusOK, map[string]string{"dir_path": publicPath})
+	}
+}
+
+// store streams every part to disk, files of a failed upload are removed
+func (s *Server) store(r *http.Request, fields []string, returned string) (string, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return "", badRequest("%v", err)
+	}
+	dirPath := ""
+	stored := map[string]string{}
+	written := []string{}
+	ok := false
+	defer func() {
+		if !ok {
+			for _, name := range written {
+				os.Remove(name)
+			}
+		}
+	}()
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", badRequest("%v", err)
+		}
+		name := part.FormName()
+		if name == "dir_path" {
+			b, err := ioutil.ReadAll(io.LimitReader(part, 512))
+			if err != nil {
+				return "", badRequest("%v", err)
+			}
+			if dirPath, err = cleanDirPath(string(b)); err != nil {
+				return "", err
+			}
+			continue
+		}
+		if !contains(fields, name) {
+			return "", badRequest("unexpected field %s", name)
+		}
+		if dirPath == "" {
+			return "", badRequest("dir_path must come before %s", name)
+		}
+		if _, dup := stored[name]; dup {
+			return "", badRequest("%s sent twice", name)
+		}
+		fileName, err := s.write(dirPath, part)
+		if err != nil {
+			return "", err
+		}
+		written = append(written, filepath.Join(s.Root, filepath.FromSlash(dirPath), fileName))
+		stored[name] = fileName
+	}
+	for _, field := range fields {
+		if _, found := stored[field]; !found {
+			return "", badRequest("%s is missing", field)
+		}
+	}
+	ok = true
+	return s.PublicPrefix + path.Join(dirPath, stored[returned]), nil
+}
+
+// write copies part to a temporary file renamed once complete
+func (s *Server) write(dirPath string, part *multipart.Part) (string, error) {
+	fileName := path.Base(strings.Replace(part.FileName(), "\\", "/", -1))
+	if fileName == "." || fileName == "/" || fileName == "" || strings.HasPrefix(fileName, ".") {
+		return "", badRequest("invalid filename %q", part.FileName())
+	}
+	dir := filepath.Join(s.Root, filepath.FromSlash(dirPath))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", errors.WithStack(err)
+	}
+	tmp, err := ioutil.TempFile(dir, ".upload-*")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, part); err != nil {
+		tmp.Close()
+		return "", badRequest("%s: %v", fileName, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", errors.WithStack(err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return "", errors.WithStack(err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, fileName)); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return fileName, nil
+}
+
+// cleanDirPath keeps dir_path inside the storage root
+func cleanDirPath(dirPath string) (string, error) {
+	dirPath = strings.TrimSpace(dirPath)
+	if !strings.HasPrefix(dirPath, "/") {
+		return "", badRequest("dir_path %q must be absolute", dirPath)
+	}
+	for _, segment := range strings.Split(dirPath, "/") {
+		if segment == ".." {
+			return "", badRequest("dir_path %q must not contain ..", dirPath)
+		}
+	}
+	cleaned := path.Clean(dirPath)
+	if cleaned == "/" {
+		return "", badRequest("dir_path must not be the root")
+	}
+	return cleaned, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// GraphQL is a gqlgen handler extension opening a span per operation
+// and a child span per resolver (fields backed by a method, e.g. RefresherCourse.teachers)
+type GraphQL struct{}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+	graphql.FieldInterceptor
+} = GraphQL{}
+
+// ExtensionName func
+func (GraphQL) ExtensionName() string {
+	return "OpenTelemetry"
+}
+
+// Validate func
+func (GraphQL) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptResponse func
+func (GraphQL) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	opType, opName := "operation", ""
+	if graphql.HasOperationContext(ctx) {
+		rc := graphql.GetOperationContext(ctx)
+		if rc.Operation != nil {
+			opType = string(rc.Operation.Operation)
+			opName = rc.Operation.Name
+		}
+	}
+	ctx, span := Tracer().Start(ctx, fmt.Sprintf("graphql.%s %s", opType, opName))
+	span.SetAttributes(
+		attribute.String("graphql.operation.type", opType),
+		attribute.String("graphql.operation.name", opName),
+	)
+	resp := next(ctx)
+	var err error
+	if resp != nil && len(resp.Errors) > 0 {
+		err = resp.Errors
+	}
+	End(span, err)
+	return resp
+}
+
+// InterceptField func
+func (GraphQL) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || !fc.IsMethod {
+		return next(ctx)
+	}
+	ctx, span := Tracer().Start(ctx, fc.Object+"."+fc.Field.Name)
+	span.SetAttributes(
+		attribute.String("graphql.field.object", fc.Object),
+		attribute.String("graphql.field.name", fc.Field.Name),
+		attribute.String("graphql.field.path", fc.Path().String()),
+	)
+	res, err := next(ctx)
+	End(span, err)
+	return res, err
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrInvalidToken is returned by VerifyToken when the token was not signed with the key
+	ErrInvalidToken = errors.New("utils: invalid signed token")
+	// ErrExpiredToken is returned by VerifyToken after the token expiry
+	ErrExpiredToken = errors.New("utils: expired signed token")
+)
+
+// SignToken returns a token carrying payload until expiresAt, signed with
+// HMAC-SHA256, nothing is stored: the token proves itself
+func SignToken(key, payload string, expiresAt time.Time) string {
+	body := base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(expiresAt.Unix(), 10) + "|" + payload))
+	return body + "." + base64.RawURLEncoding.EncodeToString(tokenMAC(key, body))
+}
+
+// VerifyToken returns the payload of a token made by SignToken
+func VerifyToken(key, token string, now time.Time) (string, error) {
+	sep := strings.IndexByte(token, '.')
+	if sep < 0 {
+		return "", ErrInvalidToken
+	}
+	body, sig := token[:sep], token[sep+1:]
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, tokenMAC(key, body)) {
+		return "", ErrInvalidToken
+	}
+	b, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+	parts := strings.SplitN(string(b), "|", 2)
+	if len(parts) != 2 {
+		return "", ErrInvalidToken
+	}
+	exp, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+	if !now.Before(time.Unix(exp, 0)) {
+		return "", ErrExpiredToken
+	}
+	return parts[1], nil
+}
+
+func tokenMAC(key, body string) []byte {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(body))
+	return mac.Sum(nil)
+}
@@ -1,19 +1,62 @@
 package utils
 
 import (
-	"math/rand"
-	"strings"
-	"time"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/pkg/errors"
+)
+
+// entropy in bytes of the generated tokens
+const (
+	refreshTokenSize = 32
+	jwtIDSize        = 16
+	oneTimeTokenSize = 32
+	csrfTokenSize    = 32
 )
 
-// HexKeyGenerator generate refresh token
-func HexKeyGenerator(nb int) string {
-	rand.Seed(time.Now().UTC().UnixNano())
-	const letterBytes = "0123456789abcdefghijklmnopqrstuvwxyz"
-	sb := strings.Builder{}
-	sb.Grow(nb)
-	for ; nb > 0; nb-- {
-		sb.WriteByte(letterBytes[rand.Intn(len(letterBytes)-1)])
+// RefreshTokenGenerator generates a refresh token from crypto/rand
+func RefreshTokenGenerator() (string, error) {
+	return randomToken(refreshTokenSize)
+}
+
+// JWTIDGenerator generates the jti claim naming a JWT in the logout denylist
+func JWTIDGenerator() (string, error) {
+	return randomToken(jwtIDSize)
+}
+
+// OneTimeTokenGenerator generates the tokens sent by email (password reset, ...)
+func OneTimeTokenGenerator() (string, error) {
+	return randomToken(oneTimeTokenSize)
+}
+
+// CSRFTokenGenerator generates the double-submit token of the cookie sessions
+func CSRFTokenGenerator() (string, error) {
+	return randomToken(csrfTokenSize)
+}
+
+// OneTimeTokenHash returns the hash stored instead of a one-time token,
+// unkeyed as the token is random and short-lived
+func OneTimeTokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.WithStack(err)
 	}
-	return sb.String()
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// RefreshTokenHash returns the keyed hash stored instead of the refresh token,
+// a leaked user_auths table gives no usable token without the key
+func RefreshTokenHash(key, refreshToken string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(refreshToken))
+	return hex.EncodeToString(mac.Sum(nil))
 }